@@ -65,6 +65,8 @@ const HackathonEdit: React.FC = () => {
     const [isLogoChanged, setIsLogoChanged] = useState<boolean>(false);
     const [mentorInvitesToDelete, setMentorInvitesToDelete] = useState<number[]>([]);
     const [newMentorInvites, setNewMentorInvites] = useState<Array<{userId: number, username: string}>>([]);
+    // ID критериев, сохранённых на сервере: их оценки привязаны к ID, поэтому такие критерии обновляются на месте
+    const [existingCriteriaIds, setExistingCriteriaIds] = useState<Set<string>>(new Set());
 
     // Рефы для доступа к компонентам дочерних форм
     const stagesRef = useRef<StepsListWithDatesRef>(null);
@@ -105,6 +107,8 @@ const HackathonEdit: React.FC = () => {
                     })));
                 }
 
+                setExistingCriteriaIds(new Set(data.criteria?.map(criterion => String(criterion.id)) || []));
+
                 // Преобразуем данные API в формат formData
                 setFormData({
                     name: data.name || '',
@@ -129,7 +133,7 @@ const HackathonEdit: React.FC = () => {
                     })) || [],
 
                     criteria: data.criteria?.map(criterion => ({
-                        id: criterion.id,
+                        id: String(criterion.id),
                         name: criterion.name,
                         minScore: criterion.minScore,
                         maxScore: criterion.maxScore
@@ -308,6 +312,7 @@ const HackathonEdit: React.FC = () => {
 
                 // Критерии оценки
                 criteria: formData.criteria.map(criterion => ({
+                    id: existingCriteriaIds.has(criterion.id) ? Number(criterion.id) : undefined,
                     name: criterion.name,
                     min_score: criterion.minScore,
                     max_score: criterion.maxScore
@@ -14,7 +14,8 @@ type ParticipantItemProps = {
 
 const teamRoles: Record<number, string> = {
     1: "Участник",
-    2: "Глава"
+    2: "Глава",
+    3: "Заместитель главы"
 };
 
 const ParticipantItem = (props: ParticipantItemProps) => {
@@ -7,10 +7,11 @@ export const statusOptions: Option[] = [
 ]
 
 export enum HackathonStatus {
-    BANNED = -1,
+    CANCELLED = -1,
     DRAFT = 0,
     PUBLISHED = 1,
-    ARCHIVED = 2
+    ARCHIVED = 2,
+    PENDING_REVIEW = 3
 }
 
 export enum HackathonRole {
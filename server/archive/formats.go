@@ -0,0 +1,205 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+func (in *inspection) zip(r io.ReaderAt, size int64) error {
+	reader, err := zip.NewReader(r, size)
+	if err != nil {
+		return ErrCorrupted
+	}
+	if len(reader.File) > in.rules.MaxEntries {
+		in.problem(fmt.Sprintf("В архиве больше %d записей", in.rules.MaxEntries))
+		in.stopped = true
+		return nil
+	}
+
+	// Сначала проверяются заявленные размеры: явную zip-бомбу видно без распаковки
+	var declared uint64
+	for _, file := range reader.File {
+		declared += file.UncompressedSize64
+		if file.UncompressedSize64 > 1<<20 && file.UncompressedSize64 > file.CompressedSize64*maxCompressionRatio {
+			in.problem(fmt.Sprintf("Файл %s сжат более чем в %d раз, архив похож на zip-бомбу", file.Name, maxCompressionRatio))
+			in.stopped = true
+			return nil
+		}
+	}
+	if declared > uint64(in.rules.MaxSize) {
+		in.grow(int64(min(declared, uint64(in.rules.MaxSize)+1)))
+		return nil
+	}
+
+	for _, file := range reader.File {
+		if in.stopped {
+			break
+		}
+
+		if !in.count() {
+			break
+		}
+
+		if file.Mode()&fs.ModeSymlink != 0 {
+			// Цель символической ссылки хранится в данных записи
+			target, err := readTarget(file)
+			if err != nil {
+				return err
+			}
+			in.link(file.Name, target)
+			in.add(file.Name, 0, false)
+			continue
+		}
+
+		dir := file.FileInfo().IsDir()
+		in.add(file.Name, int64(file.UncompressedSize64), dir)
+		if dir || in.stopped {
+			continue
+		}
+
+		// Заявленный размер может не совпадать с настоящим, поэтому данные распаковываются с подсчётом.
+		// Заодно проверяется контрольная сумма
+		rc, err := file.Open()
+		if err != nil {
+			return ErrCorrupted
+		}
+		err = in.read(rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readTarget(file *zip.File) (string, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return "", ErrCorrupted
+	}
+	defer rc.Close()
+
+	target, err := io.ReadAll(io.LimitReader(rc, 4096))
+	if err != nil {
+		return "", ErrCorrupted
+	}
+	return string(target), nil
+}
+
+func (in *inspection) tarGz(r io.Reader) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return ErrCorrupted
+	}
+	defer gz.Close()
+
+	// Данные записей, которые не читаются (устройства, неизвестные типы), tar пропускает, распаковывая,
+	// поэтому ограничивается весь распакованный поток, а не только прочитанные файлы
+	stream := &limitedReader{r: gz, n: in.rules.MaxSize + int64(in.rules.MaxEntries)*tarEntryOverhead}
+	reader := tar.NewReader(stream)
+	for !in.stopped {
+		header, err := reader.Next()
+		if stream.exceeded {
+			in.tooLarge()
+			break
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return ErrCorrupted
+		}
+		if !in.count() {
+			break
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			in.add(header.Name, 0, true)
+		case tar.TypeReg:
+			in.add(header.Name, header.Size, false)
+			if !in.stopped {
+				err := in.read(reader)
+				if stream.exceeded {
+					if !in.stopped {
+						in.tooLarge()
+					}
+				} else if err != nil {
+					return err
+				}
+			}
+		case tar.TypeSymlink:
+			in.link(header.Name, header.Linkname)
+			in.add(header.Name, 0, false)
+		case tar.TypeLink:
+			// Цель жёсткой ссылки указывается от корня архива
+			if _, ok := cleanPath(header.Linkname); !ok {
+				in.reject(header.Name + " -> " + header.Linkname)
+			}
+			in.add(header.Name, 0, false)
+		case tar.TypeXGlobalHeader, tar.TypeXHeader, tar.TypeGNULongName, tar.TypeGNULongLink:
+			// Служебные записи формата
+		default:
+			// Устройства и каналы в проекте не нужны и при распаковке опасны
+			in.reject(header.Name)
+		}
+	}
+	return nil
+}
+
+// read распаковывает данные записи, не выходя за лимит размера архива
+func (in *inspection) read(r io.Reader) error {
+	remaining := in.rules.MaxSize - in.report.Size
+	n, err := io.Copy(io.Discard, io.LimitReader(r, remaining+1))
+	if !in.grow(n) {
+		return nil
+	}
+	if err != nil {
+		return ErrCorrupted
+	}
+	return nil
+}
+
+// link проверяет, что символическая ссылка name указывает внутрь архива
+func (in *inspection) link(name, target string) {
+	target = strings.ReplaceAll(target, "\\", "/")
+	if strings.HasPrefix(target, "/") {
+		in.reject(name + " -> " + target)
+		return
+	}
+	if _, ok := cleanPath(path.Join(path.Dir(strings.ReplaceAll(name, "\\", "/")), target)); !ok {
+		in.reject(name + " -> " + target)
+	}
+}
+
+// limitedReader читает не больше n байт; попытка прочитать больше отмечается в exceeded
+type limitedReader struct {
+	r        io.Reader
+	n        int64
+	exceeded bool
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		// Поток ровно такой длины - не превышение
+		var probe [1]byte
+		if n, err := l.r.Read(probe[:]); n == 0 && errors.Is(err, io.EOF) {
+			return 0, io.EOF
+		}
+		l.exceeded = true
+		return 0, ErrTooLarge
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
@@ -0,0 +1,281 @@
+// Package archive проверяет архивы с проектами (zip и tar.gz) без распаковки на диск:
+// составляет список файлов, находит zip-бомбы и пути за пределами архива, проверяет правила хакатона
+package archive
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// Форматы архивов
+const (
+	FormatZip   = "zip"
+	FormatTarGz = "tar.gz"
+)
+
+// Ограничения по умолчанию, если правила хакатона их не задают
+const (
+	DefaultMaxSize    = 2 << 30 // распакованный размер, байт
+	DefaultMaxEntries = 50000
+	// Файл, сжатый сильнее, считается признаком zip-бомбы
+	maxCompressionRatio = 1000
+	// Сколько записей сохраняется в отчёте; проверяются все
+	maxReportEntries = 10000
+	// Сколько нарушений одного вида перечисляется в отчёте
+	maxListedProblems = 10
+	// После стольких небезопасных записей проверка прекращается: архив всё равно отклонён
+	maxUnsafeEntries = 1000
+	// Служебные данные tar на запись: заголовок, расширенный заголовок PAX и выравнивание
+	tarEntryOverhead = 4096
+)
+
+// ErrCorrupted - архив повреждён или не читается
+var ErrCorrupted = errors.New("архив повреждён")
+
+// Rules - правила проверки архива
+type Rules struct {
+	// Обязательные файлы: шаблоны имён вроде README* или путей вроде docs/*.md, без учёта регистра
+	RequiredFiles []string
+	// Запрещённые расширения файлов, например .exe
+	ForbiddenExtensions []string
+	// Наибольший распакованный размер в байтах; 0 - DefaultMaxSize
+	MaxSize int64
+	// Наибольшее число записей; 0 - DefaultMaxEntries
+	MaxEntries int
+}
+
+// Entry - файл или каталог архива
+type Entry struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	Dir  bool   `json:"dir,omitempty"`
+}
+
+// Report - результат проверки. Архив с непустым Problems принимать нельзя
+type Report struct {
+	Format    string   `json:"format"`
+	Files     int      `json:"files"`
+	Size      int64    `json:"size"`
+	Entries   []Entry  `json:"entries"`
+	Truncated bool     `json:"truncated,omitempty"`
+	Problems  []string `json:"problems,omitempty"`
+}
+
+// Detect определяет формат архива по первым байтам; для остальных файлов возвращает пустую строку
+func Detect(header []byte) string {
+	switch {
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")), bytes.HasPrefix(header, []byte("PK\x05\x06")):
+		return FormatZip
+	case bytes.HasPrefix(header, []byte{0x1f, 0x8b}):
+		return FormatTarGz
+	}
+	return ""
+}
+
+// Inspect проверяет архив формата format. Ошибка возвращается, только если архив не удалось прочитать;
+// нарушения правил перечисляются в Report.Problems
+func Inspect(r io.ReaderAt, size int64, format string, rules Rules) (*Report, error) {
+	if rules.MaxSize <= 0 {
+		rules.MaxSize = DefaultMaxSize
+	}
+	if rules.MaxEntries <= 0 {
+		rules.MaxEntries = DefaultMaxEntries
+	}
+
+	in := &inspection{rules: rules, report: &Report{Format: format, Entries: []Entry{}}}
+	var err error
+	switch format {
+	case FormatZip:
+		err = in.zip(r, size)
+	case FormatTarGz:
+		err = in.tarGz(io.NewSectionReader(r, 0, size))
+	default:
+		return nil, fmt.Errorf("неизвестный формат архива %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	in.checkRequired()
+	in.flush()
+	return in.report, nil
+}
+
+// inspection накапливает сведения о записях архива по мере чтения
+type inspection struct {
+	rules  Rules
+	report *Report
+
+	paths     []string
+	unsafe    []string
+	forbidden []string
+	entries   int
+	// Проверка прервана: дальше читать архив бессмысленно
+	stopped bool
+}
+
+// count учитывает запись архива любого типа. Возвращает false, если записей больше допустимого
+func (in *inspection) count() bool {
+	in.entries++
+	if in.entries > in.rules.MaxEntries {
+		in.problem(fmt.Sprintf("В архиве больше %d записей", in.rules.MaxEntries))
+		in.stopped = true
+		return false
+	}
+	return true
+}
+
+// add учитывает файл или каталог архива с распакованным размером size
+func (in *inspection) add(name string, size int64, dir bool) {
+	clean, ok := cleanPath(name)
+	if !ok {
+		in.reject(name)
+		return
+	}
+	if clean == "." {
+		// Корневой каталог "./" в архивах tar
+		return
+	}
+
+	if !dir {
+		in.report.Files++
+		in.paths = append(in.paths, clean)
+		if ext := strings.ToLower(path.Ext(clean)); ext != "" && in.forbiddenExt(ext) {
+			in.forbidden = append(in.forbidden, clean)
+		}
+	}
+
+	if len(in.report.Entries) < maxReportEntries {
+		in.report.Entries = append(in.report.Entries, Entry{Path: clean, Size: size, Dir: dir})
+	} else {
+		in.report.Truncated = true
+	}
+}
+
+// reject запоминает небезопасную запись
+func (in *inspection) reject(name string) {
+	in.unsafe = append(in.unsafe, name)
+	if len(in.unsafe) >= maxUnsafeEntries {
+		in.stopped = true
+	}
+}
+
+// grow учитывает распакованные байты; при превышении лимита проверка прерывается
+func (in *inspection) grow(n int64) bool {
+	in.report.Size += n
+	if in.report.Size > in.rules.MaxSize {
+		in.tooLarge()
+		return false
+	}
+	return true
+}
+
+func (in *inspection) tooLarge() {
+	in.problem(fmt.Sprintf("Распакованный размер архива больше %d МБ", in.rules.MaxSize>>20))
+	in.stopped = true
+}
+
+func (in *inspection) problem(text string) {
+	in.report.Problems = append(in.report.Problems, text)
+}
+
+func (in *inspection) forbiddenExt(ext string) bool {
+	for _, forbidden := range in.rules.ForbiddenExtensions {
+		forbidden = strings.ToLower(strings.TrimSpace(forbidden))
+		if !strings.HasPrefix(forbidden, ".") {
+			forbidden = "." + forbidden
+		}
+		if ext == forbidden {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRequired проверяет обязательные файлы. Шаблон без "/" сравнивается с именем файла
+// в любом каталоге, шаблон с "/" - с путём от корня архива или от единственного корневого каталога
+func (in *inspection) checkRequired() {
+	if in.stopped {
+		return
+	}
+	root := commonRoot(in.paths)
+	for _, pattern := range in.rules.RequiredFiles {
+		pattern = strings.ToLower(strings.Trim(strings.TrimSpace(pattern), "/"))
+		if pattern == "" {
+			continue
+		}
+		found := false
+		for _, name := range in.paths {
+			name = strings.ToLower(name)
+			candidates := []string{name, strings.TrimPrefix(name, root)}
+			if !strings.Contains(pattern, "/") {
+				candidates = []string{path.Base(name)}
+			}
+			for _, candidate := range candidates {
+				if ok, _ := path.Match(pattern, candidate); ok {
+					found = true
+				}
+			}
+			if found {
+				break
+			}
+		}
+		if !found {
+			in.problem("В архиве нет обязательного файла " + pattern)
+		}
+	}
+}
+
+// flush переносит накопленные нарушения в отчёт
+func (in *inspection) flush() {
+	if len(in.unsafe) > 0 {
+		in.problem("Пути за пределами архива: " + listNames(in.unsafe))
+	}
+	if len(in.forbidden) > 0 {
+		in.problem("Файлы с запрещёнными расширениями: " + listNames(in.forbidden))
+	}
+}
+
+func listNames(names []string) string {
+	if len(names) <= maxListedProblems {
+		return strings.Join(names, ", ")
+	}
+	return strings.Join(names[:maxListedProblems], ", ") + fmt.Sprintf(" и ещё %d", len(names)-maxListedProblems)
+}
+
+// cleanPath приводит путь записи к виду a/b/c. Абсолютные пути, пути с диском Windows
+// и пути, выходящие за корень архива через "..", небезопасны
+func cleanPath(name string) (string, bool) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if strings.HasPrefix(name, "/") || (len(name) >= 2 && name[1] == ':') {
+		return name, false
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return name, false
+		}
+	}
+	return path.Clean(name), true
+}
+
+// commonRoot возвращает каталог, в котором лежат все файлы архива, с завершающим "/".
+// Архивы часто содержат один корневой каталог с названием проекта
+func commonRoot(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+	first, _, ok := strings.Cut(strings.ToLower(paths[0]), "/")
+	if !ok {
+		return ""
+	}
+	for _, name := range paths[1:] {
+		if !strings.HasPrefix(strings.ToLower(name), first+"/") {
+			return ""
+		}
+	}
+	return first + "/"
+}
@@ -0,0 +1,385 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+type zipEntry struct {
+	name string
+	body string
+	mode fs.FileMode
+}
+
+func zipFixture(t *testing.T, entries ...zipEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.name, Method: zip.Deflate}
+		if entry.mode != 0 {
+			header.SetMode(entry.mode)
+		}
+		f, err := w.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(entry.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// zipBombFixture записывает файл, заявленный размер которого в миллионы раз больше сжатого
+func zipBombFixture(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, err := w.CreateRaw(&zip.FileHeader{
+		Name:               "bomb.txt",
+		Method:             zip.Deflate,
+		CompressedSize64:   16,
+		UncompressedSize64: 1 << 34,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write(make([]byte, 16)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+type tarEntry struct {
+	header tar.Header
+	body   string
+}
+
+func tarGzFixture(t *testing.T, entries ...tarEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	w := tar.NewWriter(gz)
+	for _, entry := range entries {
+		header := entry.header
+		header.Size = int64(len(entry.body))
+		if header.Mode == 0 {
+			header.Mode = 0o644
+		}
+		if err := w.WriteHeader(&header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(entry.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func file(name, body string) tarEntry {
+	return tarEntry{header: tar.Header{Name: name, Typeflag: tar.TypeReg}, body: body}
+}
+
+func devices(count int) []tarEntry {
+	entries := make([]tarEntry, count)
+	for i := range entries {
+		entries[i] = tarEntry{header: tar.Header{Name: fmt.Sprintf("dev/tty%d", i), Typeflag: tar.TypeChar}}
+	}
+	return entries
+}
+
+func TestInspect(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   []byte
+		rules  Rules
+		// Подстрока ожидаемого нарушения; пустая - архив должен пройти проверку
+		problem string
+	}{
+		{
+			name:   "zip без нарушений",
+			format: FormatZip,
+			data: zipFixture(t,
+				zipEntry{name: "project/"},
+				zipEntry{name: "project/README.md", body: "# Проект"},
+				zipEntry{name: "project/main.go", body: "package main"},
+			),
+		},
+		{
+			name:    "zip-бомба",
+			format:  FormatZip,
+			data:    zipBombFixture(t),
+			problem: "zip-бомбу",
+		},
+		{
+			name:    "zip с путём через ..",
+			format:  FormatZip,
+			data:    zipFixture(t, zipEntry{name: "../evil.sh", body: "rm -rf /"}),
+			problem: "Пути за пределами архива: ../evil.sh",
+		},
+		{
+			name:    "zip с абсолютным путём",
+			format:  FormatZip,
+			data:    zipFixture(t, zipEntry{name: "/etc/passwd", body: "root"}),
+			problem: "Пути за пределами архива: /etc/passwd",
+		},
+		{
+			name:    "zip с путём Windows",
+			format:  FormatZip,
+			data:    zipFixture(t, zipEntry{name: "C:\\Windows\\evil.dll", body: "MZ"}),
+			problem: "Пути за пределами архива",
+		},
+		{
+			name:   "zip с символической ссылкой за пределы архива",
+			format: FormatZip,
+			data: zipFixture(t,
+				zipEntry{name: "project/secret", body: "../../etc/shadow", mode: fs.ModeSymlink | 0o777},
+			),
+			problem: "project/secret -> ../../etc/shadow",
+		},
+		{
+			name:   "zip с символической ссылкой внутри архива",
+			format: FormatZip,
+			data: zipFixture(t,
+				zipEntry{name: "project/README.md", body: "# Проект"},
+				zipEntry{name: "project/docs/readme", body: "../README.md", mode: fs.ModeSymlink | 0o777},
+			),
+		},
+		{
+			name:   "zip с заявленным размером больше лимита",
+			format: FormatZip,
+			data: zipFixture(t,
+				zipEntry{name: "a.txt", body: strings.Repeat("a", 600)},
+				zipEntry{name: "b.txt", body: strings.Repeat("b", 600)},
+			),
+			rules:   Rules{MaxSize: 1000},
+			problem: "Распакованный размер архива",
+		},
+		{
+			name:   "zip с лишними записями",
+			format: FormatZip,
+			data: zipFixture(t,
+				zipEntry{name: "a.txt"},
+				zipEntry{name: "b.txt"},
+				zipEntry{name: "c.txt"},
+			),
+			rules:   Rules{MaxEntries: 2},
+			problem: "В архиве больше 2 записей",
+		},
+		{
+			name:    "zip с запрещённым расширением",
+			format:  FormatZip,
+			data:    zipFixture(t, zipEntry{name: "bin/setup.EXE", body: "MZ"}),
+			rules:   Rules{ForbiddenExtensions: []string{"exe"}},
+			problem: "Файлы с запрещёнными расширениями: bin/setup.EXE",
+		},
+		{
+			name:   "tar.gz без нарушений",
+			format: FormatTarGz,
+			data: tarGzFixture(t,
+				tarEntry{header: tar.Header{Name: "./", Typeflag: tar.TypeDir, Mode: 0o755}},
+				file("./README.md", "# Проект"),
+				tarEntry{header: tar.Header{Name: "./docs/readme", Typeflag: tar.TypeSymlink, Linkname: "../README.md"}},
+			),
+		},
+		{
+			name:    "tar.gz с путём через ..",
+			format:  FormatTarGz,
+			data:    tarGzFixture(t, file("project/../../evil.sh", "rm -rf /")),
+			problem: "Пути за пределами архива: project/../../evil.sh",
+		},
+		{
+			name:   "tar.gz с абсолютной символической ссылкой",
+			format: FormatTarGz,
+			data: tarGzFixture(t,
+				tarEntry{header: tar.Header{Name: "passwd", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}},
+			),
+			problem: "passwd -> /etc/passwd",
+		},
+		{
+			name:   "tar.gz с жёсткой ссылкой за пределы архива",
+			format: FormatTarGz,
+			data: tarGzFixture(t,
+				tarEntry{header: tar.Header{Name: "shadow", Typeflag: tar.TypeLink, Linkname: "../etc/shadow"}},
+			),
+			problem: "shadow -> ../etc/shadow",
+		},
+		{
+			name:   "tar.gz с устройством",
+			format: FormatTarGz,
+			data: tarGzFixture(t,
+				tarEntry{header: tar.Header{Name: "dev/sda", Typeflag: tar.TypeBlock, Devmajor: 8}},
+			),
+			problem: "Пути за пределами архива: dev/sda",
+		},
+		{
+			name:   "tar.gz с распакованным размером больше лимита",
+			format: FormatTarGz,
+			data: tarGzFixture(t,
+				file("a.txt", strings.Repeat("a", 600)),
+				file("b.txt", strings.Repeat("b", 600)),
+			),
+			rules:   Rules{MaxSize: 1000},
+			problem: "Распакованный размер архива",
+		},
+		{
+			name:   "tar.gz с большими записями неизвестного типа",
+			format: FormatTarGz,
+			data: tarGzFixture(t,
+				tarEntry{header: tar.Header{Name: "blob", Typeflag: 'Z'}, body: strings.Repeat("z", 20000)},
+			),
+			rules:   Rules{MaxSize: 1000, MaxEntries: 2},
+			problem: "Распакованный размер архива",
+		},
+		{
+			name:    "tar.gz с устройствами сверх лимита записей",
+			format:  FormatTarGz,
+			data:    tarGzFixture(t, devices(3)...),
+			rules:   Rules{MaxEntries: 2},
+			problem: "В архиве больше 2 записей",
+		},
+		{
+			name:    "tar.gz с тысячами устройств",
+			format:  FormatTarGz,
+			data:    tarGzFixture(t, devices(5000)...),
+			problem: fmt.Sprintf("и ещё %d", maxUnsafeEntries-maxListedProblems),
+		},
+		{
+			name:   "tar.gz с лишними записями",
+			format: FormatTarGz,
+			data: tarGzFixture(t,
+				file("a.txt", "a"),
+				file("b.txt", "b"),
+				file("c.txt", "c"),
+			),
+			rules:   Rules{MaxEntries: 2},
+			problem: "В архиве больше 2 записей",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := Inspect(bytes.NewReader(tt.data), int64(len(tt.data)), tt.format, tt.rules)
+			if err != nil {
+				t.Fatalf("Inspect вернул ошибку: %v", err)
+			}
+			problems := strings.Join(report.Problems, "\n")
+			if tt.problem == "" {
+				if problems != "" {
+					t.Fatalf("ожидалась успешная проверка, нарушения: %s", problems)
+				}
+				return
+			}
+			if !strings.Contains(problems, tt.problem) {
+				t.Fatalf("ожидалось нарушение %q, получено: %q", tt.problem, problems)
+			}
+		})
+	}
+}
+
+func TestInspectRequiredFiles(t *testing.T) {
+	data := zipFixture(t,
+		zipEntry{name: "Project/README.md", body: "# Проект"},
+		zipEntry{name: "Project/docs/api.md", body: "API"},
+		zipEntry{name: "Project/src/main.go", body: "package main"},
+	)
+
+	tests := []struct {
+		name     string
+		required []string
+		missing  []string
+	}{
+		{name: "шаблон имени в любом каталоге", required: []string{"readme*"}},
+		{name: "путь от общего корневого каталога", required: []string{"docs/*.md", "/src/main.go"}},
+		{name: "путь от корня архива", required: []string{"project/src/*.go"}},
+		{name: "пустой шаблон пропускается", required: []string{" ", "/"}},
+		{
+			name:     "отсутствующие файлы",
+			required: []string{"LICENSE", "src/*.md", "main.go/"},
+			missing:  []string{"license", "src/*.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := Inspect(bytes.NewReader(data), int64(len(data)), FormatZip, Rules{RequiredFiles: tt.required})
+			if err != nil {
+				t.Fatalf("Inspect вернул ошибку: %v", err)
+			}
+			if len(report.Problems) != len(tt.missing) {
+				t.Fatalf("ожидалось %d нарушений, получено: %q", len(tt.missing), report.Problems)
+			}
+			for i, pattern := range tt.missing {
+				if want := "В архиве нет обязательного файла " + pattern; report.Problems[i] != want {
+					t.Errorf("нарушение %d: %q, ожидалось %q", i, report.Problems[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestCommonRoot(t *testing.T) {
+	tests := []struct {
+		paths []string
+		want  string
+	}{
+		{paths: nil, want: ""},
+		{paths: []string{"README.md"}, want: ""},
+		{paths: []string{"Project/README.md", "project/src/main.go"}, want: "project/"},
+		{paths: []string{"project/README.md", "other/main.go"}, want: ""},
+		{paths: []string{"project/README.md", "projectX/main.go"}, want: ""},
+	}
+	for _, tt := range tests {
+		if got := commonRoot(tt.paths); got != tt.want {
+			t.Errorf("commonRoot(%q) = %q, ожидалось %q", tt.paths, got, tt.want)
+		}
+	}
+}
+
+func TestInspectCorrupted(t *testing.T) {
+	data := []byte("PK\x03\x04 это не архив")
+	for _, format := range []string{FormatZip, FormatTarGz} {
+		if _, err := Inspect(bytes.NewReader(data), int64(len(data)), format, Rules{}); !errors.Is(err, ErrCorrupted) {
+			t.Errorf("%s: ожидалась ErrCorrupted, получено %v", format, err)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		header []byte
+		want   string
+	}{
+		{header: zipFixture(t), want: FormatZip},
+		{header: zipFixture(t, zipEntry{name: "a.txt", body: "a"}), want: FormatZip},
+		{header: tarGzFixture(t, file("a.txt", "a")), want: FormatTarGz},
+		{header: []byte("%PDF-1.7"), want: ""},
+		{header: nil, want: ""},
+	}
+	for _, tt := range tests {
+		if got := Detect(tt.header); got != tt.want {
+			t.Errorf("Detect(%q) = %q, ожидалось %q", tt.header[:min(len(tt.header), 4)], got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+)
+
+// WalkFunc получает обычный файл архива: путь вида a/b/c, заявленный размер и содержимое.
+// Содержимое доступно только до возврата из функции. Ошибка fs.SkipAll завершает обход без ошибки
+type WalkFunc func(name string, size int64, body io.Reader) error
+
+// ErrTooLarge - при обходе архива распаковано больше разрешённого
+var ErrTooLarge = errors.New("превышен размер распакованных данных")
+
+// Walk обходит обычные файлы архива формата format. Каталоги, ссылки и записи с путями
+// за пределами архива пропускаются. Если распаковано больше maxSize байт, включая пропущенные
+// записи tar.gz, обход прерывается ошибкой ErrTooLarge
+func Walk(r io.ReaderAt, size int64, format string, maxSize int64, fn WalkFunc) error {
+	stream := &limitedReader{n: maxSize}
+	var err error
+	switch format {
+	case FormatZip:
+		err = walkZip(r, size, stream, fn)
+	case FormatTarGz:
+		err = walkTarGz(io.NewSectionReader(r, 0, size), stream, fn)
+	default:
+		return fmt.Errorf("неизвестный формат архива %q", format)
+	}
+	if stream.exceeded {
+		return ErrTooLarge
+	}
+	if errors.Is(err, fs.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+// walkZip читает каждый файл через общий stream, чтобы ограничение действовало на весь архив
+func walkZip(r io.ReaderAt, size int64, stream *limitedReader, fn WalkFunc) error {
+	reader, err := zip.NewReader(r, size)
+	if err != nil {
+		return ErrCorrupted
+	}
+	for _, file := range reader.File {
+		if !file.Mode().IsRegular() {
+			continue
+		}
+		name, ok := cleanPath(file.Name)
+		if !ok || name == "." {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return ErrCorrupted
+		}
+		stream.r = rc
+		err = fn(name, int64(file.UncompressedSize64), stream)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkTarGz(r io.Reader, stream *limitedReader, fn WalkFunc) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return ErrCorrupted
+	}
+	defer gz.Close()
+
+	stream.r = gz
+	reader := tar.NewReader(stream)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return ErrCorrupted
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name, ok := cleanPath(header.Name)
+		if !ok || name == "." {
+			continue
+		}
+		if err := fn(name, header.Size, reader); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Команда migrate-storage переносит содержимое файлов между хранилищами, например с диска в S3:
+//
+//	go run ./cmd/migrate-storage -from local -to s3
+//
+// Параметры хранилищ и БД берутся из тех же переменных окружения, что и у сервера.
+// Уже перенесённые файлы пропускаются, поэтому команду можно запускать повторно
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"gorm.io/gorm"
+	"log"
+	"server/initializers"
+	"server/models"
+	"server/storage"
+)
+
+func main() {
+	from := flag.String("from", storage.BackendLocal, "хранилище, из которого переносятся файлы: local или s3")
+	to := flag.String("to", storage.BackendS3, "хранилище, в которое переносятся файлы: local или s3")
+	remove := flag.Bool("delete", false, "удалять файлы из исходного хранилища после переноса")
+	dryRun := flag.Bool("dry-run", false, "только показать, какие файлы будут перенесены")
+	flag.Parse()
+
+	if *from == *to {
+		log.Fatal("Source and destination storage must differ")
+	}
+	src, err := storage.New(*from)
+	if err != nil {
+		log.Fatal("Failed to configure source storage: ", err)
+	}
+	dst, err := storage.New(*to)
+	if err != nil {
+		log.Fatal("Failed to configure destination storage: ", err)
+	}
+	if s3, ok := dst.(*storage.S3); ok && !*dryRun {
+		if err := s3.EnsureBucket(context.Background()); err != nil {
+			log.Fatal("Failed to prepare S3 bucket: ", err)
+		}
+	}
+
+	initializers.ConnectToDb()
+
+	var moved, skipped, missing, failed int
+	var files []models.File
+	// Переносятся и файлы удалённых записей: на них могут ссылаться старые версии сдач
+	err = initializers.DB.Unscoped().Order("id").FindInBatches(&files, 500, func(tx *gorm.DB, batch int) error {
+		for _, file := range files {
+			switch err := migrateFile(context.Background(), src, dst, file, *remove, *dryRun); {
+			case err == nil:
+				moved++
+			case errors.Is(err, errAlreadyMigrated):
+				skipped++
+			case errors.Is(err, storage.ErrNotFound):
+				missing++
+				log.Printf("File %d (%s) is missing in source storage", file.ID, file.StoredName)
+			default:
+				failed++
+				log.Printf("Failed to migrate file %d (%s): %v", file.ID, file.StoredName, err)
+			}
+		}
+		return nil
+	}).Error
+	if err != nil {
+		log.Fatal("Failed to read files: ", err)
+	}
+
+	log.Printf("Migration finished: moved %d, already migrated %d, missing %d, failed %d", moved, skipped, missing, failed)
+	if failed > 0 {
+		log.Fatal("Some files were not migrated")
+	}
+}
+
+var errAlreadyMigrated = errors.New("файл уже перенесён")
+
+// migrateFile копирует файл и проверяет его размер в новом хранилище
+func migrateFile(ctx context.Context, src, dst storage.Storage, file models.File, remove, dryRun bool) error {
+	if object, err := dst.Stat(ctx, file.StoredName); err == nil && object.Size == file.Size {
+		if remove && !dryRun {
+			if err := src.Delete(ctx, file.StoredName); err != nil {
+				return err
+			}
+		}
+		return errAlreadyMigrated
+	} else if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+
+	if dryRun {
+		_, err := src.Stat(ctx, file.StoredName)
+		if err == nil {
+			log.Printf("Would migrate file %d (%s, %d bytes)", file.ID, file.StoredName, file.Size)
+		}
+		return err
+	}
+
+	reader, object, err := src.Get(ctx, file.StoredName)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	if err := dst.Put(ctx, file.StoredName, reader, object.Size, file.Type); err != nil {
+		return err
+	}
+
+	copied, err := dst.Stat(ctx, file.StoredName)
+	if err != nil {
+		return err
+	}
+	if copied.Size != object.Size {
+		return errors.New("размер перенесённого файла не совпадает с исходным")
+	}
+	if remove {
+		return src.Delete(ctx, file.StoredName)
+	}
+	return nil
+}
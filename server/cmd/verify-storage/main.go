@@ -0,0 +1,277 @@
+// Команда verify-storage проверяет, что содержимое файлов на месте и не повреждено:
+//
+//	go run ./cmd/verify-storage
+//
+// Каждый объект читается целиком и сверяется с SHA-256; с -quick проверяются только наличие и размер.
+// Дополнительные действия:
+//
+//	-backfill  вычислить SHA-256 файлов, загруженных до появления контрольных сумм,
+//	           и объединить одинаковые файлы в общие объекты
+//	-repair    пересчитать число ссылок на объекты по записям файлов
+//	-collect   удалить объекты, на которые больше нет ссылок
+//
+// Параметры хранилища и БД берутся из тех же переменных окружения, что и у сервера.
+// Если найдены отсутствующие или повреждённые объекты, команда завершается с ошибкой
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"io"
+	"log"
+	"server/initializers"
+	"server/models"
+	"server/storage"
+	"time"
+)
+
+var errCorrupted = errors.New("содержимое не совпадает с контрольной суммой")
+
+func main() {
+	quick := flag.Bool("quick", false, "проверять только наличие и размер объектов, не читая их")
+	backfill := flag.Bool("backfill", false, "вычислить контрольные суммы старых файлов и объединить одинаковые")
+	repair := flag.Bool("repair", false, "пересчитать число ссылок на объекты")
+	collect := flag.Bool("collect", false, "удалить объекты без ссылок")
+	flag.Parse()
+
+	initializers.ConnectToDb()
+	initializers.ConnectToStorage()
+	ctx := context.Background()
+	db, store := initializers.DB, initializers.Storage
+
+	if *backfill {
+		if err := backfillFiles(ctx, db, store); err != nil {
+			log.Fatal("Failed to backfill checksums: ", err)
+		}
+	}
+
+	stale, err := countStaleRefs(db)
+	if err != nil {
+		log.Fatal("Failed to count blob references: ", err)
+	}
+	if stale > 0 && *repair {
+		if err := repairRefs(db); err != nil {
+			log.Fatal("Failed to repair blob references: ", err)
+		}
+		log.Printf("Reference counts repaired for %d blobs", stale)
+	} else if stale > 0 {
+		log.Printf("%d blobs have wrong reference counts, run with -repair to fix", stale)
+	}
+
+	if *collect {
+		removed, err := collectBlobs(ctx, db, store, time.Now().Add(-models.BlobCollectDelay))
+		if err != nil {
+			log.Fatal("Failed to collect unreferenced blobs: ", err)
+		}
+		log.Printf("Removed %d unreferenced blobs", removed)
+	}
+
+	var checked, missing, corrupted int
+	report := func(key string, err error) {
+		checked++
+		switch {
+		case err == nil:
+		case errors.Is(err, storage.ErrNotFound):
+			missing++
+			log.Printf("Object %s is missing", key)
+		default:
+			corrupted++
+			log.Printf("Object %s is corrupted: %v", key, err)
+		}
+	}
+
+	var blobs []models.Blob
+	err = db.Order("id").FindInBatches(&blobs, 500, func(tx *gorm.DB, batch int) error {
+		for _, blob := range blobs {
+			report(blob.StoredName, verifyObject(ctx, store, blob.StoredName, blob.Size, blob.SHA256, *quick))
+		}
+		return nil
+	}).Error
+	if err != nil {
+		log.Fatal("Failed to read blobs: ", err)
+	}
+
+	// Файлы без контрольной суммы проверяются по размеру
+	var files []models.File
+	err = db.Unscoped().Where("sha256 IS NULL OR sha256 = ''").Order("id").FindInBatches(&files, 500, func(tx *gorm.DB, batch int) error {
+		for _, file := range files {
+			report(file.StoredName, verifyObject(ctx, store, file.StoredName, file.Size, "", true))
+		}
+		return nil
+	}).Error
+	if err != nil {
+		log.Fatal("Failed to read files: ", err)
+	}
+
+	log.Printf("Verification finished: checked %d, missing %d, corrupted %d", checked, missing, corrupted)
+	if missing > 0 || corrupted > 0 {
+		log.Fatal("Storage verification failed")
+	}
+}
+
+// verifyObject сверяет объект с ожидаемым размером и, если не quick, с контрольной суммой
+func verifyObject(ctx context.Context, store storage.Storage, key string, size int64, sum string, quick bool) error {
+	if quick || sum == "" {
+		object, err := store.Stat(ctx, key)
+		if err != nil {
+			return err
+		}
+		if object.Size != size {
+			return fmt.Errorf("размер %d вместо %d", object.Size, size)
+		}
+		return nil
+	}
+
+	actualSum, actualSize, err := objectSHA256(ctx, store, key)
+	if err != nil {
+		return err
+	}
+	if actualSize != size {
+		return fmt.Errorf("размер %d вместо %d", actualSize, size)
+	}
+	if actualSum != sum {
+		return errCorrupted
+	}
+	return nil
+}
+
+func objectSHA256(ctx context.Context, store storage.Storage, key string) (string, int64, error) {
+	reader, _, err := store.Get(ctx, key)
+	if err != nil {
+		return "", 0, err
+	}
+	defer reader.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, reader)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
+
+// backfillFiles вычисляет контрольные суммы файлов, загруженных до их появления. Первый файл с таким
+// содержимым становится общим объектом под прежним ключом, копии ссылаются на него и удаляются из хранилища
+func backfillFiles(ctx context.Context, db *gorm.DB, store storage.Storage) error {
+	var linked, merged, missing int
+	var files []models.File
+	err := db.Unscoped().Where("sha256 IS NULL OR sha256 = ''").Order("id").FindInBatches(&files, 100, func(tx *gorm.DB, batch int) error {
+		for _, file := range files {
+			sum, size, err := objectSHA256(ctx, store, file.StoredName)
+			if errors.Is(err, storage.ErrNotFound) {
+				missing++
+				log.Printf("File %d (%s) is missing, checksum not computed", file.ID, file.StoredName)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			duplicate, err := linkFile(db, file, sum, size)
+			if err != nil {
+				return err
+			}
+			if duplicate {
+				merged++
+				if err := store.Delete(ctx, file.StoredName); err != nil {
+					log.Printf("Failed to delete duplicate object %s: %v", file.StoredName, err)
+				}
+			} else {
+				linked++
+			}
+		}
+		return nil
+	}).Error
+	log.Printf("Backfill finished: new blobs %d, merged duplicates %d, missing %d", linked, merged, missing)
+	return err
+}
+
+// linkFile записывает контрольную сумму файла и привязывает его к объекту с тем же содержимым.
+// Возвращает true, если такой объект уже был и собственная копия файла больше не нужна
+func linkFile(db *gorm.DB, file models.File, sum string, size int64) (bool, error) {
+	duplicate := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var blob models.Blob
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("sha256 = ?", sum).First(&blob).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			blob = models.Blob{SHA256: sum, StoredName: file.StoredName, Size: size, RefCount: 1}
+			if err := tx.Create(&blob).Error; err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		default:
+			duplicate = blob.StoredName != file.StoredName
+			if err := tx.Model(&blob).UpdateColumns(map[string]interface{}{
+				"ref_count":  gorm.Expr("ref_count + 1"),
+				"updated_at": time.Now(),
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Model(&file).UpdateColumns(map[string]interface{}{
+			"sha256":      sum,
+			"stored_name": blob.StoredName,
+		}).Error
+	})
+	return duplicate, err
+}
+
+// Число ссылок на объект - число записей о файлах с его ключом, включая удалённые:
+// на удалённые файлы могут ссылаться старые версии сдач
+const refCountQuery = "(SELECT COUNT(*) FROM files WHERE files.stored_name = blobs.stored_name)"
+
+func countStaleRefs(db *gorm.DB) (int64, error) {
+	var count int64
+	err := db.Model(&models.Blob{}).Where("ref_count <> " + refCountQuery).Count(&count).Error
+	return count, err
+}
+
+func repairRefs(db *gorm.DB) error {
+	return db.Model(&models.Blob{}).Where("ref_count <> " + refCountQuery).UpdateColumns(map[string]interface{}{
+		"ref_count":  gorm.Expr(refCountQuery),
+		"updated_at": time.Now(),
+	}).Error
+}
+
+// collectBlobs удаляет объекты, на которые давно никто не ссылается. Запись блокируется на время
+// удаления объекта: загрузка того же содержимого дождётся её и сохранит объект заново
+func collectBlobs(ctx context.Context, db *gorm.DB, store storage.Storage, before time.Time) (int, error) {
+	var candidates []models.Blob
+	if err := db.Where("ref_count = 0 AND updated_at < ?", before).Find(&candidates).Error; err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, candidate := range candidates {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var blob models.Blob
+			// Объект с неверным счётчиком, на который ещё ссылаются файлы, не удаляется
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("ref_count = 0 AND updated_at < ?", before).
+				Where("NOT EXISTS (SELECT 1 FROM files WHERE files.stored_name = blobs.stored_name)").
+				First(&blob, candidate.ID).Error; err != nil {
+				return err
+			}
+			if err := store.Delete(ctx, blob.StoredName); err != nil {
+				return err
+			}
+			return tx.Delete(&blob).Error
+		})
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,713 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"net/http"
+	"server/models"
+	"server/models/DTO/applicationDTO"
+	"server/models/DTO/fileDTO"
+	"server/models/DTO/technologyDTO"
+	"server/reports"
+	"server/types"
+	"strconv"
+	"strings"
+)
+
+type ApplicationController struct {
+	DB             *gorm.DB
+	FileController *FileController
+}
+
+func NewApplicationController(db *gorm.DB, fileController *FileController) *ApplicationController {
+	return &ApplicationController{
+		DB:             db,
+		FileController: fileController,
+	}
+}
+
+var applicationStatusNames = map[int]string{
+	models.ApplicationRejected: "Отклонена",
+	models.ApplicationPending:  "На рассмотрении",
+	models.ApplicationAccepted: "Принята",
+}
+
+// loadFormQuestions возвращает вопросы анкеты хакатона в порядке отображения
+func loadFormQuestions(db *gorm.DB, hackathonID uint) ([]models.FormQuestion, error) {
+	var questions []models.FormQuestion
+	err := db.Where("hackathon_id = ?", hackathonID).Order("position, id").Find(&questions).Error
+	return questions, err
+}
+
+// hackathonHasForm проверяет, требует ли хакатон заполнения анкеты при записи
+func hackathonHasForm(db *gorm.DB, hackathonID uint) (bool, error) {
+	var count int64
+	err := db.Model(&models.FormQuestion{}).Where("hackathon_id = ?", hackathonID).Count(&count).Error
+	return count > 0, err
+}
+
+// GetForm возвращает анкету хакатона
+func (ac *ApplicationController) GetForm(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	questions, err := loadFormQuestions(ac.DB, uint(hackathonID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении анкеты"})
+		return
+	}
+
+	result := make([]applicationDTO.QuestionGet, 0, len(questions))
+	for _, question := range questions {
+		result = append(result, applicationDTO.QuestionGet{
+			ID:       question.ID,
+			Label:    question.Label,
+			Type:     question.Type,
+			Required: question.Required,
+			Multiple: question.Multiple,
+			Options:  question.Options,
+			Position: question.Position,
+		})
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// UpdateForm заменяет анкету хакатона. Ответы на удалённые вопросы сохраняются в поданных заявках
+func (ac *ApplicationController) UpdateForm(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	var dto applicationDTO.FormUpdate
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных", "details": err.Error()})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка валидации", "details": err.Error()})
+		return
+	}
+
+	for _, question := range dto.Questions {
+		if question.Type == models.FormQuestionChoice && len(question.Options) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("У вопроса '%s' нет вариантов ответа", question.Label)})
+			return
+		}
+	}
+
+	err = ac.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("hackathon_id = ?", hackathonID).Delete(&models.FormQuestion{}).Error; err != nil {
+			return err
+		}
+
+		for i := range dto.Questions {
+			if err := tx.Create(dto.Questions[i].ToModel(uint(hackathonID), i)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении анкеты", "details": err.Error()})
+		return
+	}
+
+	ac.GetForm(c)
+}
+
+// Submit подаёт заявку на участие в хакатоне с ответами на анкету.
+// Отклонённую заявку можно подать повторно
+func (ac *ApplicationController) Submit(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	// Устанавливаем userID в контекст для FileController
+	c.Set("userID", userID)
+
+	var participant models.BndUserHackathon
+	if err := ac.DB.Where("user_id = ? AND hackathon_id = ?", userID, hackathonID).First(&participant).Error; err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Пользователь уже добавлен к хакатону"})
+		return
+	}
+
+	questions, err := loadFormQuestions(ac.DB, uint(hackathonID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении анкеты"})
+		return
+	}
+	if len(questions) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "У хакатона нет анкеты, запишитесь без заявки"})
+		return
+	}
+
+	if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка при парсинге формы: " + err.Error()})
+		return
+	}
+
+	var dto applicationDTO.Submit
+	if data := c.Request.FormValue("data"); data != "" {
+		if err := json.Unmarshal([]byte(data), &dto); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка при разборе JSON: " + err.Error()})
+			return
+		}
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка валидации", "details": err.Error()})
+		return
+	}
+
+	answers := make(map[uint]applicationDTO.AnswerInput, len(dto.Answers))
+	for _, answer := range dto.Answers {
+		answers[answer.QuestionID] = answer
+	}
+
+	// Сверяем ответы с анкетой
+	questionIDs := make(map[uint]bool, len(questions))
+	for _, question := range questions {
+		questionIDs[question.ID] = true
+
+		answer := answers[question.ID]
+		files := c.Request.MultipartForm.File[fmt.Sprintf("file_%d", question.ID)]
+
+		if msg := checkAnswer(ac.DB, question, answer, len(files)); msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg, "question_id": question.ID})
+			return
+		}
+	}
+	for questionID := range answers {
+		if !questionIDs[questionID] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Ответ на вопрос, которого нет в анкете", "question_id": questionID})
+			return
+		}
+	}
+
+	// Файлы загружаются до транзакции и привязываются к ответам после их создания
+	uploaded := make(map[uint][]*models.File, len(questions))
+	removeUploaded := func() {
+		for _, files := range uploaded {
+			for _, file := range files {
+				if err := ac.FileController.DeleteFile(file); err != nil {
+					log.Printf("Не удалось удалить файл %d: %v", file.ID, err)
+				}
+			}
+		}
+	}
+	for _, question := range questions {
+		for _, fileHeader := range c.Request.MultipartForm.File[fmt.Sprintf("file_%d", question.ID)] {
+			file, err := ac.FileController.UploadFile(c, fileHeader, 0, "application_answer")
+			if err != nil {
+				removeUploaded()
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при загрузке файла", "details": err.Error()})
+				return
+			}
+			uploaded[question.ID] = append(uploaded[question.ID], file)
+		}
+	}
+
+	var application models.Application
+	var replaced []models.File
+	err = ac.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ? AND hackathon_id = ?", userID, hackathonID).First(&application).Error
+		switch {
+		case err == nil:
+			if application.Status != models.ApplicationRejected {
+				return errApplicationExists
+			}
+			// Повторная подача: прежние ответы заменяются новыми, их файлы удаляются после сохранения
+			if err := tx.Where("owner_type = ? AND owner_id IN (?)", "application_answer",
+				tx.Model(&models.ApplicationAnswer{}).Select("id").Where("application_id = ?", application.ID)).
+				Find(&replaced).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("application_id = ?", application.ID).Delete(&models.ApplicationAnswer{}).Error; err != nil {
+				return err
+			}
+			application.Status = models.ApplicationPending
+			application.ReviewComment = ""
+			if err := tx.Save(&application).Error; err != nil {
+				return err
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			application = models.Application{
+				UserID:      userID,
+				HackathonID: uint(hackathonID),
+				Status:      models.ApplicationPending,
+			}
+			if err := tx.Create(&application).Error; err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		for _, question := range questions {
+			input := answers[question.ID]
+			answer := models.ApplicationAnswer{
+				ApplicationID: application.ID,
+				QuestionID:    question.ID,
+				Text:          input.Text,
+				Choices:       input.Choices,
+			}
+			if err := tx.Create(&answer).Error; err != nil {
+				return err
+			}
+
+			if len(input.Technologies) > 0 {
+				var technologies []*models.Technology
+				if err := tx.Find(&technologies, input.Technologies).Error; err != nil {
+					return err
+				}
+				if err := tx.Model(&answer).Association("Technologies").Append(technologies); err != nil {
+					return err
+				}
+			}
+
+			for _, file := range uploaded[question.ID] {
+				if err := tx.Model(file).Update("owner_id", answer.ID).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		removeUploaded()
+		if errors.Is(err, errApplicationExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Заявка уже подана", "status": application.Status})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при подаче заявки", "details": err.Error()})
+		return
+	}
+
+	for i := range replaced {
+		if err := ac.FileController.DeleteFile(&replaced[i]); err != nil {
+			log.Printf("Не удалось удалить файл %d: %v", replaced[i].ID, err)
+		}
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Заявка отправлена на рассмотрение", "id": application.ID, "status": application.Status})
+}
+
+var errApplicationExists = errors.New("заявка уже подана")
+
+// checkAnswer проверяет ответ на вопрос анкеты и возвращает текст ошибки, если ответ некорректен
+func checkAnswer(db *gorm.DB, question models.FormQuestion, answer applicationDTO.AnswerInput, files int) string {
+	switch question.Type {
+	case models.FormQuestionText:
+		if question.Required && strings.TrimSpace(answer.Text) == "" {
+			return fmt.Sprintf("Необходимо ответить на вопрос '%s'", question.Label)
+		}
+
+	case models.FormQuestionChoice:
+		if question.Required && len(answer.Choices) == 0 {
+			return fmt.Sprintf("Необходимо выбрать вариант в вопросе '%s'", question.Label)
+		}
+		if !question.Multiple && len(answer.Choices) > 1 {
+			return fmt.Sprintf("В вопросе '%s' можно выбрать только один вариант", question.Label)
+		}
+		allowed := make(map[string]bool, len(question.Options))
+		for _, option := range question.Options {
+			allowed[option] = true
+		}
+		for _, choice := range answer.Choices {
+			if !allowed[choice] {
+				return fmt.Sprintf("Недопустимый вариант '%s' в вопросе '%s'", choice, question.Label)
+			}
+		}
+
+	case models.FormQuestionFile:
+		if question.Required && files == 0 {
+			return fmt.Sprintf("Необходимо приложить файл к вопросу '%s'", question.Label)
+		}
+		if !question.Multiple && files > 1 {
+			return fmt.Sprintf("К вопросу '%s' можно приложить только один файл", question.Label)
+		}
+
+	case models.FormQuestionTechnology:
+		if question.Required && len(answer.Technologies) == 0 {
+			return fmt.Sprintf("Необходимо выбрать технологии в вопросе '%s'", question.Label)
+		}
+		if !question.Multiple && len(answer.Technologies) > 1 {
+			return fmt.Sprintf("В вопросе '%s' можно выбрать только одну технологию", question.Label)
+		}
+		if len(answer.Technologies) > 0 {
+			var count int64
+			if err := db.Model(&models.Technology{}).Where("id IN ?", answer.Technologies).Count(&count).Error; err != nil || int(count) != len(answer.Technologies) {
+				return fmt.Sprintf("Неизвестная технология в вопросе '%s'", question.Label)
+			}
+		}
+	}
+
+	return ""
+}
+
+// GetMy возвращает заявку текущего пользователя на хакатон
+func (ac *ApplicationController) GetMy(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	var application models.Application
+	if err := ac.DB.Where("user_id = ? AND hackathon_id = ?", userID, hackathonID).First(&application).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Заявка не найдена"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении заявки"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":            application.ID,
+		"status":        application.Status,
+		"reviewComment": application.ReviewComment,
+		"createdAt":     application.CreatedAt,
+	})
+}
+
+// loadApplications загружает заявки хакатона вместе с ответами
+func loadApplications(query *gorm.DB) ([]models.Application, error) {
+	var applications []models.Application
+	err := query.
+		Preload("User").
+		Preload("Answers", func(db *gorm.DB) *gorm.DB { return db.Order("question_id") }).
+		Preload("Answers.Technologies").
+		Preload("Answers.Files").
+		Order("applications.created_at, applications.id").
+		Find(&applications).Error
+	return applications, err
+}
+
+// toApplicationDTO формирует заявку для ответа организатору
+func toApplicationDTO(application models.Application, questions map[uint]models.FormQuestion) applicationDTO.Get {
+	result := applicationDTO.Get{
+		ID:            application.ID,
+		UserID:        application.UserID,
+		Username:      application.User.Username,
+		Email:         application.User.Email,
+		Status:        application.Status,
+		ReviewComment: application.ReviewComment,
+		CreatedAt:     application.CreatedAt,
+		Answers:       make([]applicationDTO.AnswerGet, 0, len(application.Answers)),
+	}
+
+	for _, answer := range application.Answers {
+		item := applicationDTO.AnswerGet{
+			QuestionID: answer.QuestionID,
+			Label:      questions[answer.QuestionID].Label,
+			Text:       answer.Text,
+			Choices:    answer.Choices,
+		}
+		for _, technology := range answer.Technologies {
+			item.Technologies = append(item.Technologies, technologyDTO.GetShort{ID: technology.ID, Name: technology.Name})
+		}
+		for _, file := range answer.Files {
+			item.Files = append(item.Files, fileDTO.GetShort{ID: file.ID, Name: file.Name, Size: file.Size, Type: file.Type})
+		}
+		result.Answers = append(result.Answers, item)
+	}
+
+	return result
+}
+
+// questionsByID загружает все вопросы хакатона, включая удалённые, чтобы подписать старые ответы
+func questionsByID(db *gorm.DB, hackathonID uint) (map[uint]models.FormQuestion, []models.FormQuestion, error) {
+	var questions []models.FormQuestion
+	if err := db.Unscoped().Where("hackathon_id = ?", hackathonID).Order("deleted_at DESC, position, id").Find(&questions).Error; err != nil {
+		return nil, nil, err
+	}
+
+	byID := make(map[uint]models.FormQuestion, len(questions))
+	for _, question := range questions {
+		byID[question.ID] = question
+	}
+	return byID, questions, nil
+}
+
+// GetAll возвращает заявки хакатона для организатора
+func (ac *ApplicationController) GetAll(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	var filter applicationDTO.Filter
+	if err := c.ShouldBindJSON(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат фильтров"})
+		return
+	}
+
+	query := ac.DB.Model(&models.Application{}).Where("hackathon_id = ?", hackathonID)
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при подсчете заявок"})
+		return
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	applications, err := loadApplications(query.Limit(limit).Offset(filter.Offset))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении заявок", "details": err.Error()})
+		return
+	}
+
+	questions, _, err := questionsByID(ac.DB, uint(hackathonID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении анкеты"})
+		return
+	}
+
+	list := make([]applicationDTO.Get, 0, len(applications))
+	for _, application := range applications {
+		list = append(list, toApplicationDTO(application, questions))
+	}
+
+	c.JSON(http.StatusOK, applicationDTO.ListResponse{
+		List:   list,
+		Total:  total,
+		Limit:  limit,
+		Offset: filter.Offset,
+	})
+}
+
+// Review принимает или отклоняет заявки. Принятие добавляет пользователя в участники,
+// отклонение ранее принятой заявки исключает его из хакатона
+func (ac *ApplicationController) Review(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	var dto applicationDTO.Review
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных", "details": err.Error()})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка валидации", "details": err.Error()})
+		return
+	}
+
+	type reviewFailure struct {
+		ID    uint   `json:"id"`
+		Error string `json:"error"`
+	}
+
+	updated := make([]uint, 0, len(dto.IDs))
+	failed := make([]reviewFailure, 0)
+
+	err = ac.DB.Transaction(func(tx *gorm.DB) error {
+		hackathon, err := lockHackathon(tx, uint(hackathonID))
+		if err != nil {
+			return err
+		}
+
+		var applications []models.Application
+		if err := tx.Where("hackathon_id = ? AND id IN ?", hackathon.ID, dto.IDs).Find(&applications).Error; err != nil {
+			return err
+		}
+
+		found := make(map[uint]bool, len(applications))
+		for _, application := range applications {
+			found[application.ID] = true
+		}
+		for _, id := range dto.IDs {
+			if !found[id] {
+				failed = append(failed, reviewFailure{ID: id, Error: "Заявка не найдена"})
+			}
+		}
+
+		removed := false
+		for _, application := range applications {
+			if application.Status == dto.Status {
+				updated = append(updated, application.ID)
+				continue
+			}
+
+			if dto.Status == models.ApplicationAccepted {
+				if hackathon.MaxParticipants > 0 {
+					count, err := countParticipants(tx, hackathon.ID)
+					if err != nil {
+						return err
+					}
+					if count >= int64(hackathon.MaxParticipants) {
+						failed = append(failed, reviewFailure{ID: application.ID, Error: "Достигнут лимит участников"})
+						continue
+					}
+				}
+
+				participant := models.BndUserHackathon{
+					UserID:        application.UserID,
+					HackathonID:   hackathon.ID,
+					HackathonRole: 1,
+				}
+				if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&participant).Error; err != nil {
+					return err
+				}
+			} else {
+				var teamCount int64
+				if err := tx.Model(&models.BndUserTeam{}).
+					Joins("JOIN teams ON teams.id = bnd_user_teams.team_id").
+					Where("bnd_user_teams.user_id = ? AND teams.hackathon_id = ? AND teams.deleted_at IS NULL", application.UserID, hackathon.ID).
+					Count(&teamCount).Error; err != nil {
+					return err
+				}
+				if teamCount > 0 {
+					failed = append(failed, reviewFailure{ID: application.ID, Error: "Участник уже состоит в команде"})
+					continue
+				}
+
+				result := tx.Where("user_id = ? AND hackathon_id = ? AND hackathon_role = 1", application.UserID, hackathon.ID).
+					Delete(&models.BndUserHackathon{})
+				if result.Error != nil {
+					return result.Error
+				}
+				removed = removed || result.RowsAffected > 0
+			}
+
+			if err := tx.Model(&application).Updates(map[string]interface{}{
+				"status":         dto.Status,
+				"review_comment": dto.Comment,
+			}).Error; err != nil {
+				return err
+			}
+			updated = append(updated, application.ID)
+		}
+
+		// Освободившиеся места отдаём очереди ожидания
+		if removed {
+			if _, err := promoteWaitlist(tx, hackathon); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при рассмотрении заявок", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"updated": updated, "failed": failed})
+}
+
+// Export выгружает все заявки хакатона с ответами в CSV
+func (ac *ApplicationController) Export(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	questionMap, questions, err := questionsByID(ac.DB, uint(hackathonID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении анкеты"})
+		return
+	}
+
+	applications, err := loadApplications(ac.DB.Where("hackathon_id = ?", hackathonID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении заявок", "details": err.Error()})
+		return
+	}
+
+	header := []string{"ID заявки", "Пользователь", "Email", "Статус", "Дата подачи"}
+	for _, question := range questions {
+		header = append(header, reports.SafeText(question.Label))
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=applications_%d.csv", hackathonID))
+
+	// BOM, чтобы Excel корректно распознал UTF-8
+	c.Writer.Write([]byte("\xEF\xBB\xBF"))
+
+	writer := csv.NewWriter(c.Writer)
+	writer.Write(header)
+
+	for _, application := range applications {
+		dto := toApplicationDTO(application, questionMap)
+
+		answers := make(map[uint]applicationDTO.AnswerGet, len(dto.Answers))
+		for _, answer := range dto.Answers {
+			answers[answer.QuestionID] = answer
+		}
+
+		row := []string{
+			strconv.FormatUint(uint64(dto.ID), 10),
+			reports.SafeText(dto.Username),
+			reports.SafeText(dto.Email),
+			applicationStatusNames[dto.Status],
+			dto.CreatedAt.Format("2006-01-02 15:04"),
+		}
+		for _, question := range questions {
+			row = append(row, reports.SafeText(formatAnswer(answers[question.ID])))
+		}
+		writer.Write(row)
+	}
+
+	writer.Flush()
+}
+
+// formatAnswer приводит ответ любого типа к строке для выгрузки
+func formatAnswer(answer applicationDTO.AnswerGet) string {
+	parts := make([]string, 0)
+	if answer.Text != "" {
+		parts = append(parts, answer.Text)
+	}
+	parts = append(parts, answer.Choices...)
+	for _, technology := range answer.Technologies {
+		parts = append(parts, technology.Name)
+	}
+	for _, file := range answer.Files {
+		parts = append(parts, file.Name)
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,231 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"server/archive"
+	"server/models"
+	"server/models/DTO/submissionDTO"
+	"server/types"
+	"strconv"
+	"strings"
+)
+
+var errArchiveRejected = errors.New("архив не принят")
+
+// archiveRules - правила проверки архивов хакатона
+func archiveRules(hackathon models.Hackathon) archive.Rules {
+	return archive.Rules{
+		RequiredFiles:       hackathon.ArchiveRequiredFiles,
+		ForbiddenExtensions: hackathon.ArchiveForbiddenExtensions,
+		MaxSize:             int64(hackathon.ArchiveMaxSizeMB) << 20,
+	}
+}
+
+// inspectUpload проверяет загружаемый архив. Для файлов, не являющихся zip или tar.gz, отчёт не составляется.
+// Архив с нарушениями правил отклоняется ошибкой errArchiveRejected с перечнем нарушений
+func inspectUpload(header *multipart.FileHeader, rules archive.Rules) (*archive.Report, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return inspectArchive(file, header.Size, rules)
+}
+
+// inspectStaged - то же для файла, загруженного частями
+func (fc *FileController) inspectStaged(session models.UploadSession, rules archive.Rules) (*archive.Report, error) {
+	file, err := fc.Staging.Open(session.Token)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return inspectArchive(file, session.Size, rules)
+}
+
+func inspectArchive(file io.ReaderAt, size int64, rules archive.Rules) (*archive.Report, error) {
+	// Формат определяется по содержимому, а не по расширению или заголовку клиента
+	start := make([]byte, 512)
+	n, err := file.ReadAt(start, 0)
+	if err != nil && n == 0 {
+		return nil, nil
+	}
+	format := archive.Detect(start[:n])
+	if format == "" {
+		return nil, nil
+	}
+
+	report, err := archive.Inspect(file, size, format, rules)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errArchiveRejected, err)
+	}
+	if len(report.Problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", errArchiveRejected, strings.Join(report.Problems, "; "))
+	}
+	return report, nil
+}
+
+func toInspectionModel(fileID uint, report *archive.Report) *models.ArchiveInspection {
+	inspection := &models.ArchiveInspection{
+		FileID:    fileID,
+		Format:    report.Format,
+		Files:     report.Files,
+		Size:      report.Size,
+		Truncated: report.Truncated,
+		Entries:   make([]models.ArchiveEntry, len(report.Entries)),
+	}
+	for i, entry := range report.Entries {
+		inspection.Entries[i] = models.ArchiveEntry{Path: entry.Path, Size: entry.Size, Dir: entry.Dir}
+	}
+	return inspection
+}
+
+func inspectionURL(hackathonID, fileID uint) string {
+	return fmt.Sprintf("/hackathon/%d/inspections/%d", hackathonID, fileID)
+}
+
+// applyInspections добавляет к файлам версий итоги проверки архивов
+func applyInspections(db *gorm.DB, hackathonID uint, versions []submissionDTO.Get) error {
+	var fileIDs []uint
+	for _, version := range versions {
+		for _, artifact := range version.Artifacts {
+			if artifact.File != nil {
+				fileIDs = append(fileIDs, artifact.File.ID)
+			}
+		}
+	}
+	if len(fileIDs) == 0 {
+		return nil
+	}
+
+	var inspections []models.ArchiveInspection
+	if err := db.Omit("Entries").Where("file_id IN ?", fileIDs).Find(&inspections).Error; err != nil {
+		return err
+	}
+	byFile := make(map[uint]models.ArchiveInspection, len(inspections))
+	for _, inspection := range inspections {
+		byFile[inspection.FileID] = inspection
+	}
+
+	for _, version := range versions {
+		for i, artifact := range version.Artifacts {
+			if artifact.File == nil {
+				continue
+			}
+			if inspection, ok := byFile[artifact.File.ID]; ok {
+				version.Artifacts[i].Inspection = &submissionDTO.Inspection{
+					Format:    inspection.Format,
+					Files:     inspection.Files,
+					Size:      inspection.Size,
+					Truncated: inspection.Truncated,
+					URL:       inspectionURL(hackathonID, inspection.FileID),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// GetArchiveInspection возвращает список файлов архива. Организаторам доступны все архивы хакатона,
+// судьям - архивы назначенных им команд без конфликта интересов, участникам - архивы своей команды.
+// При слепой оценке корневой каталог архива в списке для судьи заменяется кодом команды
+func (hc *HackathonController) GetArchiveInspection(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+	fileID, err := strconv.ParseUint(c.Param("file_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID файла"})
+		return
+	}
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	// Команда, в версиях которой есть этот файл
+	var teamIDs []uint
+	if err := hc.DB.Model(&models.SubmissionArtifact{}).
+		Joins("JOIN submissions ON submissions.id = submission_artifacts.submission_id AND submissions.deleted_at IS NULL").
+		Where("submission_artifacts.file_id = ? AND submissions.hackathon_id = ?", fileID, hackathonID).
+		Distinct().Pluck("submissions.team_id", &teamIDs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при поиске файла"})
+		return
+	}
+	if len(teamIDs) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Файл не найден среди сдач хакатона"})
+		return
+	}
+
+	var member models.BndUserHackathon
+	if err := hc.DB.Where("user_id = ? AND hackathon_id = ?", userID, hackathonID).First(&member).Error; err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "У вас нет доступа к сдачам этого хакатона"})
+		return
+	}
+	if member.HackathonRole < 2 {
+		link, err := userTeamInHackathon(hc.DB, userID, uint(hackathonID))
+		if err != nil || link.TeamID != teamIDs[0] {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Архив принадлежит другой команде"})
+			return
+		}
+	}
+
+	blindCode := ""
+	if member.HackathonRole == 2 {
+		access, err := loadJudgeAccess(hc.DB, uint(hackathonID))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке назначений судьи"})
+			return
+		}
+		if reason := access.check(teamIDs[0], userID); reason != "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": reason})
+			return
+		}
+
+		var hackathon models.Hackathon
+		if err := hc.DB.First(&hackathon, hackathonID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+			return
+		}
+		if blindFor(hackathon, member.HackathonRole) {
+			teams := make([]models.Team, 1)
+			if err := hc.DB.First(&teams[0], teamIDs[0]).Error; err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Команда не найдена"})
+				return
+			}
+			if err := ensureBlindCodes(hc.DB, teams); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обезличивании команды"})
+				return
+			}
+			blindCode = teams[0].BlindCode
+		}
+	}
+
+	var inspection models.ArchiveInspection
+	if err := hc.DB.Where("file_id = ?", fileID).First(&inspection).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Файл не проверялся как архив"})
+		return
+	}
+
+	tree := submissionDTO.Tree{
+		Inspection: submissionDTO.Inspection{
+			Format:    inspection.Format,
+			Files:     inspection.Files,
+			Size:      inspection.Size,
+			Truncated: inspection.Truncated,
+			URL:       inspectionURL(uint(hackathonID), inspection.FileID),
+		},
+		Entries: make([]submissionDTO.TreeEntry, len(inspection.Entries)),
+	}
+	for i, entry := range inspection.Entries {
+		tree.Entries[i] = submissionDTO.TreeEntry{Path: entry.Path, Size: entry.Size, Dir: entry.Dir}
+	}
+	if blindCode != "" {
+		blindTreeRoot(tree.Entries, blindCode)
+	}
+	c.JSON(http.StatusOK, tree)
+}
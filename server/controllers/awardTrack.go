@@ -0,0 +1,435 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"net/http"
+	"server/models"
+	"server/models/DTO/awardDTO"
+	"server/models/DTO/awardTrackDTO"
+	"server/types"
+	"strconv"
+)
+
+func toAwardTrackDTO(track models.AwardTrack) awardTrackDTO.Get {
+	result := awardTrackDTO.Get{
+		ID:          track.ID,
+		Name:        track.Name,
+		Description: track.Description,
+		Kind:        track.Kind,
+		OptIn:       track.OptIn,
+		Criteria:    make([]awardTrackDTO.Criterion, 0, len(track.Criteria)),
+		Awards:      make([]awardDTO.Get, 0, len(track.Awards)),
+	}
+	for _, criterion := range track.Criteria {
+		result.Criteria = append(result.Criteria, awardTrackDTO.Criterion{ID: criterion.ID, Name: criterion.Name})
+	}
+	for _, award := range track.Awards {
+		result.Awards = append(result.Awards, awardDTO.Get{
+			ID:           award.ID,
+			PlaceFrom:    award.PlaceFrom,
+			PlaceTo:      award.PlaceTo,
+			MoneyAmount:  award.MoneyAmount,
+			Additionally: award.Additionally,
+			TrackID:      award.TrackID,
+		})
+	}
+	return result
+}
+
+// trackByID загружает номинацию хакатона из URL
+func (hc *HackathonController) trackByID(c *gin.Context) (models.AwardTrack, bool) {
+	var track models.AwardTrack
+	if err := hc.DB.Where("id = ? AND hackathon_id = ?", c.Param("track_id"), c.Param("hackathon_id")).First(&track).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Номинация не найдена"})
+		return track, false
+	}
+	return track, true
+}
+
+// bindAwardTrack читает и проверяет номинацию из запроса, возвращая её критерии
+func (hc *HackathonController) bindAwardTrack(c *gin.Context, hackathon models.Hackathon) (awardTrackDTO.Create, []models.Criteria, bool) {
+	var dto awardTrackDTO.Create
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return dto, nil, false
+	}
+	if err := validator.New().Struct(dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка валидации: " + err.Error()})
+		return dto, nil, false
+	}
+
+	if dto.Kind != models.AwardTrackCriteria && len(dto.Criteria) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Критерии указываются только для номинации по критериям"})
+		return dto, nil, false
+	}
+	if dto.Kind == models.AwardTrackAudience && hackathon.VotingMode == models.VotingOff {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Номинация зрительских симпатий требует включённого голосования"})
+		return dto, nil, false
+	}
+	if dto.Kind == models.AwardTrackCriteria && len(dto.Criteria) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Для номинации по критериям нужен хотя бы один критерий"})
+		return dto, nil, false
+	}
+
+	var criteria []models.Criteria
+	if len(dto.Criteria) > 0 {
+		ids := uniqueIDs(dto.Criteria)
+		if err := hc.DB.Where("id IN ? AND hackathon_id = ? AND step_id IS NULL", dto.Criteria, hackathon.ID).Find(&criteria).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке критериев", "details": err.Error()})
+			return dto, nil, false
+		}
+		if len(criteria) != len(ids) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Не все критерии относятся к хакатону"})
+			return dto, nil, false
+		}
+	}
+
+	return dto, criteria, true
+}
+
+// saveAwardTrack сохраняет номинацию, заменяя её критерии и призы
+func saveAwardTrack(tx *gorm.DB, track *models.AwardTrack, dto awardTrackDTO.Create, criteria []models.Criteria) error {
+	track.Name = dto.Name
+	track.Description = dto.Description
+	track.Kind = dto.Kind
+	track.OptIn = dto.OptIn
+
+	if err := tx.Omit("Criteria", "Awards").Save(track).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(track).Omit("Criteria.*").Association("Criteria").Replace(criteria); err != nil {
+		return err
+	}
+
+	if err := tx.Where("track_id = ?", track.ID).Delete(&models.Award{}).Error; err != nil {
+		return err
+	}
+	for _, input := range dto.Awards {
+		award := input.ToModel(track.HackathonID)
+		award.TrackID = &track.ID
+		if err := tx.Create(award).Error; err != nil {
+			return err
+		}
+	}
+
+	// Выбор организатора теряет смысл, если номинация стала считаться по критериям
+	if track.Kind != models.AwardTrackManual {
+		if err := tx.Unscoped().Where("track_id = ?", track.ID).Delete(&models.AwardTrackPick{}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAwardTracks возвращает номинации хакатона; для участника отмечает, подала ли его команда заявку
+func (hc *HackathonController) GetAwardTracks(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	var tracks []models.AwardTrack
+	if err := hc.DB.Preload("Criteria", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Awards", func(db *gorm.DB) *gorm.DB { return db.Order("place_from") }).
+		Where("hackathon_id = ?", hackathonID).
+		Order("id").
+		Find(&tracks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении номинаций", "details": err.Error()})
+		return
+	}
+
+	trackIDs := make([]uint, len(tracks))
+	for i, track := range tracks {
+		trackIDs[i] = track.ID
+	}
+
+	var entries []models.AwardTrackEntry
+	if err := hc.DB.Where("track_id IN ?", trackIDs).Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении заявок", "details": err.Error()})
+		return
+	}
+
+	var picks []models.AwardTrackPick
+	if err := hc.DB.Preload("Team").Where("track_id IN ?", trackIDs).Order("place").Find(&picks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении победителей", "details": err.Error()})
+		return
+	}
+
+	var myTeamID uint
+	if link, err := userTeamInHackathon(hc.DB, userID, uint(hackathonID)); err == nil {
+		myTeamID = link.TeamID
+	}
+
+	// Выбор организатора виден всем только после публикации результатов
+	var hackathon models.Hackathon
+	if err := hc.DB.First(&hackathon, hackathonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return
+	}
+	var participant models.BndUserHackathon
+	if err := hc.DB.Where("user_id = ? AND hackathon_id = ?", userID, hackathonID).First(&participant).Error; err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Вы не участвуете в этом хакатоне"})
+		return
+	}
+	showPicks := hackathon.ResultsPublished() || participant.HackathonRole >= 3
+
+	result := make([]awardTrackDTO.Get, 0, len(tracks))
+	for _, track := range tracks {
+		item := toAwardTrackDTO(track)
+		for _, entry := range entries {
+			if entry.TrackID != track.ID {
+				continue
+			}
+			item.Entries++
+			if entry.TeamID == myTeamID {
+				item.Entered = true
+			}
+		}
+		if showPicks {
+			for _, pick := range picks {
+				if pick.TrackID == track.ID {
+					item.Picks = append(item.Picks, awardTrackDTO.Pick{TeamID: pick.TeamID, TeamName: pick.Team.Name, Place: pick.Place})
+				}
+			}
+		}
+		result = append(result, item)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CreateAwardTrack создаёт номинацию хакатона
+func (hc *HackathonController) CreateAwardTrack(c *gin.Context) {
+	hackathon, ok := hc.unpublishedHackathon(c)
+	if !ok {
+		return
+	}
+
+	dto, criteria, ok := hc.bindAwardTrack(c, hackathon)
+	if !ok {
+		return
+	}
+
+	track := models.AwardTrack{HackathonID: hackathon.ID}
+	if err := hc.DB.Transaction(func(tx *gorm.DB) error {
+		return saveAwardTrack(tx, &track, dto, criteria)
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании номинации", "details": err.Error()})
+		return
+	}
+
+	if err := hc.DB.Preload("Criteria").Preload("Awards").First(&track, track.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении номинации", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, toAwardTrackDTO(track))
+}
+
+// UpdateAwardTrack изменяет номинацию хакатона
+func (hc *HackathonController) UpdateAwardTrack(c *gin.Context) {
+	hackathon, ok := hc.unpublishedHackathon(c)
+	if !ok {
+		return
+	}
+
+	track, ok := hc.trackByID(c)
+	if !ok {
+		return
+	}
+
+	dto, criteria, ok := hc.bindAwardTrack(c, hackathon)
+	if !ok {
+		return
+	}
+
+	if err := hc.DB.Transaction(func(tx *gorm.DB) error {
+		return saveAwardTrack(tx, &track, dto, criteria)
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при изменении номинации", "details": err.Error()})
+		return
+	}
+
+	if err := hc.DB.Preload("Criteria").Preload("Awards").First(&track, track.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении номинации", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toAwardTrackDTO(track))
+}
+
+// DeleteAwardTrack удаляет номинацию вместе с призами, заявками и выбором организатора
+func (hc *HackathonController) DeleteAwardTrack(c *gin.Context) {
+	if _, ok := hc.unpublishedHackathon(c); !ok {
+		return
+	}
+
+	track, ok := hc.trackByID(c)
+	if !ok {
+		return
+	}
+
+	err := hc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&track).Association("Criteria").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Where("track_id = ?", track.ID).Delete(&models.Award{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("track_id = ?", track.ID).Delete(&models.AwardTrackEntry{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("track_id = ?", track.ID).Delete(&models.AwardTrackPick{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&track).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении номинации", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Номинация удалена"})
+}
+
+// trackEntryTeam проверяет, что номинация принимает заявки, а пользователь может подавать их от команды
+func (hc *HackathonController) trackEntryTeam(c *gin.Context) (models.AwardTrack, models.BndUserTeam, bool) {
+	var link models.BndUserTeam
+
+	track, ok := hc.trackByID(c)
+	if !ok {
+		return track, link, false
+	}
+	if !track.OptIn {
+		c.JSON(http.StatusConflict, gin.H{"error": "В номинации участвуют все команды, заявка не нужна"})
+		return track, link, false
+	}
+
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+	link, err := userTeamInHackathon(hc.DB, userID, track.HackathonID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Вы не состоите в команде на этом хакатоне"})
+		return track, link, false
+	}
+	if !models.TeamRoleCan(link.TeamRole, models.TeamActionUpload) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Заявку на номинацию подаёт тот, кто сдаёт проект команды"})
+		return track, link, false
+	}
+	return track, link, true
+}
+
+// EnterAwardTrack подаёт заявку команды на участие в номинации
+func (hc *HackathonController) EnterAwardTrack(c *gin.Context) {
+	track, link, ok := hc.trackEntryTeam(c)
+	if !ok {
+		return
+	}
+
+	entry := models.AwardTrackEntry{TrackID: track.ID, TeamID: link.TeamID}
+	err := hc.DB.Where(&entry).First(&models.AwardTrackEntry{}).Error
+	if err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Команда уже участвует в номинации"})
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке заявки", "details": err.Error()})
+		return
+	}
+
+	if err := hc.DB.Create(&entry).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при подаче заявки", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Команда участвует в номинации", "trackId": track.ID})
+}
+
+// LeaveAwardTrack отзывает заявку команды на номинацию
+func (hc *HackathonController) LeaveAwardTrack(c *gin.Context) {
+	track, link, ok := hc.trackEntryTeam(c)
+	if !ok {
+		return
+	}
+
+	result := hc.DB.Unscoped().Where("track_id = ? AND team_id = ?", track.ID, link.TeamID).Delete(&models.AwardTrackEntry{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при отзыве заявки", "details": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Команда не участвует в номинации"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Заявка на номинацию отозвана"})
+}
+
+// SetTrackPicks сохраняет победителей номинации, которую решает организатор, в порядке мест
+func (hc *HackathonController) SetTrackPicks(c *gin.Context) {
+	var dto awardTrackDTO.PicksSet
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+	if err := validator.New().Struct(dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка валидации: " + err.Error()})
+		return
+	}
+
+	hackathon, ok := hc.unpublishedHackathon(c)
+	if !ok {
+		return
+	}
+
+	track, ok := hc.trackByID(c)
+	if !ok {
+		return
+	}
+	if track.Kind != models.AwardTrackManual {
+		c.JSON(http.StatusConflict, gin.H{"error": "Победители номинации определяются по критериям"})
+		return
+	}
+
+	if len(uniqueIDs(dto.Teams)) != len(dto.Teams) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Команды не должны повторяться"})
+		return
+	}
+
+	// В номинации с заявками можно выбрать только подавшие заявку команды
+	query := hc.DB.Model(&models.Team{}).Where("teams.id IN ? AND teams.hackathon_id = ?", dto.Teams, hackathon.ID)
+	if track.OptIn {
+		query = query.Joins("JOIN award_track_entries ON award_track_entries.team_id = teams.id AND award_track_entries.track_id = ?", track.ID)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке команд", "details": err.Error()})
+		return
+	}
+	if int(count) != len(dto.Teams) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Не все команды участвуют в номинации"})
+		return
+	}
+
+	err := hc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("track_id = ?", track.ID).Delete(&models.AwardTrackPick{}).Error; err != nil {
+			return err
+		}
+		for i, teamID := range dto.Teams {
+			pick := models.AwardTrackPick{TrackID: track.ID, TeamID: teamID, Place: i + 1}
+			if err := tx.Create(&pick).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении победителей", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Победители номинации сохранены", "teams": dto.Teams})
+}
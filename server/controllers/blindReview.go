@@ -0,0 +1,241 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"path/filepath"
+	"server/models"
+	"server/models/DTO/submissionDTO"
+	"server/types"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// blindArtifactLabels - латинские метки артефактов для обезличенных имён файлов
+var blindArtifactLabels = map[int]string{
+	models.ArtifactSourceArchive: "source",
+	models.ArtifactSlides:        "slides",
+}
+
+// blindProjectLabel - метка файла проекта, загруженного до появления версий сдачи
+const blindProjectLabel = "project"
+
+// blindFor проверяет, скрыты ли команды хакатона от пользователя с ролью role.
+// Обезличивание касается судей; организаторы видят команды всегда
+func blindFor(hackathon models.Hackathon, role int) bool {
+	return role == 2 && hackathon.BlindActive(time.Now())
+}
+
+// blindTeamFile проверяет, что файл принадлежит команде хакатона со слепой оценкой
+// и пользователь - судья не из этой команды, то есть файл нельзя отдавать под исходным именем
+func blindTeamFile(db *gorm.DB, file models.File, userID uint) (bool, error) {
+	if file.OwnerType != "team" && file.OwnerType != "submission" {
+		return false, nil
+	}
+
+	var team models.Team
+	if err := db.Preload("Hackathon").First(&team, file.OwnerID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	var link models.BndUserHackathon
+	err := db.Where("user_id = ? AND hackathon_id = ?", userID, team.HackathonID).First(&link).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !blindFor(team.Hackathon, link.HackathonRole) {
+		return false, nil
+	}
+
+	var member int64
+	if err := db.Model(&models.BndUserTeam{}).
+		Where("user_id = ? AND team_id = ?", userID, team.ID).
+		Count(&member).Error; err != nil {
+		return false, err
+	}
+	return member == 0, nil
+}
+
+// ensureBlindCodes выдаёт коды командам, созданным до появления слепой оценки
+func ensureBlindCodes(db *gorm.DB, teams []models.Team) error {
+	for i := range teams {
+		if teams[i].BlindCode != "" {
+			continue
+		}
+		code, err := models.NewBlindCode()
+		if err != nil {
+			return err
+		}
+		if err := db.Model(&models.Team{}).Where("id = ?", teams[i].ID).Update("blind_code", code).Error; err != nil {
+			return err
+		}
+		teams[i].BlindCode = code
+	}
+	return nil
+}
+
+// findHackathonTeam ищет команду хакатона по числовому ID или по обезличенному коду
+func findHackathonTeam(db *gorm.DB, hackathonID uint, ref string) (models.Team, error) {
+	var team models.Team
+	query := db.Where("hackathon_id = ?", hackathonID)
+	if id, err := strconv.ParseUint(ref, 10, 32); err == nil {
+		query = query.Where("id = ?", id)
+	} else {
+		query = query.Where("blind_code = ?", ref)
+	}
+	err := query.First(&team).Error
+	return team, err
+}
+
+// blindFileName формирует имя файла без названия команды и исходного имени: P-1A2B3C4D-source.zip
+func blindFileName(code, label, name string) string {
+	return code + "-" + label + filepath.Ext(name)
+}
+
+// blindTreeRoot заменяет кодом команды общий корневой каталог архива: его обычно называют
+// по команде или репозиторию
+func blindTreeRoot(entries []submissionDTO.TreeEntry, code string) {
+	if len(entries) == 0 {
+		return
+	}
+	root, _, _ := strings.Cut(entries[0].Path, "/")
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Path, root+"/") && !(entry.Path == root && entry.Dir) {
+			return
+		}
+	}
+	for i := range entries {
+		entries[i].Path = code + strings.TrimPrefix(entries[i].Path, root)
+	}
+}
+
+func blindDownloadURL(hackathonID uint, code string, fileID uint) string {
+	return fmt.Sprintf("/hackathon/%d/blind/%s/files/%d", hackathonID, code, fileID)
+}
+
+// blindSubmission убирает из версии сдачи команду и автора, а файлы переименовывает по коду команды
+func blindSubmission(result submissionDTO.Get, hackathonID uint, code string) submissionDTO.Get {
+	result.TeamID = 0
+	result.SubmittedBy = ""
+
+	artifacts := make([]submissionDTO.Artifact, len(result.Artifacts))
+	for i, artifact := range result.Artifacts {
+		if artifact.File != nil {
+			file := *artifact.File
+			file.Name = blindFileName(code, blindArtifactLabels[artifact.Kind], file.Name)
+			artifact.File = &file
+			artifact.DownloadURL = blindDownloadURL(hackathonID, code, file.ID)
+		}
+		artifacts[i] = artifact
+	}
+	result.Artifacts = artifacts
+	return result
+}
+
+// DownloadBlindFile отдаёт судье файл команды по её коду под обезличенным именем
+func (hc *HackathonController) DownloadBlindFile(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	fileID, err := strconv.ParseUint(c.Param("file_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID файла"})
+		return
+	}
+
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	var team models.Team
+	if err := hc.DB.Where("hackathon_id = ? AND blind_code = ?", hackathonID, c.Param("code")).First(&team).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Команда не найдена"})
+		return
+	}
+
+	// Судья скачивает только файлы назначенных ему команд без конфликта интересов
+	var link models.BndUserHackathon
+	if err := hc.DB.Where("user_id = ? AND hackathon_id = ?", userID, hackathonID).First(&link).Error; err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "У вас нет доступа к файлам команд этого хакатона"})
+		return
+	}
+	if link.HackathonRole == 2 {
+		access, err := loadJudgeAccess(hc.DB, uint(hackathonID))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке назначений судьи"})
+			return
+		}
+		if reason := access.check(team.ID, userID); reason != "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": reason})
+			return
+		}
+	}
+
+	// Файл должен быть артефактом сдачи команды или проектом, загруженным до версий сдачи
+	label := blindProjectLabel
+	var artifact models.SubmissionArtifact
+	artifactErr := hc.DB.Joins("JOIN submissions ON submissions.id = submission_artifacts.submission_id AND submissions.deleted_at IS NULL").
+		Where("submissions.team_id = ? AND submission_artifacts.file_id = ?", team.ID, fileID).
+		First(&artifact).Error
+	switch {
+	case artifactErr == nil:
+		label = blindArtifactLabels[artifact.Kind]
+	case !errors.Is(artifactErr, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при поиске файла"})
+		return
+	}
+
+	var file models.File
+	query := hc.DB.Where("id = ?", fileID)
+	if artifactErr != nil {
+		query = query.Where("owner_type = ? AND owner_id = ?", "team", team.ID)
+	}
+	if err := query.First(&file).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Файл не найден"})
+		return
+	}
+
+	hc.FileController.serveFile(c, file, blindFileName(team.BlindCode, label, file.Name))
+}
+
+// RevealBlindReview досрочно раскрывает судьям команды хакатона со слепой оценкой
+func (hc *HackathonController) RevealBlindReview(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	var hackathon models.Hackathon
+	if err := hc.DB.First(&hackathon, hackathonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return
+	}
+
+	if !hackathon.BlindReview {
+		c.JSON(http.StatusConflict, gin.H{"error": "Слепая оценка для хакатона не включена"})
+		return
+	}
+	if hackathon.BlindRevealed {
+		c.JSON(http.StatusConflict, gin.H{"error": "Команды уже раскрыты"})
+		return
+	}
+
+	if err := hc.DB.Model(&hackathon).Update("blind_revealed", true).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при раскрытии команд", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Команды раскрыты судьям"})
+}
@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"io"
+	"server/models"
+	"time"
+)
+
+// errBlobGone - объект удалили как ненужный, пока загружалась копия того же содержимого
+var errBlobGone = errors.New("объект удалён из хранилища")
+
+// contentSHA256 вычисляет контрольную сумму содержимого и возвращает читатель в начало
+func contentSHA256(src io.ReadSeeker) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, src); err != nil {
+		return "", err
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// putBlob сохраняет содержимое в хранилище, если такого объекта ещё нет.
+// Возвращает true, если объект записан этим вызовом
+func (fc *FileController) putBlob(ctx context.Context, src io.ReadSeeker, size int64, sum, fileType string, force bool) (bool, error) {
+	if !force {
+		var count int64
+		if err := fc.DB.Model(&models.Blob{}).Where("sha256 = ?", sum).Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return false, nil
+		}
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+	return true, fc.Storage.Put(ctx, models.BlobKey(sum), src, size, fileType)
+}
+
+// acquireBlob добавляет ссылку на объект с контрольной суммой sum. Если записи об объекте нет,
+// она создаётся - но только когда объект только что записан (stored), иначе возвращается errBlobGone
+func acquireBlob(tx *gorm.DB, sum string, size int64, stored bool) (models.Blob, error) {
+	var blob models.Blob
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("sha256 = ?", sum).First(&blob).Error
+	if err == nil {
+		err = tx.Model(&blob).UpdateColumns(map[string]interface{}{
+			"ref_count":  gorm.Expr("ref_count + 1"),
+			"updated_at": time.Now(),
+		}).Error
+		return blob, err
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return blob, err
+	}
+	if !stored {
+		return blob, errBlobGone
+	}
+
+	// Параллельная загрузка того же содержимого могла создать запись первой
+	blob = models.Blob{SHA256: sum, StoredName: models.BlobKey(sum), Size: size, RefCount: 1}
+	err = tx.Clauses(clause.Returning{}, clause.OnConflict{
+		Columns: []clause.Column{{Name: "sha256"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"ref_count":  gorm.Expr("blobs.ref_count + 1"),
+			"updated_at": time.Now(),
+		}),
+	}).Create(&blob).Error
+	return blob, err
+}
+
+// releaseBlob убирает ссылку на объект. Объект без ссылок удаляет команда verify-storage -collect
+func releaseBlob(tx *gorm.DB, storedName string) error {
+	return tx.Model(&models.Blob{}).
+		Where("stored_name = ? AND ref_count > 0", storedName).
+		UpdateColumns(map[string]interface{}{
+			"ref_count":  gorm.Expr("ref_count - 1"),
+			"updated_at": time.Now(),
+		}).Error
+}
+
+// DeleteFile окончательно удаляет запись о файле, на который ничто не ссылается
+func (fc *FileController) DeleteFile(file *models.File) error {
+	return fc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(file).Error; err != nil {
+			return err
+		}
+		return releaseBlob(tx, file.StoredName)
+	})
+}
@@ -0,0 +1,639 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"server/models"
+	"server/models/DTO/certificateDTO"
+	"server/models/DTO/fileDTO"
+	"server/pdf"
+	"server/types"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CertificateController struct {
+	DB             *gorm.DB
+	FileController *FileController
+}
+
+func NewCertificateController(db *gorm.DB, fileController *FileController) *CertificateController {
+	return &CertificateController{
+		DB:             db,
+		FileController: fileController,
+	}
+}
+
+// Максимальный размер шрифта и фона шаблона
+const certificateAssetMaxSize = 20 << 20
+
+func toCertificateDTO(certificate models.Certificate) certificateDTO.Get {
+	return certificateDTO.Get{
+		Code:      certificate.Code,
+		Kind:      certificate.Kind,
+		KindName:  models.CertificateKindNames[certificate.Kind],
+		UserID:    certificate.UserID,
+		Username:  certificate.User.Username,
+		TeamName:  certificate.TeamName,
+		Place:     certificate.Place,
+		TrackName: certificate.TrackName,
+		IssuedAt:  certificate.CreatedAt,
+		RevokedAt: certificate.RevokedAt,
+	}
+}
+
+func toFileShort(file *models.File) fileDTO.GetShort {
+	return fileDTO.GetShort{ID: file.ID, Name: file.Name, Size: file.Size, Type: file.Type}
+}
+
+// readUpload читает загруженный файл шаблона в память для проверки формата
+func readUpload(header *multipart.FileHeader) ([]byte, error) {
+	if header.Size > certificateAssetMaxSize {
+		return nil, fmt.Errorf("файл %s больше %d МБ", header.Filename, certificateAssetMaxSize>>20)
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(file); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GetTemplate возвращает шаблон сертификатов хакатона
+func (cc *CertificateController) GetTemplate(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	var template models.CertificateTemplate
+	if err := cc.DB.Preload("Background").Preload("Font").Where("hackathon_id = ?", hackathonID).First(&template).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Шаблон сертификатов не загружен"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении шаблона"})
+		return
+	}
+
+	var fields []certificateDTO.Field
+	if err := json.Unmarshal([]byte(template.Fields), &fields); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Шаблон сертификатов повреждён"})
+		return
+	}
+
+	result := certificateDTO.TemplateGet{
+		PageWidth:  template.PageWidth,
+		PageHeight: template.PageHeight,
+		Fields:     fields,
+		UpdatedAt:  template.UpdatedAt,
+	}
+	if template.Font != nil {
+		result.Font = toFileShort(template.Font)
+	}
+	if template.Background != nil {
+		background := toFileShort(template.Background)
+		result.Background = &background
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// UpdateTemplate сохраняет шаблон сертификатов: multipart-форма с полем data и файлами font и background
+func (cc *CertificateController) UpdateTemplate(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+	// Устанавливаем userID в контекст для FileController
+	c.Set("userID", userID)
+
+	if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка при парсинге формы: " + err.Error()})
+		return
+	}
+
+	var dto certificateDTO.TemplateSet
+	if err := json.Unmarshal([]byte(c.Request.FormValue("data")), &dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка при разборе JSON: " + err.Error()})
+		return
+	}
+	validate := validator.New()
+	if err := validate.Struct(dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка валидации", "details": err.Error()})
+		return
+	}
+
+	var template models.CertificateTemplate
+	err = cc.DB.Where("hackathon_id = ?", hackathonID).First(&template).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении шаблона"})
+		return
+	}
+
+	// Файлы проверяются до сохранения, чтобы не оставлять на диске непригодные шрифты и фоны
+	fontHeader, backgroundHeader := formFile(c, "font"), formFile(c, "background")
+	if fontHeader == nil && template.FontID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Загрузите шрифт TrueType (.ttf) с нужными символами"})
+		return
+	}
+	if fontHeader != nil {
+		data, err := readUpload(fontHeader)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if _, err := pdf.LoadTrueType(data); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if backgroundHeader != nil {
+		data, err := readUpload(backgroundHeader)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if _, err := pdf.LoadImage(data); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	fields := make([]models.CertificateField, len(dto.Fields))
+	for i := range dto.Fields {
+		fields[i] = dto.Fields[i].ToModel()
+	}
+	fieldsJSON, err := json.Marshal(fields)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении шаблона"})
+		return
+	}
+
+	template.HackathonID = uint(hackathonID)
+	template.Fields = string(fieldsJSON)
+	// По умолчанию - A4 в альбомной ориентации
+	template.PageWidth, template.PageHeight = pdf.A4Height, pdf.A4Width
+	if dto.PageWidth > 0 {
+		template.PageWidth = dto.PageWidth
+	}
+	if dto.PageHeight > 0 {
+		template.PageHeight = dto.PageHeight
+	}
+
+	if fontHeader != nil {
+		file, err := cc.FileController.UploadFile(c, fontHeader, uint(hackathonID), "certificate_font")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при загрузке шрифта", "details": err.Error()})
+			return
+		}
+		template.FontID = file.ID
+	}
+	if dto.RemoveBackground {
+		template.BackgroundID = nil
+	}
+	if backgroundHeader != nil {
+		file, err := cc.FileController.UploadFile(c, backgroundHeader, uint(hackathonID), "certificate_background")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при загрузке фона", "details": err.Error()})
+			return
+		}
+		template.BackgroundID = &file.ID
+	}
+
+	if err := cc.DB.Save(&template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении шаблона"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Шаблон сертификатов сохранён"})
+}
+
+// formFile возвращает первый файл поля формы или nil
+func formFile(c *gin.Context, field string) *multipart.FileHeader {
+	if files := c.Request.MultipartForm.File[field]; len(files) > 0 {
+		return files[0]
+	}
+	return nil
+}
+
+// plannedCertificates составляет полный список сертификатов хакатона по участникам команд,
+// опубликованным победителям и судьям
+func plannedCertificates(db *gorm.DB, hackathonID uint) ([]models.Certificate, error) {
+	var certificates []models.Certificate
+
+	// Участники, состоящие в командах хакатона
+	var members []struct {
+		UserID   uint
+		TeamID   uint
+		TeamName string
+	}
+	if err := db.Model(&models.BndUserTeam{}).
+		Select("bnd_user_teams.user_id, bnd_user_teams.team_id, teams.name AS team_name").
+		Joins("JOIN teams ON teams.id = bnd_user_teams.team_id AND teams.deleted_at IS NULL").
+		Joins("JOIN bnd_user_hackathons ON bnd_user_hackathons.user_id = bnd_user_teams.user_id AND bnd_user_hackathons.hackathon_id = teams.hackathon_id").
+		Where("teams.hackathon_id = ? AND bnd_user_hackathons.hackathon_role = 1", hackathonID).
+		Scan(&members).Error; err != nil {
+		return nil, err
+	}
+	membersByTeam := make(map[uint][]uint)
+	for _, member := range members {
+		teamID := member.TeamID
+		membersByTeam[teamID] = append(membersByTeam[teamID], member.UserID)
+		certificates = append(certificates, models.Certificate{
+			HackathonID: hackathonID,
+			UserID:      member.UserID,
+			Subject:     "participation",
+			Kind:        models.CertificateParticipation,
+			TeamID:      &teamID,
+			TeamName:    member.TeamName,
+		})
+	}
+
+	// Победители - команды опубликованных результатов, получившие приз
+	var winners []models.ResultSnapshot
+	if err := db.Where("hackathon_id = ? AND award_id IS NOT NULL", hackathonID).Order("track_id NULLS FIRST, position").Find(&winners).Error; err != nil {
+		return nil, err
+	}
+	var tracks []models.AwardTrack
+	if err := db.Where("hackathon_id = ?", hackathonID).Find(&tracks).Error; err != nil {
+		return nil, err
+	}
+	trackNames := make(map[uint]string, len(tracks))
+	for _, track := range tracks {
+		trackNames[track.ID] = track.Name
+	}
+	for _, winner := range winners {
+		trackName := ""
+		if winner.TrackID != nil {
+			trackName = trackNames[*winner.TrackID]
+		}
+		for _, userID := range membersByTeam[winner.TeamID] {
+			teamID, awardID := winner.TeamID, *winner.AwardID
+			certificates = append(certificates, models.Certificate{
+				HackathonID: hackathonID,
+				UserID:      userID,
+				Subject:     fmt.Sprintf("winner:%d", awardID),
+				Kind:        models.CertificateWinner,
+				TeamID:      &teamID,
+				TeamName:    winner.TeamName,
+				Place:       winner.Place,
+				TrackName:   trackName,
+				AwardID:     &awardID,
+			})
+		}
+	}
+
+	// Менторы и судьи: судьёй считается тот, кому назначены команды или кто ставил оценки
+	var staff []struct {
+		UserID uint
+		Judge  bool
+	}
+	if err := db.Model(&models.BndUserHackathon{}).
+		Select(`user_id, EXISTS (SELECT 1 FROM judge_assignments WHERE judge_assignments.user_id = bnd_user_hackathons.user_id
+				AND judge_assignments.hackathon_id = bnd_user_hackathons.hackathon_id AND judge_assignments.deleted_at IS NULL)
+			OR EXISTS (SELECT 1 FROM scores JOIN teams ON teams.id = scores.team_id WHERE scores.user_id = bnd_user_hackathons.user_id
+				AND teams.hackathon_id = bnd_user_hackathons.hackathon_id AND scores.deleted_at IS NULL) AS judge`).
+		Where("hackathon_id = ? AND hackathon_role = 2", hackathonID).
+		Scan(&staff).Error; err != nil {
+		return nil, err
+	}
+	for _, member := range staff {
+		certificate := models.Certificate{HackathonID: hackathonID, UserID: member.UserID, Subject: "mentor", Kind: models.CertificateMentor}
+		if member.Judge {
+			certificate.Subject, certificate.Kind = "judge", models.CertificateJudge
+		}
+		certificates = append(certificates, certificate)
+	}
+
+	return certificates, nil
+}
+
+// revokeStaleWinnerCertificates отзывает сертификаты победителей, которых нет среди current:
+// после исправления и повторной публикации результатов команда могла лишиться награды
+func revokeStaleWinnerCertificates(tx *gorm.DB, hackathonID uint, current []models.Certificate) (int64, error) {
+	keep := make([][]interface{}, 0)
+	for _, certificate := range current {
+		if certificate.Kind == models.CertificateWinner {
+			keep = append(keep, []interface{}{certificate.UserID, certificate.Subject})
+		}
+	}
+
+	query := tx.Model(&models.Certificate{}).
+		Where("hackathon_id = ? AND kind = ? AND revoked_at IS NULL", hackathonID, models.CertificateWinner)
+	if len(keep) > 0 {
+		query = query.Where("(user_id, subject) NOT IN ?", keep)
+	}
+	result := query.Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// IssueCertificates выдаёт сертификаты по опубликованным результатам. Выданные ранее сертификаты
+// и их коды сохраняются, поэтому повторный вызов только добавляет недостающие. Сертификаты победителей
+// сверяются с текущими результатами: лишние отзываются, подтверждённые снова становятся действующими
+func (cc *CertificateController) IssueCertificates(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	var hackathon models.Hackathon
+	if err := cc.DB.First(&hackathon, hackathonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return
+	}
+	if !hackathon.ResultsPublished() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Сертификаты выдаются после публикации результатов"})
+		return
+	}
+
+	certificates, err := plannedCertificates(cc.DB, hackathon.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при подготовке сертификатов", "details": err.Error()})
+		return
+	}
+	for i := range certificates {
+		if certificates[i].Code, err = models.NewCertificateCode(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании кода сертификата"})
+			return
+		}
+	}
+
+	var result certificateDTO.IssueResult
+	err = cc.DB.Transaction(func(tx *gorm.DB) error {
+		if len(certificates) > 0 {
+			// Уже выданный сертификат того же вида не перезаписывается
+			created := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "hackathon_id"}, {Name: "user_id"}, {Name: "subject"}},
+				DoNothing: true,
+			}).CreateInBatches(&certificates, 500)
+			if created.Error != nil {
+				return created.Error
+			}
+			result.Issued = int(created.RowsAffected)
+		}
+
+		// Сертификат победителя, выданный раньше, получает место из текущих результатов
+		for _, certificate := range certificates {
+			if certificate.Kind != models.CertificateWinner {
+				continue
+			}
+			if err := tx.Model(&models.Certificate{}).
+				Where("hackathon_id = ? AND user_id = ? AND subject = ?", certificate.HackathonID, certificate.UserID, certificate.Subject).
+				Updates(map[string]interface{}{
+					"team_id":    certificate.TeamID,
+					"team_name":  certificate.TeamName,
+					"place":      certificate.Place,
+					"track_name": certificate.TrackName,
+					"revoked_at": nil,
+				}).Error; err != nil {
+				return err
+			}
+		}
+
+		var err error
+		if result.Revoked, err = revokeStaleWinnerCertificates(tx, hackathon.ID, certificates); err != nil {
+			return err
+		}
+		return tx.Model(&models.Certificate{}).Where("hackathon_id = ? AND revoked_at IS NULL", hackathon.ID).Count(&result.Total).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при выдаче сертификатов", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetCertificates возвращает все выданные сертификаты хакатона
+func (cc *CertificateController) GetCertificates(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	cc.respondCertificates(c, cc.DB.Where("certificates.hackathon_id = ?", hackathonID))
+}
+
+// GetMyCertificates возвращает сертификаты текущего пользователя в хакатоне
+func (cc *CertificateController) GetMyCertificates(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	cc.respondCertificates(c, cc.DB.Where("certificates.hackathon_id = ? AND certificates.user_id = ?", hackathonID, userID))
+}
+
+func (cc *CertificateController) respondCertificates(c *gin.Context, query *gorm.DB) {
+	var certificates []models.Certificate
+	if err := query.Joins("User").Order("certificates.kind, certificates.place, \"User\".username").Find(&certificates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении сертификатов"})
+		return
+	}
+
+	result := make([]certificateDTO.Get, 0, len(certificates))
+	for _, certificate := range certificates {
+		result = append(result, toCertificateDTO(certificate))
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetCertificatePDF формирует PDF сертификата. Скачать его может владелец или организатор
+func (cc *CertificateController) GetCertificatePDF(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	var certificate models.Certificate
+	if err := cc.DB.Preload("User").Preload("Hackathon").
+		Where("hackathon_id = ? AND code = ?", hackathonID, strings.ToUpper(c.Param("code"))).
+		First(&certificate).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Сертификат не найден"})
+		return
+	}
+
+	if certificate.RevokedAt != nil {
+		c.JSON(http.StatusGone, gin.H{"error": "Сертификат отозван после исправления результатов"})
+		return
+	}
+
+	if certificate.UserID != userID {
+		var member models.BndUserHackathon
+		if err := cc.DB.Where("user_id = ? AND hackathon_id = ?", userID, hackathonID).First(&member).Error; err != nil || member.HackathonRole < 3 {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Сертификат принадлежит другому пользователю"})
+			return
+		}
+	}
+
+	var template models.CertificateTemplate
+	if err := cc.DB.Preload("Background").Preload("Font").Where("hackathon_id = ?", hackathonID).First(&template).Error; err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Организатор ещё не загрузил шаблон сертификатов"})
+		return
+	}
+
+	document, err := cc.renderCertificate(template, certificate, certificateVerifyURL(c, certificate.Code))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при формировании сертификата", "details": err.Error()})
+		return
+	}
+
+	var buf bytes.Buffer
+	if _, err := document.WriteTo(&buf); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при формировании сертификата", "details": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=certificate-%s.pdf", certificate.Code))
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+// renderCertificate выводит строки шаблона, относящиеся к виду сертификата, с подстановкой данных
+func (cc *CertificateController) renderCertificate(template models.CertificateTemplate, certificate models.Certificate, verifyURL string) (*pdf.Document, error) {
+	var fields []models.CertificateField
+	if err := json.Unmarshal([]byte(template.Fields), &fields); err != nil {
+		return nil, err
+	}
+
+	if template.Font == nil {
+		return nil, errors.New("файл шрифта шаблона удалён")
+	}
+	fontData, err := cc.FileController.readFile(*template.Font)
+	if err != nil {
+		return nil, err
+	}
+	font, err := pdf.LoadTrueType(fontData)
+	if err != nil {
+		return nil, err
+	}
+
+	document := pdf.New(template.PageWidth, template.PageHeight)
+	document.SetFont(font)
+
+	if template.Background != nil {
+		backgroundData, err := cc.FileController.readFile(*template.Background)
+		if err != nil {
+			return nil, err
+		}
+		background, err := pdf.LoadImage(backgroundData)
+		if err != nil {
+			return nil, err
+		}
+		document.SetBackground(background)
+	}
+
+	place := ""
+	if certificate.Place > 0 {
+		place = strconv.Itoa(certificate.Place)
+	}
+	replacer := strings.NewReplacer(
+		"{name}", certificate.User.Username,
+		"{hackathon}", certificate.Hackathon.Name,
+		"{kind}", models.CertificateKindNames[certificate.Kind],
+		"{team}", certificate.TeamName,
+		"{place}", place,
+		"{track}", certificate.TrackName,
+		"{date}", certificate.CreatedAt.Format("02.01.2006"),
+		"{code}", certificate.Code,
+		"{verify_url}", verifyURL,
+	)
+
+	for _, field := range fields {
+		if len(field.Kinds) > 0 && !slices.Contains(field.Kinds, certificate.Kind) {
+			continue
+		}
+		text := strings.TrimSpace(replacer.Replace(field.Text))
+		if text == "" {
+			continue
+		}
+		// В шаблоне координаты отсчитываются от верхнего края, в PDF - от нижнего
+		if err := document.Text(field.X, template.PageHeight-field.Y, field.Size, parseColor(field.Color), field.Align, text); err != nil {
+			return nil, err
+		}
+	}
+
+	return document, nil
+}
+
+// parseColor переводит цвет #RRGGBB в компоненты 0..1; по умолчанию - чёрный
+func parseColor(color string) [3]float64 {
+	var rgb [3]float64
+	value, err := strconv.ParseUint(strings.TrimPrefix(color, "#"), 16, 32)
+	if err != nil || len(color) != 7 {
+		return rgb
+	}
+	for i := range rgb {
+		rgb[i] = float64(value>>(16-8*i)&0xFF) / 255
+	}
+	return rgb
+}
+
+// certificateVerifyURL - адрес публичной проверки сертификата. Базовый адрес задаётся
+// переменной CERTIFICATE_VERIFY_URL, иначе строится по адресу запроса
+func certificateVerifyURL(c *gin.Context, code string) string {
+	base := os.Getenv("CERTIFICATE_VERIFY_URL")
+	if base == "" {
+		scheme := "http"
+		if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+			scheme = "https"
+		}
+		base = scheme + "://" + c.Request.Host + "/api/certificate/verify/"
+	}
+	return strings.TrimRight(base, "/") + "/" + code
+}
+
+// VerifyCertificate - публичная проверка подлинности сертификата по коду
+func (cc *CertificateController) VerifyCertificate(c *gin.Context) {
+	code := strings.ToUpper(strings.TrimSpace(c.Param("code")))
+
+	var certificate models.Certificate
+	if err := cc.DB.Preload("User").Preload("Hackathon").Where("code = ?", code).First(&certificate).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"valid": false, "code": code, "error": "Сертификат с таким кодом не выдавался"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке сертификата"})
+		return
+	}
+
+	c.JSON(http.StatusOK, certificateDTO.Verify{
+		Valid:     certificate.RevokedAt == nil,
+		Code:      certificate.Code,
+		Holder:    certificate.User.Username,
+		Hackathon: certificate.Hackathon.Name,
+		Kind:      certificate.Kind,
+		KindName:  models.CertificateKindNames[certificate.Kind],
+		TeamName:  certificate.TeamName,
+		Place:     certificate.Place,
+		TrackName: certificate.TrackName,
+		IssuedAt:  certificate.CreatedAt,
+		RevokedAt: certificate.RevokedAt,
+	})
+}
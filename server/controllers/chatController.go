@@ -410,14 +410,19 @@ func (cc *ChatController) GetChatMessages(c *gin.Context) {
 
 // Проверка прав доступа к чату
 func (cc *ChatController) checkChatAccess(userID uint, chatID uint, isWriteAccess bool) (bool, error) {
+	return chatAccess(cc.DB, userID, chatID, isWriteAccess)
+}
+
+// chatAccess проверяет права доступа к чату; используется и для вложений сообщений
+func chatAccess(db *gorm.DB, userID uint, chatID uint, isWriteAccess bool) (bool, error) {
 	var chat models.Chat
-	if err := cc.DB.First(&chat, chatID).Error; err != nil {
+	if err := db.First(&chat, chatID).Error; err != nil {
 		return false, err
 	}
 
 	// Проверка наличия пользователя в хакатоне
 	var userHackathon models.BndUserHackathon
-	err := cc.DB.Where("user_id = ? AND hackathon_id = ?", userID, chat.HackathonID).
+	err := db.Where("user_id = ? AND hackathon_id = ?", userID, chat.HackathonID).
 		First(&userHackathon).Error
 
 	if err != nil {
@@ -444,7 +449,7 @@ func (cc *ChatController) checkChatAccess(userID uint, chatID uint, isWriteAcces
 
 		// Проверяем, состоит ли пользователь в этой команде
 		var userTeam models.BndUserTeam
-		teamErr := cc.DB.Where("user_id = ? AND team_id = ?", userID, *chat.TeamID).
+		teamErr := db.Where("user_id = ? AND team_id = ?", userID, *chat.TeamID).
 			First(&userTeam).Error
 
 		return teamErr == nil, nil
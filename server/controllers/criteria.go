@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"server/models"
+	"server/models/DTO/criteriaDTO"
+)
+
+// errCriterionNotFound - при редактировании передан ID критерия, которого у хакатона нет
+var errCriterionNotFound = errors.New("критерий не найден")
+
+// errCriterionInUse - удаляемый критерий уже используется в оценках или правилах подсчёта результатов
+var errCriterionInUse = errors.New("нельзя удалить критерий")
+
+// syncFinalCriteria приводит критерии финальной оценки к переданному списку. Критерии с ID обновляются
+// на месте, чтобы оценки, номинации и правила ничьих продолжали на них ссылаться; критерии без ID
+// создаются; отсутствующие в списке удаляются, если на них ещё ничто не ссылается
+func syncFinalCriteria(tx *gorm.DB, hackathonID uint, criteria []criteriaDTO.Create) error {
+	var existing []models.Criteria
+	if err := tx.Where("hackathon_id = ? AND step_id IS NULL", hackathonID).Find(&existing).Error; err != nil {
+		return err
+	}
+	existingByID := make(map[uint]models.Criteria, len(existing))
+	for _, criterion := range existing {
+		existingByID[criterion.ID] = criterion
+	}
+
+	kept := make(map[uint]bool, len(criteria))
+	for _, criterion := range criteria {
+		if criterion.ID == 0 {
+			if err := tx.Create(criterion.ToModel(hackathonID)).Error; err != nil {
+				return err
+			}
+			continue
+		}
+
+		current, ok := existingByID[criterion.ID]
+		if !ok || kept[criterion.ID] {
+			return fmt.Errorf("%w: %d", errCriterionNotFound, criterion.ID)
+		}
+		kept[criterion.ID] = true
+		if err := tx.Model(&current).Updates(criterion.Updates()).Error; err != nil {
+			return err
+		}
+	}
+
+	removed := make([]uint, 0)
+	for _, criterion := range existing {
+		if kept[criterion.ID] {
+			continue
+		}
+		if err := checkCriterionUnused(tx, criterion); err != nil {
+			return err
+		}
+		removed = append(removed, criterion.ID)
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+	return tx.Delete(&models.Criteria{}, removed).Error
+}
+
+// checkCriterionUnused проверяет, что критерий можно удалить, не потеряв оценки и настройки результатов
+func checkCriterionUnused(tx *gorm.DB, criterion models.Criteria) error {
+	var scores int64
+	if err := tx.Model(&models.Score{}).Where("criteria_id = ?", criterion.ID).Count(&scores).Error; err != nil {
+		return err
+	}
+	if scores > 0 {
+		return fmt.Errorf("%w «%s»: по нему уже выставлены оценки", errCriterionInUse, criterion.Name)
+	}
+
+	var rules int64
+	if err := tx.Model(&models.TieBreaker{}).Where("criteria_id = ?", criterion.ID).Count(&rules).Error; err != nil {
+		return err
+	}
+	if rules > 0 {
+		return fmt.Errorf("%w «%s»: он выбран в правилах разрешения ничьих", errCriterionInUse, criterion.Name)
+	}
+
+	var tracks int64
+	if err := tx.Table("award_track_criteria").Where("criteria_id = ?", criterion.ID).Count(&tracks).Error; err != nil {
+		return err
+	}
+	if tracks > 0 {
+		return fmt.Errorf("%w «%s»: он используется в номинации", errCriterionInUse, criterion.Name)
+	}
+	return nil
+}
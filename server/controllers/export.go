@@ -0,0 +1,363 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"server/models"
+	"server/reports"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Отчёты, которые можно выгрузить
+const (
+	reportLeaderboard = "leaderboard"
+	reportScores      = "scores"
+	reportRoster      = "roster"
+	reportAwards      = "awards"
+	reportAll         = "all"
+)
+
+// exportSheets - отчёты в порядке листов при выгрузке всех отчётов одним файлом
+var exportSheets = []string{reportLeaderboard, reportScores, reportRoster, reportAwards}
+
+var exportSheetNames = map[string]string{
+	reportLeaderboard: "Рейтинг",
+	reportScores:      "Оценки судей",
+	reportRoster:      "Участники",
+	reportAwards:      "Награды",
+}
+
+// exportCriterion - колонка критерия в отчётах; критерии туров подписываются названием тура
+type exportCriterion struct {
+	models.Criteria
+	Title string
+}
+
+// exportCriteria возвращает критерии финальной оценки, затем критерии отборочных туров
+func exportCriteria(db *gorm.DB, hackathonID uint) ([]exportCriterion, []exportCriterion, error) {
+	criteria, err := mainCriteria(db, hackathonID)
+	if err != nil {
+		return nil, nil, err
+	}
+	rounds, err := loadRounds(db, hackathonID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	final := make([]exportCriterion, 0, len(criteria))
+	for _, criterion := range criteria {
+		final = append(final, exportCriterion{Criteria: criterion, Title: criterion.Name})
+	}
+	all := append([]exportCriterion{}, final...)
+	for _, round := range rounds {
+		for _, criterion := range round.Criteria {
+			all = append(all, exportCriterion{Criteria: criterion, Title: round.Name + ": " + criterion.Name})
+		}
+	}
+	return final, all, nil
+}
+
+// ExportReport выгружает рейтинг, оценки судей, состав команд и награды хакатона в CSV, XLSX или JSON.
+// CSV содержит одну таблицу, поэтому отчёт all доступен только в XLSX и JSON
+func (hc *HackathonController) ExportReport(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	report := c.Param("report")
+	sheets := []string{report}
+	if report == reportAll {
+		sheets = exportSheets
+	} else if _, ok := exportSheetNames[report]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неизвестный отчёт", "reports": append(exportSheets, reportAll)})
+		return
+	}
+
+	format := c.DefaultQuery("format", reports.FormatXLSX)
+	if format != reports.FormatCSV && format != reports.FormatXLSX && format != reports.FormatJSON {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Формат отчёта должен быть csv, xlsx или json"})
+		return
+	}
+	if len(sheets) > 1 && !reports.MultiSheet(format) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "В CSV выгружается только один отчёт, выберите xlsx или json"})
+		return
+	}
+
+	var hackathon models.Hackathon
+	if err := hc.DB.First(&hackathon, hackathonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return
+	}
+
+	final, all, err := exportCriteria(hc.DB, hackathon.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении критериев оценки", "details": err.Error()})
+		return
+	}
+
+	// Рейтинг считается до начала выгрузки: после отправки заголовков ошибку уже не вернуть
+	var leaderboard []resultRow
+	if slices.Contains(sheets, reportLeaderboard) {
+		if leaderboard, err = exportLeaderboardRows(hc.DB, hackathon, final); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при подсчёте результатов", "details": err.Error()})
+			return
+		}
+	}
+
+	writer, err := reports.NewWriter(format, c.Writer)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("hackathon-%d-%s-%s.%s", hackathon.ID, report, time.Now().Format("20060102"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	c.Header("Content-Type", reports.ContentType(format))
+	c.Status(http.StatusOK)
+
+	for _, sheet := range sheets {
+		switch sheet {
+		case reportLeaderboard:
+			err = exportLeaderboard(writer, hackathon, final, leaderboard)
+		case reportScores:
+			err = exportScores(hc.DB, writer, hackathon.ID, all)
+		case reportRoster:
+			err = exportRoster(hc.DB, writer, hackathon.ID)
+		case reportAwards:
+			err = exportAwards(hc.DB, writer, hackathon.ID)
+		}
+		if err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		// Заголовки уже отправлены, поэтому выгрузку можно только оборвать
+		log.Printf("Ошибка при выгрузке отчёта %s хакатона %d: %v", report, hackathon.ID, err)
+		c.Abort()
+	}
+}
+
+// exportLeaderboardRows возвращает опубликованный рейтинг, а до публикации - текущий
+func exportLeaderboardRows(db *gorm.DB, hackathon models.Hackathon, criteria []exportCriterion) ([]resultRow, error) {
+	if hackathon.ResultsPublished() {
+		list, _, err := loadPublishedResults(db, hackathon.ID)
+		return list, err
+	}
+
+	plain := make([]models.Criteria, len(criteria))
+	for i, criterion := range criteria {
+		plain[i] = criterion.Criteria
+	}
+	table, _, err := computeResults(db, hackathon, plain, hackathonFormula(hackathon, plain))
+	return table.List, err
+}
+
+// exportLeaderboard пишет рейтинг с баллами и комментариями по каждому критерию
+func exportLeaderboard(writer reports.Writer, hackathon models.Hackathon, criteria []exportCriterion, list []resultRow) error {
+	header := []string{"Место", "Команда", "Итоговый балл"}
+	if hackathon.VotingMode != models.VotingOff {
+		header = append(header, "Голоса зрителей")
+	}
+	header = append(header, "Приз", "Приз: дополнительно")
+	for _, criterion := range criteria {
+		header = append(header, criterion.Title, criterion.Title+": комментарии")
+	}
+	if err := writer.Sheet(exportSheetNames[reportLeaderboard], header); err != nil {
+		return err
+	}
+
+	for _, row := range list {
+		values := []any{row.Place, row.TeamName, row.Score}
+		if hackathon.VotingMode != models.VotingOff {
+			var votes any
+			if row.Votes != nil {
+				votes = *row.Votes
+			}
+			values = append(values, votes)
+		}
+		if row.Award != nil {
+			values = append(values, row.Award.MoneyAmount, row.Award.Additionally)
+		} else {
+			values = append(values, nil, nil)
+		}
+
+		// В строке результата есть только критерии, по которым команду оценивали
+		byName := make(map[string]resultCriteriaScore, len(row.Criteria))
+		for _, score := range row.Criteria {
+			byName[score.Name] = score
+		}
+		for _, criterion := range criteria {
+			score, ok := byName[criterion.Name]
+			if !ok {
+				values = append(values, nil, nil)
+				continue
+			}
+			values = append(values, score.Score, strings.Join(score.Comments, "\n"))
+		}
+
+		if err := writer.Row(values...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportScores пишет исходные оценки: строка на пару команда-судья, колонка на критерий.
+// Оценки читаются курсором и выводятся по мере чтения
+func exportScores(db *gorm.DB, writer reports.Writer, hackathonID uint, criteria []exportCriterion) error {
+	header := []string{"Команда", "Судья"}
+	column := make(map[uint]int, len(criteria))
+	criteriaIDs := make([]uint, len(criteria))
+	for i, criterion := range criteria {
+		header = append(header, criterion.Title)
+		column[criterion.ID] = i
+		criteriaIDs[i] = criterion.ID
+	}
+	if err := writer.Sheet(exportSheetNames[reportScores], header); err != nil {
+		return err
+	}
+	if len(criteria) == 0 {
+		return nil
+	}
+
+	rows, err := db.Model(&models.Score{}).
+		Select("scores.team_id, teams.name AS team_name, scores.user_id, users.username, scores.criteria_id, scores.score").
+		Joins("JOIN teams ON teams.id = scores.team_id AND teams.deleted_at IS NULL").
+		Joins("JOIN users ON users.id = scores.user_id").
+		Where("teams.hackathon_id = ? AND scores.criteria_id IN ?", hackathonID, criteriaIDs).
+		Order("teams.name, scores.team_id, users.username, scores.user_id").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var current struct{ team, judge uint }
+	var line []any
+	flush := func() error {
+		if line == nil {
+			return nil
+		}
+		return writer.Row(line...)
+	}
+
+	for rows.Next() {
+		var score struct {
+			TeamID     uint
+			TeamName   string
+			UserID     uint
+			Username   string
+			CriteriaID uint
+			Score      float64
+		}
+		if err := db.ScanRows(rows, &score); err != nil {
+			return err
+		}
+
+		if line == nil || current.team != score.TeamID || current.judge != score.UserID {
+			if err := flush(); err != nil {
+				return err
+			}
+			current.team, current.judge = score.TeamID, score.UserID
+			line = make([]any, 2+len(criteria))
+			line[0], line[1] = score.TeamName, score.Username
+		}
+		line[2+column[score.CriteriaID]] = score.Score
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// exportRoster пишет состав команд с контактами участников
+func exportRoster(db *gorm.DB, writer reports.Writer, hackathonID uint) error {
+	header := []string{"Команда", "Участник", "Email", "Роль в команде", "В команде с"}
+	if err := writer.Sheet(exportSheetNames[reportRoster], header); err != nil {
+		return err
+	}
+
+	rows, err := db.Model(&models.BndUserTeam{}).
+		Select("teams.name AS team_name, users.username, users.email, bnd_user_teams.team_role, bnd_user_teams.created_at").
+		Joins("JOIN teams ON teams.id = bnd_user_teams.team_id AND teams.deleted_at IS NULL").
+		Joins("JOIN users ON users.id = bnd_user_teams.user_id").
+		Where("teams.hackathon_id = ?", hackathonID).
+		Order("teams.name, bnd_user_teams.created_at").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var member struct {
+			TeamName  string
+			Username  string
+			Email     string
+			TeamRole  int
+			CreatedAt time.Time
+		}
+		if err := db.ScanRows(rows, &member); err != nil {
+			return err
+		}
+		if err := writer.Row(member.TeamName, member.Username, member.Email, models.TeamRoleNames[member.TeamRole], member.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// exportAwards пишет награды общего рейтинга и номинаций с победителями после публикации
+func exportAwards(db *gorm.DB, writer reports.Writer, hackathonID uint) error {
+	header := []string{"Номинация", "Места", "Сумма", "Дополнительно", "Победители"}
+	if err := writer.Sheet(exportSheetNames[reportAwards], header); err != nil {
+		return err
+	}
+
+	var tracks []models.AwardTrack
+	if err := db.Where("hackathon_id = ?", hackathonID).Find(&tracks).Error; err != nil {
+		return err
+	}
+	trackNames := make(map[uint]string, len(tracks))
+	for _, track := range tracks {
+		trackNames[track.ID] = track.Name
+	}
+
+	var awards []models.Award
+	if err := db.Preload("Teams", func(db *gorm.DB) *gorm.DB { return db.Order("name") }).
+		Where("hackathon_id = ?", hackathonID).
+		Order("track_id NULLS FIRST, place_from").
+		Find(&awards).Error; err != nil {
+		return err
+	}
+
+	for _, award := range awards {
+		track := "Общий рейтинг"
+		if award.TrackID != nil {
+			track = trackNames[*award.TrackID]
+		}
+		places := strconv.Itoa(award.PlaceFrom)
+		if award.PlaceTo != award.PlaceFrom {
+			places += "–" + strconv.Itoa(award.PlaceTo)
+		}
+		winners := make([]string, len(award.Teams))
+		for i, team := range award.Teams {
+			winners[i] = team.Name
+		}
+		if err := writer.Row(track, places, award.MoneyAmount, award.Additionally, strings.Join(winners, ", ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"server/models"
+	"server/types"
+	"time"
+)
+
+// Сколько действует подписанная ссылка на публичный файл
+const publicFileURLLifetime = 15 * time.Minute
+
+// hackathonRoleOf возвращает роль пользователя в хакатоне; 0 - не участвует
+func hackathonRoleOf(db *gorm.DB, userID, hackathonID uint) (int, error) {
+	var member models.BndUserHackathon
+	err := db.Where("user_id = ? AND hackathon_id = ?", userID, hackathonID).First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return member.HackathonRole, nil
+}
+
+func isTeamMember(db *gorm.DB, userID, teamID uint) (bool, error) {
+	var count int64
+	if err := db.Model(&models.BndUserTeam{}).Where("user_id = ? AND team_id = ?", userID, teamID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// canAccessFile проверяет, может ли пользователь скачать файл. Правила зависят от владельца файла:
+// материалы хакатона видны вместе с хакатоном, проекты - команде, её судьям и организаторам,
+// скриншоты галереи после публикации - всем, вложения сообщений - тем, кто читает чат
+func canAccessFile(db *gorm.DB, file models.File, claims *types.Claims) (bool, error) {
+	userID := claims.UserID
+	if file.UploadedByID == userID {
+		return true, nil
+	}
+
+	switch file.OwnerType {
+	case "hackathon_logo", "hackathon", "hackathon_file":
+		var hackathon models.Hackathon
+		if err := db.Select("id", "status").First(&hackathon, file.OwnerID).Error; err != nil {
+			return false, ignoreNotFound(err)
+		}
+		if hackathon.Status == models.HackathonStatusPublished || claims.SystemRole >= 2 {
+			return true, nil
+		}
+		// Неопубликованный хакатон видят только организаторы и менторы
+		role, err := hackathonRoleOf(db, userID, hackathon.ID)
+		return role >= 2, err
+
+	case "team", "submission":
+		var team models.Team
+		if err := db.Select("id", "hackathon_id").First(&team, file.OwnerID).Error; err != nil {
+			return false, ignoreNotFound(err)
+		}
+		return canAccessTeamFiles(db, team.ID, team.HackathonID, userID)
+
+	case "gallery_screenshot":
+		var entry models.GalleryEntry
+		if err := db.First(&entry, file.OwnerID).Error; err != nil {
+			return false, ignoreNotFound(err)
+		}
+		if entry.Visible() {
+			var hackathon models.Hackathon
+			if err := db.First(&hackathon, entry.HackathonID).Error; err != nil {
+				return false, ignoreNotFound(err)
+			}
+			if hackathon.GalleryOpen() {
+				return true, nil
+			}
+		}
+		return canAccessTeamFiles(db, entry.TeamID, entry.HackathonID, userID)
+
+	case "user":
+		// Аватары видны всем пользователям
+		return true, nil
+
+	case "chat_message":
+		var message models.ChatMessage
+		if err := db.Select("id", "chat_id").First(&message, file.OwnerID).Error; err != nil {
+			return false, ignoreNotFound(err)
+		}
+		return chatAccess(db, userID, message.ChatID, false)
+
+	case "application_answer":
+		var application models.Application
+		if err := db.Joins("JOIN application_answers ON application_answers.application_id = applications.id").
+			Where("application_answers.id = ?", file.OwnerID).First(&application).Error; err != nil {
+			return false, ignoreNotFound(err)
+		}
+		if application.UserID == userID {
+			return true, nil
+		}
+		role, err := hackathonRoleOf(db, userID, application.HackathonID)
+		return role >= 3, err
+
+	case "certificate_font", "certificate_background":
+		role, err := hackathonRoleOf(db, userID, file.OwnerID)
+		return role >= 3, err
+	}
+
+	return false, nil
+}
+
+// canAccessTeamFiles - файлы команды доступны её участникам и организаторам хакатона, а судьям -
+// так же, как оценка: при распределении только назначенным и без конфликта интересов
+func canAccessTeamFiles(db *gorm.DB, teamID, hackathonID, userID uint) (bool, error) {
+	member, err := isTeamMember(db, userID, teamID)
+	if err != nil || member {
+		return member, err
+	}
+	role, err := hackathonRoleOf(db, userID, hackathonID)
+	if err != nil || role != 2 {
+		return role >= 3, err
+	}
+	access, err := loadJudgeAccess(db, hackathonID)
+	if err != nil {
+		return false, err
+	}
+	return access.check(teamID, userID) == "", nil
+}
+
+// ignoreNotFound - у файла без владельца нет и правил доступа: такой файл никому не отдаётся
+func ignoreNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
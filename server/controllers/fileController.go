@@ -1,35 +1,51 @@
 package controllers
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"io"
+	"log"
 	"mime/multipart"
 	"net/http"
 	"os"
 	"path/filepath"
+	"server/initializers"
 	"server/models"
+	"server/staging"
+	"server/storage"
+	"server/types"
 	"strings"
+	"time"
 )
 
 type FileController struct {
-	DB     *gorm.DB
-	Config struct {
-		StoragePath string // Путь к директории для хранения файлов
-	}
+	DB      *gorm.DB
+	Storage storage.Storage // Хранилище содержимого файлов
+	Staging *staging.Dir    // Данные незавершённых возобновляемых загрузок
 }
 
 func NewFileController(db *gorm.DB) *FileController {
-	fc := &FileController{
-		DB: db,
+	return &FileController{
+		DB:      db,
+		Storage: initializers.Storage,
+		Staging: initializers.Staging,
 	}
-	fc.Config.StoragePath = "uploads"
+}
 
-	return fc
+// fileURL - адрес скачивания файла через API
+func fileURL(fileID uint) string {
+	return fmt.Sprintf("/file/%d", fileID)
 }
 
+// Наибольший размер загружаемого файла
+const maxFileSize = 1 * 1024 * 1024 * 1024 // 1GB в байтах
+
 // UploadFile - метод для безопасной загрузки файла
 func (fc *FileController) UploadFile(c *gin.Context, file *multipart.FileHeader, ownerID uint, ownerType string) (*models.File, error) {
 	// Получаем текущего пользователя
@@ -38,73 +54,145 @@ func (fc *FileController) UploadFile(c *gin.Context, file *multipart.FileHeader,
 		return nil, errors.New("требуется авторизация")
 	}
 
-	// Проверка максимального размера файла (например, 10MB)
-	const maxFileSize = 1 * 1024 * 1024 * 1024 // 10MB в байтах
+	// Проверка максимального размера файла
 	if file.Size > maxFileSize {
 		return nil, errors.New("размер файла превышает допустимый предел")
 	}
 
-	// Создаем директорию если её нет
-	if err := os.MkdirAll(fc.Config.StoragePath, os.ModePerm); err != nil {
-		return nil, fmt.Errorf("ошибка создания директории: %w", err)
+	// Тип определяется по содержимому: заголовку Content-Type от клиента доверять нельзя
+	fileType := detectFileType(file, filepath.Ext(file.Filename))
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения файла: %w", err)
 	}
+	defer src.Close()
 
-	// Генерируем уникальное имя для файла
-	fileExt := filepath.Ext(file.Filename)
-	fileUUID := uuid.New().String()
-	storedName := fileUUID + fileExt
-	filePath := filepath.Join(fc.Config.StoragePath, storedName)
+	return fc.storeFile(c.Request.Context(), src, file.Size, "", file.Filename, fileType, userID.(uint), ownerID, ownerType)
+}
 
-	// Сохраняем файл
-	if err := c.SaveUploadedFile(file, filePath); err != nil {
-		return nil, fmt.Errorf("ошибка сохранения файла: %w", err)
+// FinalizeUpload переносит завершённую возобновляемую загрузку в хранилище и создаёт запись о файле.
+// Сама загрузка не удаляется: вызывающий удаляет её через DiscardUpload, когда файл окончательно принят
+func (fc *FileController) FinalizeUpload(ctx context.Context, session models.UploadSession, ownerID uint, ownerType string) (*models.File, error) {
+	if !session.Complete() {
+		return nil, errors.New("загрузка не завершена")
 	}
 
-	// Определяем тип файла более надежным способом
-	var fileType string
+	src, err := fc.Staging.Open(session.Token)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения загрузки: %w", err)
+	}
+	defer src.Close()
 
-	// Сначала пробуем получить из заголовка
-	fileType = file.Header.Get("Content-Type")
+	head := make([]byte, 512)
+	n, _ := src.ReadAt(head, 0)
+	fileType := detectContentType(head[:n], filepath.Ext(session.Name))
 
-	// Если тип не определен или generic, определяем его по расширению
-	if fileType == "" || fileType == "application/octet-stream" {
-		fileType = getFileTypeByExtension(fileExt)
+	return fc.storeFile(ctx, src, session.Size, session.SHA256, session.Name, fileType, session.UserID, ownerID, ownerType)
+}
+
+// DiscardUpload удаляет возобновляемую загрузку вместе с её данными
+func (fc *FileController) DiscardUpload(session models.UploadSession) error {
+	if err := fc.Staging.Remove(session.Token); err != nil {
+		return err
 	}
+	return fc.DB.Unscoped().Delete(&session).Error
+}
 
-	// Если все еще не определен, пробуем определить по содержимому
-	if fileType == "" || fileType == "application/octet-stream" {
-		openedFile, err := file.Open()
-		if err == nil {
-			defer openedFile.Close()
-			buffer := make([]byte, 512) // Используем первые 512 байт для определения типа
-			if _, err := openedFile.Read(buffer); err == nil {
-				fileType = http.DetectContentType(buffer)
-			}
+// storeFile сохраняет содержимое в хранилище и создаёт запись о файле. Объект в хранилище адресуется
+// SHA-256 содержимого, поэтому повторно загруженный файл не занимает места. sum - уже известная
+// контрольная сумма; если она пуста, то вычисляется по src
+func (fc *FileController) storeFile(ctx context.Context, src io.ReadSeeker, size int64, sum, name, fileType string, uploadedByID, ownerID uint, ownerType string) (*models.File, error) {
+	if sum == "" {
+		var err error
+		if sum, err = contentSHA256(src); err != nil {
+			return nil, fmt.Errorf("ошибка чтения файла: %w", err)
 		}
 	}
 
-	// Создаем URL с учетом сервера и путей
-	fileURL := fmt.Sprintf("%s/api/files/%s%s", fc.Config.StoragePath, fileUUID, fileExt)
-
-	// Создаем запись о файле в БД
 	fileRecord := models.File{
-		Name:         file.Filename,
-		StoredName:   storedName,
-		URL:          fileURL,
-		Size:         file.Size,
+		Name:         name,
+		Size:         size,
+		SHA256:       sum,
 		Type:         fileType,
 		OwnerType:    ownerType,
 		OwnerID:      ownerID,
-		UploadedByID: userID.(uint),
+		UploadedByID: uploadedByID,
+	}
+
+	// Объект, найденный при проверке, мог быть удалён до записи в БД: тогда он сохраняется заново
+	for force := false; ; force = true {
+		stored, err := fc.putBlob(ctx, src, size, sum, fileType, force)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сохранения файла: %w", err)
+		}
+
+		// Создаем запись о файле в БД; адрес скачивания известен после получения ID
+		err = fc.DB.Transaction(func(tx *gorm.DB) error {
+			blob, err := acquireBlob(tx, sum, size, stored)
+			if err != nil {
+				return err
+			}
+			fileRecord.StoredName = blob.StoredName
+			if err := tx.Create(&fileRecord).Error; err != nil {
+				return err
+			}
+			fileRecord.URL = fileURL(fileRecord.ID)
+			return tx.Model(&fileRecord).Update("url", fileRecord.URL).Error
+		})
+		if errors.Is(err, errBlobGone) && !force {
+			continue
+		}
+		if err != nil {
+			// Записанный объект остаётся без ссылок: его удалит сборка мусора, если он никому не понадобится.
+			// Удалить его сразу нельзя - на него уже могла сослаться параллельная загрузка того же содержимого
+			if stored {
+				fc.DB.Clauses(clause.OnConflict{DoNothing: true}).
+					Create(&models.Blob{SHA256: sum, StoredName: models.BlobKey(sum), Size: size})
+			}
+			return nil, fmt.Errorf("ошибка создания записи в БД: %w", err)
+		}
+		return &fileRecord, nil
+	}
+}
+
+// Типы, которые по содержимому не отличить от более общего: документы Office - это zip-архивы
+// или составные файлы, которые распознаются как двоичные данные
+var refinedFileTypes = map[string][]string{
+	"application/zip": {
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	},
+	"application/octet-stream": {"application/msword", "application/vnd.ms-excel"},
+}
+
+// detectFileType определяет тип файла по первым 512 байтам. Расширение уточняет тип,
+// только если не противоречит содержимому
+func detectFileType(file *multipart.FileHeader, ext string) string {
+	var head []byte
+	if openedFile, err := file.Open(); err == nil {
+		defer openedFile.Close()
+		buffer := make([]byte, 512)
+		n, _ := openedFile.Read(buffer)
+		head = buffer[:n]
 	}
+	return detectContentType(head, ext)
+}
 
-	if err := fc.DB.Create(&fileRecord).Error; err != nil {
-		// Удаляем файл при ошибке записи в БД
-		os.Remove(filePath)
-		return nil, fmt.Errorf("ошибка создания записи в БД: %w", err)
+// detectContentType - то же по уже прочитанному началу файла
+func detectContentType(head []byte, ext string) string {
+	fileType := "application/octet-stream"
+	if len(head) > 0 {
+		fileType = http.DetectContentType(head)
 	}
 
-	return &fileRecord, nil
+	byExtension := getFileTypeByExtension(ext)
+	for _, refined := range refinedFileTypes[fileType] {
+		if refined == byExtension {
+			return byExtension
+		}
+	}
+	return fileType
 }
 
 // Функция для определения типа файла по расширению
@@ -148,57 +236,171 @@ func (fc *FileController) GetFile(c *gin.Context) {
 		return
 	}
 
-	// Проверяем права доступа (упрощенно)
-	// В реальном приложении здесь должна быть более сложная логика проверки прав
-	//userID, exists := c.Get("userID")
-	//if !exists {
-	//	c.JSON(http.StatusUnauthorized, gin.H{"error": "Необходима авторизация"})
-	//	return
-	//}
-	//
-	//hasAccess := false
-
-	// Пользователь, загрузивший файл, всегда имеет доступ
-	//if file.UploadedByID == userID.(uint) {
-	//	hasAccess = true
-	//} else {
-	//	// Проверка прав в зависимости от типа владельца
-	//	switch file.OwnerType {
-	//	case "hackathon":
-	//		var count int64
-	//		fc.DB.Model(&models.BndUserHackathon{}).
-	//			Where("user_id = ? AND hackathon_id = ?", userID, file.OwnerID).
-	//			Count(&count)
-	//		hasAccess = count > 0
-	//	case "team":
-	//		var count int64
-	//		fc.DB.Model(&models.BndUserTeam{}).
-	//			Where("user_id = ? AND team_id = ?", userID, file.OwnerID).
-	//			Count(&count)
-	//		hasAccess = count > 0
-	//	case "user":
-	//		hasAccess = file.OwnerID == userID.(uint)
-	//	}
-	//}
-	//
-	//if !hasAccess {
-	//	c.JSON(http.StatusForbidden, gin.H{"error": "Нет доступа к файлу"})
-	//	return
-	//}
-
-	// Формируем путь к файлу
-	filePath := filepath.Join(fc.Config.StoragePath, file.StoredName)
-
-	// Проверяем существование файла
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+	if !fc.authorizeDownload(c, file) {
+		return
+	}
+
+	fc.serveFile(c, file, file.Name)
+}
+
+// authorizeDownload проверяет право текущего пользователя на файл и при отказе отвечает ошибкой
+func (fc *FileController) authorizeDownload(c *gin.Context, file models.File) bool {
+	claims := c.MustGet("user_claims").(*types.Claims)
+	allowed, err := canAccessFile(fc.DB, file, claims)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке доступа к файлу"})
+		return false
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Нет доступа к файлу"})
+		return false
+	}
+
+	// При слепой оценке судьи получают файлы команд только по обезличенной ссылке
+	hidden, err := blindTeamFile(fc.DB, file, claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке доступа к файлу"})
+		return false
+	}
+	if hidden {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Во время слепой оценки файлы команд доступны судьям только по обезличенной ссылке"})
+		return false
+	}
+	return true
+}
+
+// GetFileURL выдаёт короткоживущую подписанную ссылку на файл, например для тега img.
+// Права проверяются так же, как при скачивании
+func (fc *FileController) GetFileURL(c *gin.Context) {
+	var file models.File
+	if err := fc.DB.Where("id = ?", c.Param("file_id")).First(&file).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Файл не найден"})
+		return
+	}
+
+	if !fc.authorizeDownload(c, file) {
+		return
+	}
+
+	url := fc.publicURL(c.Request.Context(), file)
+	if url == "" {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при подписи ссылки"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"url": url, "expiresAt": time.Now().Add(publicFileURLLifetime)})
+}
+
+// publicURL подписывает ссылку на файл, доступную без авторизации publicFileURLLifetime.
+// Ссылки выдаются только на файлы, права на которые уже проверены; при ошибке возвращается пустая строка
+func (fc *FileController) publicURL(ctx context.Context, file models.File) string {
+	url, err := fc.Storage.Presign(ctx, file.StoredName, publicFileURLLifetime, file.Name)
+	if err != nil {
+		log.Printf("Не удалось подписать ссылку на файл %d: %v", file.ID, err)
+		return ""
+	}
+	return url
+}
+
+// readFile читает содержимое сохранённого файла целиком
+func (fc *FileController) readFile(file models.File) ([]byte, error) {
+	reader, _, err := fc.Storage.Get(context.Background(), file.StoredName)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// tempFile копирует сохранённый файл во временный, чтобы читать большие архивы, не держа их в памяти.
+// Вызывающий закрывает файл и удаляет его
+func (fc *FileController) tempFile(file models.File) (*os.File, int64, error) {
+	reader, _, err := fc.Storage.Get(context.Background(), file.StoredName)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer reader.Close()
+
+	temp, err := os.CreateTemp("", "file-*")
+	if err != nil {
+		return nil, 0, err
+	}
+	size, err := io.Copy(temp, reader)
+	if err != nil {
+		temp.Close()
+		os.Remove(temp.Name())
+		return nil, 0, err
+	}
+	return temp, size, nil
+}
+
+// serveFile отдаёт файл из хранилища под указанным именем
+func (fc *FileController) serveFile(c *gin.Context, file models.File, name string) {
+	headers := map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%s", name),
+	}
+	// Контрольная сумма позволяет проверить скачанный файл и не скачивать его повторно
+	if file.SHA256 != "" {
+		etag := `"` + file.SHA256 + `"`
+		c.Header("ETag", etag)
+		if etagMatches(c.GetHeader("If-None-Match"), etag) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+		if sum, err := hex.DecodeString(file.SHA256); err == nil {
+			encoded := base64.StdEncoding.EncodeToString(sum)
+			headers["Digest"] = "sha-256=" + encoded
+			headers["Repr-Digest"] = "sha-256=:" + encoded + ":"
+		}
+	}
+
+	reader, object, err := fc.Storage.Get(c.Request.Context(), file.StoredName)
+	if errors.Is(err, storage.ErrNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Физический файл не найден"})
 		return
 	}
+	if err != nil {
+		log.Printf("Ошибка чтения файла %s из хранилища: %v", file.StoredName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при чтении файла"})
+		return
+	}
+	defer reader.Close()
 
-	// Устанавливаем заголовки для скачивания
-	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", file.Name))
-	c.Header("Content-Type", file.Type)
+	// Отправляем файл с заголовками для скачивания
+	c.DataFromReader(http.StatusOK, object.Size, file.Type, reader, headers)
+}
 
-	// Отправляем файл
-	c.File(filePath)
+// etagMatches проверяет заголовок If-None-Match: список меток через запятую или "*"
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// GetStorageObject отдаёт файл локального хранилища по подписанной ссылке. Для S3 ссылки ведут в само хранилище
+func (fc *FileController) GetStorageObject(c *gin.Context) {
+	verifier, ok := fc.Storage.(storage.Verifier)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Файл не найден"})
+		return
+	}
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if err := verifier.Verify(key, c.Request.URL.Query()); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Ссылка на файл недействительна или устарела"})
+		return
+	}
+
+	var file models.File
+	if err := fc.DB.Where("stored_name = ?", key).First(&file).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Файл не найден"})
+		return
+	}
+	name := c.Query("name")
+	if name == "" {
+		name = file.Name
+	}
+	fc.serveFile(c, file, name)
 }
@@ -0,0 +1,628 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"mime/multipart"
+	"net/http"
+	"server/models"
+	"server/models/DTO/galleryDTO"
+	"server/types"
+	"strconv"
+	"strings"
+)
+
+type GalleryController struct {
+	DB             *gorm.DB
+	FileController *FileController
+}
+
+func NewGalleryController(db *gorm.DB, fileController *FileController) *GalleryController {
+	return &GalleryController{
+		DB:             db,
+		FileController: fileController,
+	}
+}
+
+// Сколько скриншотов может быть в карточке проекта
+const galleryMaxScreenshots = 10
+
+func galleryScreenshotURL(hackathonID, teamID, fileID uint) string {
+	return fmt.Sprintf("/gallery/%d/teams/%d/screenshots/%d", hackathonID, teamID, fileID)
+}
+
+func galleryDownloadURL(hackathonID, teamID uint) string {
+	return fmt.Sprintf("/gallery/%d/teams/%d/download", hackathonID, teamID)
+}
+
+// galleryProjectFile возвращает файл итогового проекта: основной файл последней версии сдачи,
+// для команд, сдававших проект до появления версий, - прежний файл
+func galleryProjectFile(entry models.GalleryEntry, submission *models.Submission) *models.File {
+	if submission != nil {
+		return submission.PrimaryFile()
+	}
+	return entry.Team.Project
+}
+
+// buildGalleryEntries собирает карточки: участников, итоговую сдачу и награды из опубликованных результатов.
+// Имена участников без согласия в Members не попадают
+func buildGalleryEntries(db *gorm.DB, hackathonID uint, entries []models.GalleryEntry) ([]galleryDTO.Entry, error) {
+	teamIDs := make([]uint, len(entries))
+	for i, entry := range entries {
+		teamIDs[i] = entry.TeamID
+	}
+
+	submissions, err := finalSubmissions(db, teamIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []models.BndUserTeam
+	if err := db.Preload("User").Where("team_id IN ?", teamIDs).Order("created_at").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	membersByTeam := make(map[uint][]models.BndUserTeam)
+	for _, member := range members {
+		membersByTeam[member.TeamID] = append(membersByTeam[member.TeamID], member)
+	}
+
+	var snapshots []models.ResultSnapshot
+	if err := db.Where("hackathon_id = ? AND team_id IN ?", hackathonID, teamIDs).Order("track_id NULLS FIRST, position").Find(&snapshots).Error; err != nil {
+		return nil, err
+	}
+	var tracks []models.AwardTrack
+	if err := db.Where("hackathon_id = ?", hackathonID).Find(&tracks).Error; err != nil {
+		return nil, err
+	}
+	trackNames := make(map[uint]string, len(tracks))
+	for _, track := range tracks {
+		trackNames[track.ID] = track.Name
+	}
+
+	result := make([]galleryDTO.Entry, 0, len(entries))
+	for _, entry := range entries {
+		item := galleryDTO.Entry{
+			Get: galleryDTO.Get{
+				TeamID:        entry.TeamID,
+				TeamName:      entry.Team.Name,
+				Members:       []string{},
+				Description:   entry.Description,
+				Screenshots:   make([]galleryDTO.Screenshot, 0, len(entry.Screenshots)),
+				RepositoryURL: entry.RepositoryURL,
+				DemoURL:       entry.DemoURL,
+				Awards:        []galleryDTO.Award{},
+			},
+			Published:    entry.Published,
+			Hidden:       entry.Hidden,
+			HiddenReason: entry.HiddenReason,
+			Team:         []galleryDTO.Member{},
+		}
+
+		for _, member := range membersByTeam[entry.TeamID] {
+			if member.GalleryConsent {
+				item.Members = append(item.Members, member.User.Username)
+			}
+			item.Team = append(item.Team, galleryDTO.Member{UserID: member.UserID, Username: member.User.Username, Consent: member.GalleryConsent})
+		}
+
+		for _, screenshot := range entry.Screenshots {
+			item.Screenshots = append(item.Screenshots, galleryDTO.Screenshot{
+				ID:   screenshot.ID,
+				Name: screenshot.Name,
+				URL:  galleryScreenshotURL(hackathonID, entry.TeamID, screenshot.ID),
+			})
+		}
+
+		var submission *models.Submission
+		if final, ok := submissions[entry.TeamID]; ok {
+			submission = &final
+			if item.Description == "" {
+				item.Description = final.Description
+			}
+			for _, artifact := range final.Artifacts {
+				if artifact.Kind == models.ArtifactRepository && item.RepositoryURL == "" {
+					item.RepositoryURL = artifact.URL
+				}
+				if artifact.Kind == models.ArtifactDemoVideo && item.DemoURL == "" {
+					item.DemoURL = artifact.URL
+				}
+			}
+		}
+		if galleryProjectFile(entry, submission) != nil {
+			item.DownloadURL = galleryDownloadURL(hackathonID, entry.TeamID)
+		}
+
+		for _, snapshot := range snapshots {
+			if snapshot.TeamID != entry.TeamID {
+				continue
+			}
+			if snapshot.TrackID == nil {
+				item.Place = snapshot.Place
+			}
+			if snapshot.AwardID != nil {
+				award := galleryDTO.Award{Place: snapshot.Place}
+				if snapshot.TrackID != nil {
+					award.TrackName = trackNames[*snapshot.TrackID]
+				}
+				item.Awards = append(item.Awards, award)
+			}
+		}
+
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// openGallery загружает хакатон и проверяет, что его галерея доступна посетителям
+func (gc *GalleryController) openGallery(c *gin.Context) (models.Hackathon, bool) {
+	var hackathon models.Hackathon
+
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return hackathon, false
+	}
+
+	if err := gc.DB.First(&hackathon, hackathonID).Error; err != nil || hackathon.Status != models.HackathonStatusPublished {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return hackathon, false
+	}
+	if !hackathon.GalleryOpen() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Галерея проектов хакатона не опубликована"})
+		return hackathon, false
+	}
+	return hackathon, true
+}
+
+// visibleEntry находит карточку команды из URL, открытую посетителям
+func (gc *GalleryController) visibleEntry(c *gin.Context, hackathon models.Hackathon) (models.GalleryEntry, bool) {
+	var entry models.GalleryEntry
+
+	teamID, err := strconv.ParseUint(c.Param("team_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID команды"})
+		return entry, false
+	}
+
+	// У расформированной команды Team не загружается, и карточка не показывается
+	if err := gc.DB.Preload("Team.Project").Preload("Screenshots").
+		Where("hackathon_id = ? AND team_id = ?", hackathon.ID, teamID).
+		First(&entry).Error; err != nil || !entry.Visible() || entry.Team.ID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Проект не найден в галерее"})
+		return entry, false
+	}
+	return entry, true
+}
+
+// GetGallery возвращает опубликованные проекты хакатона. Фильтры: search - по названию команды
+// и описанию, track - команды, участвовавшие в номинации, awarded - только призёры
+func (gc *GalleryController) GetGallery(c *gin.Context) {
+	hackathon, ok := gc.openGallery(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	offset = max(offset, 0)
+
+	query := gc.DB.Model(&models.GalleryEntry{}).
+		Joins("JOIN teams ON teams.id = gallery_entries.team_id AND teams.deleted_at IS NULL").
+		Where("gallery_entries.hackathon_id = ? AND gallery_entries.published AND NOT gallery_entries.hidden", hackathon.ID)
+
+	if search := strings.TrimSpace(c.Query("search")); search != "" {
+		pattern := "%" + search + "%"
+		query = query.Where("teams.name ILIKE ? OR gallery_entries.description ILIKE ?", pattern, pattern)
+	}
+	if track := c.Query("track"); track != "" {
+		trackID, err := strconv.ParseUint(track, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID номинации"})
+			return
+		}
+		query = query.Where("EXISTS (SELECT 1 FROM result_snapshots WHERE result_snapshots.team_id = gallery_entries.team_id AND result_snapshots.track_id = ? AND result_snapshots.deleted_at IS NULL)", trackID)
+	}
+	if c.Query("awarded") == "true" {
+		query = query.Where("EXISTS (SELECT 1 FROM result_snapshots WHERE result_snapshots.team_id = gallery_entries.team_id AND result_snapshots.award_id IS NOT NULL AND result_snapshots.deleted_at IS NULL)")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении галереи"})
+		return
+	}
+
+	// Проекты идут в порядке общего рейтинга, команды без места - по названию
+	var entries []models.GalleryEntry
+	if err := query.Preload("Team.Project").Preload("Screenshots").
+		Joins("LEFT JOIN result_snapshots ON result_snapshots.team_id = gallery_entries.team_id AND result_snapshots.track_id IS NULL AND result_snapshots.deleted_at IS NULL").
+		Order("result_snapshots.position NULLS LAST, teams.name").
+		Limit(limit).Offset(offset).
+		Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении галереи"})
+		return
+	}
+
+	items, err := buildGalleryEntries(gc.DB, hackathon.ID, entries)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении галереи", "details": err.Error()})
+		return
+	}
+
+	list := make([]galleryDTO.Get, len(items))
+	for i, item := range items {
+		list[i] = item.Get
+	}
+	c.JSON(http.StatusOK, galleryDTO.ListResponse{List: list, Total: total, Limit: limit, Offset: offset})
+}
+
+// GetGalleryEntry возвращает страницу проекта команды
+func (gc *GalleryController) GetGalleryEntry(c *gin.Context) {
+	hackathon, ok := gc.openGallery(c)
+	if !ok {
+		return
+	}
+	entry, ok := gc.visibleEntry(c, hackathon)
+	if !ok {
+		return
+	}
+
+	items, err := buildGalleryEntries(gc.DB, hackathon.ID, []models.GalleryEntry{entry})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении проекта", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, items[0].Get)
+}
+
+// DownloadGalleryProject отдаёт файл итоговой сдачи проекта
+func (gc *GalleryController) DownloadGalleryProject(c *gin.Context) {
+	hackathon, ok := gc.openGallery(c)
+	if !ok {
+		return
+	}
+	entry, ok := gc.visibleEntry(c, hackathon)
+	if !ok {
+		return
+	}
+
+	submissions, err := finalSubmissions(gc.DB, []uint{entry.TeamID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении проекта"})
+		return
+	}
+	var submission *models.Submission
+	if final, ok := submissions[entry.TeamID]; ok {
+		submission = &final
+	}
+
+	file := galleryProjectFile(entry, submission)
+	if file == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Команда не сдала файл проекта"})
+		return
+	}
+	gc.FileController.serveFile(c, *file, file.Name)
+}
+
+// GetGalleryScreenshot отдаёт скриншот проекта
+func (gc *GalleryController) GetGalleryScreenshot(c *gin.Context) {
+	hackathon, ok := gc.openGallery(c)
+	if !ok {
+		return
+	}
+	entry, ok := gc.visibleEntry(c, hackathon)
+	if !ok {
+		return
+	}
+
+	fileID, err := strconv.ParseUint(c.Param("file_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID файла"})
+		return
+	}
+	for _, screenshot := range entry.Screenshots {
+		if screenshot.ID == uint(fileID) {
+			gc.FileController.serveFile(c, screenshot, screenshot.Name)
+			return
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "Скриншот не найден"})
+}
+
+// teamEntry загружает карточку команды текущего пользователя; если её нет, возвращается новая
+func (gc *GalleryController) teamEntry(c *gin.Context) (models.Hackathon, models.BndUserTeam, models.GalleryEntry, bool) {
+	var hackathon models.Hackathon
+	var link models.BndUserTeam
+	var entry models.GalleryEntry
+
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return hackathon, link, entry, false
+	}
+	if err := gc.DB.First(&hackathon, hackathonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return hackathon, link, entry, false
+	}
+
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+	link, err = userTeamInHackathon(gc.DB, userID, hackathon.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Вы не состоите в команде на этом хакатоне"})
+		return hackathon, link, entry, false
+	}
+
+	err = gc.DB.Preload("Team.Project").Preload("Screenshots").Where("team_id = ?", link.TeamID).First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		entry = models.GalleryEntry{HackathonID: hackathon.ID, TeamID: link.TeamID}
+		err = gc.DB.Preload("Project").First(&entry.Team, link.TeamID).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении карточки проекта"})
+		return hackathon, link, entry, false
+	}
+	return hackathon, link, entry, true
+}
+
+func (gc *GalleryController) respondEntry(c *gin.Context, hackathon models.Hackathon, entry models.GalleryEntry) {
+	items, err := buildGalleryEntries(gc.DB, hackathon.ID, []models.GalleryEntry{entry})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении карточки проекта", "details": err.Error()})
+		return
+	}
+	item := items[0]
+	item.GalleryOpen = hackathon.GalleryOpen()
+	c.JSON(http.StatusOK, item)
+}
+
+// GetTeamGalleryEntry возвращает карточку проекта команды текущего пользователя
+func (gc *GalleryController) GetTeamGalleryEntry(c *gin.Context) {
+	hackathon, _, entry, ok := gc.teamEntry(c)
+	if !ok {
+		return
+	}
+	gc.respondEntry(c, hackathon, entry)
+}
+
+// screenshotFile проверяет по содержимому, что загружено изображение
+func screenshotFile(header *multipart.FileHeader) bool {
+	file, err := header.Open()
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	buffer := make([]byte, 512)
+	n, _ := file.Read(buffer)
+	return strings.HasPrefix(http.DetectContentType(buffer[:n]), "image/")
+}
+
+// UpdateTeamGalleryEntry сохраняет карточку проекта. Менять её может капитан или его заместитель
+func (gc *GalleryController) UpdateTeamGalleryEntry(c *gin.Context) {
+	hackathon, link, entry, ok := gc.teamEntry(c)
+	if !ok {
+		return
+	}
+	if !models.TeamRoleCan(link.TeamRole, models.TeamActionUpload) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Изменять карточку проекта может только капитан или его заместитель"})
+		return
+	}
+
+	if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка при парсинге формы: " + err.Error()})
+		return
+	}
+
+	var dto galleryDTO.EntryUpdate
+	if err := json.Unmarshal([]byte(c.Request.FormValue("data")), &dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка при разборе JSON: " + err.Error()})
+		return
+	}
+	validate := validator.New()
+	if err := validate.Struct(dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка валидации", "details": err.Error()})
+		return
+	}
+
+	keep := make(map[uint]bool, len(entry.Screenshots))
+	for _, screenshot := range entry.Screenshots {
+		keep[screenshot.ID] = dto.KeepScreenshots == nil
+	}
+	for _, id := range dto.KeepScreenshots {
+		if _, ok := keep[id]; ok {
+			keep[id] = true
+		}
+	}
+	kept := 0
+	for _, ok := range keep {
+		if ok {
+			kept++
+		}
+	}
+
+	headers := c.Request.MultipartForm.File["screenshot"]
+	if kept+len(headers) > galleryMaxScreenshots {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("В карточке может быть не больше %d скриншотов", galleryMaxScreenshots)})
+		return
+	}
+	for _, header := range headers {
+		if !screenshotFile(header) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Скриншот «" + header.Filename + "» не является изображением"})
+			return
+		}
+	}
+
+	entry.Published = dto.Published
+	entry.Description = dto.Description
+	entry.RepositoryURL = dto.RepositoryURL
+	entry.DemoURL = dto.DemoURL
+	if err := gc.DB.Omit("Team", "Screenshots").Save(&entry).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении карточки проекта"})
+		return
+	}
+
+	// Скриншоты привязываются к сохранённой карточке
+	c.Set("userID", c.MustGet("user_claims").(*types.Claims).UserID)
+	for _, header := range headers {
+		if _, err := gc.FileController.UploadFile(c, header, entry.ID, "gallery_screenshot"); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при загрузке скриншота", "details": err.Error()})
+			return
+		}
+	}
+	for id, ok := range keep {
+		if !ok {
+			if err := gc.DB.Delete(&models.File{}, id).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении скриншота"})
+				return
+			}
+		}
+	}
+
+	if err := gc.DB.Preload("Team.Project").Preload("Screenshots").First(&entry, entry.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении карточки проекта"})
+		return
+	}
+	gc.respondEntry(c, hackathon, entry)
+}
+
+// SetGalleryConsent сохраняет согласие участника показывать своё имя в галерее
+func (gc *GalleryController) SetGalleryConsent(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	var dto galleryDTO.Consent
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных", "details": err.Error()})
+		return
+	}
+
+	link, err := userTeamInHackathon(gc.DB, userID, uint(hackathonID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Вы не состоите в команде на этом хакатоне"})
+		return
+	}
+
+	if err := gc.DB.Model(&models.BndUserTeam{}).
+		Where("team_id = ? AND user_id = ?", link.TeamID, userID).
+		Update("gallery_consent", dto.Consent).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении согласия"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"consent": dto.Consent})
+}
+
+// SetGallerySettings включает или выключает галерею хакатона
+func (gc *GalleryController) SetGallerySettings(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	var dto galleryDTO.Settings
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных", "details": err.Error()})
+		return
+	}
+
+	result := gc.DB.Model(&models.Hackathon{}).Where("id = ?", hackathonID).Update("gallery_enabled", dto.Enabled)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении настроек галереи"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"enabled": dto.Enabled})
+}
+
+// GetGalleryEntries возвращает организатору все карточки хакатона, включая скрытые и неопубликованные
+func (gc *GalleryController) GetGalleryEntries(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	var hackathon models.Hackathon
+	if err := gc.DB.First(&hackathon, hackathonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return
+	}
+
+	var entries []models.GalleryEntry
+	if err := gc.DB.Preload("Team.Project").Preload("Screenshots").
+		Joins("JOIN teams ON teams.id = gallery_entries.team_id AND teams.deleted_at IS NULL").
+		Where("gallery_entries.hackathon_id = ?", hackathon.ID).
+		Order("teams.name").
+		Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении галереи"})
+		return
+	}
+
+	items, err := buildGalleryEntries(gc.DB, hackathon.ID, entries)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении галереи", "details": err.Error()})
+		return
+	}
+	for i := range items {
+		items[i].GalleryOpen = hackathon.GalleryOpen()
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// HideGalleryEntry скрывает проект из галереи или возвращает его
+func (gc *GalleryController) HideGalleryEntry(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+	teamID, err := strconv.ParseUint(c.Param("team_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID команды"})
+		return
+	}
+
+	var dto galleryDTO.Hide
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных", "details": err.Error()})
+		return
+	}
+	validate := validator.New()
+	if err := validate.Struct(dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка валидации", "details": err.Error()})
+		return
+	}
+	if !dto.Hidden {
+		dto.Reason = ""
+	}
+
+	result := gc.DB.Model(&models.GalleryEntry{}).
+		Where("hackathon_id = ? AND team_id = ?", hackathonID, teamID).
+		Updates(map[string]interface{}{"hidden": dto.Hidden, "hidden_reason": dto.Reason})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении карточки проекта"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Команда не создавала карточку проекта"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"hidden": dto.Hidden, "reason": dto.Reason})
+}
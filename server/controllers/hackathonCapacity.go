@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"server/models"
+	"server/types"
+	"strconv"
+	"time"
+)
+
+// lockHackathon загружает хакатон с блокировкой строки, чтобы параллельные
+// записи на участие не превысили лимит
+func lockHackathon(tx *gorm.DB, hackathonID uint) (models.Hackathon, error) {
+	var hackathon models.Hackathon
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&hackathon, hackathonID).Error
+	return hackathon, err
+}
+
+// countParticipants возвращает число участников хакатона (без менторов и организаторов)
+func countParticipants(tx *gorm.DB, hackathonID uint) (int64, error) {
+	var count int64
+	err := tx.Model(&models.BndUserHackathon{}).
+		Where("hackathon_id = ? AND hackathon_role = 1", hackathonID).
+		Count(&count).Error
+	return count, err
+}
+
+// waitlistPosition возвращает позицию пользователя в очереди ожидания (начиная с 1)
+func waitlistPosition(tx *gorm.DB, entry models.HackathonWaitlist) (int64, error) {
+	var ahead int64
+	err := tx.Model(&models.HackathonWaitlist{}).
+		Where("hackathon_id = ? AND (created_at < ? OR (created_at = ? AND id < ?))",
+			entry.HackathonID, entry.CreatedAt, entry.CreatedAt, entry.ID).
+		Count(&ahead).Error
+	return ahead + 1, err
+}
+
+// promoteWaitlist переводит пользователей из очереди ожидания в участники, пока есть свободные места
+// и идёт регистрация: после неё очередь не трогается. Хакатон должен быть заблокирован вызывающей транзакцией
+func promoteWaitlist(tx *gorm.DB, hackathon models.Hackathon) ([]uint, error) {
+	if !hackathon.State(time.Now()).Allows(models.HackathonPhaseRegistration) {
+		return nil, nil
+	}
+
+	query := tx.Where("hackathon_id = ?", hackathon.ID).Order("created_at, id")
+
+	if hackathon.MaxParticipants > 0 {
+		count, err := countParticipants(tx, hackathon.ID)
+		if err != nil {
+			return nil, err
+		}
+		free := int64(hackathon.MaxParticipants) - count
+		if free <= 0 {
+			return nil, nil
+		}
+		query = query.Limit(int(free))
+	}
+
+	var entries []models.HackathonWaitlist
+	if err := query.Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	promoted := make([]uint, 0, len(entries))
+	for _, entry := range entries {
+		participant := models.BndUserHackathon{
+			UserID:        entry.UserID,
+			HackathonID:   hackathon.ID,
+			HackathonRole: 1,
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&participant).Error; err != nil {
+			return nil, err
+		}
+		if err := tx.Unscoped().Delete(&entry).Error; err != nil {
+			return nil, err
+		}
+		promoted = append(promoted, entry.UserID)
+	}
+
+	return promoted, nil
+}
+
+// teamSize возвращает число участников команды
+func teamSize(tx *gorm.DB, teamID uint) (int64, error) {
+	var count int64
+	err := tx.Model(&models.BndUserTeam{}).Where("team_id = ?", teamID).Count(&count).Error
+	return count, err
+}
+
+// teamIsFull проверяет, достигла ли команда максимального размера
+func teamIsFull(tx *gorm.DB, hackathon models.Hackathon, teamID uint) (bool, error) {
+	if hackathon.MaxTeamSize <= 0 {
+		return false, nil
+	}
+	size, err := teamSize(tx, teamID)
+	if err != nil {
+		return false, err
+	}
+	return size >= int64(hackathon.MaxTeamSize), nil
+}
+
+// teamEligible определяет, может ли команда сдавать проект: после окончания
+// регистрации команда меньше минимального размера к сдаче не допускается
+func teamEligible(hackathon models.Hackathon, size int64, now time.Time) bool {
+	if hackathon.MinTeamSize <= 0 || hackathon.RegDateTo.IsZero() || now.Before(hackathon.RegDateTo) {
+		return true
+	}
+	return size >= int64(hackathon.MinTeamSize)
+}
+
+// LeaveHackathon отменяет участие пользователя в хакатоне или убирает его из очереди ожидания.
+// Освободившееся место сразу отдаётся первому в очереди
+func (hc *HackathonController) LeaveHackathon(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	var message string
+	err = hc.DB.Transaction(func(tx *gorm.DB) error {
+		hackathon, err := lockHackathon(tx, uint(hackathonID))
+		if err != nil {
+			return err
+		}
+
+		// Пользователь ещё в очереди - просто убираем его оттуда
+		result := tx.Unscoped().
+			Where("user_id = ? AND hackathon_id = ?", userID, hackathon.ID).
+			Delete(&models.HackathonWaitlist{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			message = "Вы покинули очередь ожидания"
+			return nil
+		}
+
+		var participant models.BndUserHackathon
+		if err := tx.Where("user_id = ? AND hackathon_id = ?", userID, hackathon.ID).First(&participant).Error; err != nil {
+			return err
+		}
+		if participant.HackathonRole != 1 {
+			return errLeaveNotParticipant
+		}
+
+		var teamCount int64
+		if err := tx.Model(&models.BndUserTeam{}).
+			Joins("JOIN teams ON teams.id = bnd_user_teams.team_id").
+			Where("bnd_user_teams.user_id = ? AND teams.hackathon_id = ? AND teams.deleted_at IS NULL", userID, hackathon.ID).
+			Count(&teamCount).Error; err != nil {
+			return err
+		}
+		if teamCount > 0 {
+			return errLeaveInTeam
+		}
+
+		if err := tx.Where("user_id = ? AND hackathon_id = ?", userID, hackathon.ID).Delete(&models.BndUserHackathon{}).Error; err != nil {
+			return err
+		}
+
+		if _, err := promoteWaitlist(tx, hackathon); err != nil {
+			return err
+		}
+
+		message = "Вы покинули хакатон"
+		return nil
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Вы не участвуете в хакатоне и не стоите в очереди"})
+		case errors.Is(err, errLeaveNotParticipant):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Организаторы и менторы не могут покинуть хакатон"})
+		case errors.Is(err, errLeaveInTeam):
+			c.JSON(http.StatusConflict, gin.H{"error": "Сначала покиньте команду"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при выходе из хакатона", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+var (
+	errLeaveNotParticipant = errors.New("пользователь не является участником хакатона")
+	errLeaveInTeam         = errors.New("пользователь состоит в команде")
+	errTeamFull            = errors.New("команда заполнена")
+)
@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"net/http"
+	"server/middlewares"
+	"server/models"
+	"server/models/DTO/hackathonDTO"
+	"strconv"
+	"time"
+)
+
+// hackathonState загружает хакатон вместе с шагами и вычисляет его текущий этап
+func (hc *HackathonController) hackathonState(hackathonID uint) (models.Hackathon, models.HackathonState, error) {
+	var hackathon models.Hackathon
+	if err := hc.DB.Preload("Steps").First(&hackathon, hackathonID).Error; err != nil {
+		return hackathon, models.HackathonState{}, err
+	}
+	return hackathon, hackathon.State(time.Now()), nil
+}
+
+// requirePhase проверяет этап хакатона там, где его нельзя проверить middleware
+// (например, когда ID хакатона не передаётся в URL). Возвращает false, если ответ уже отправлен
+func (hc *HackathonController) requirePhase(c *gin.Context, hackathonID uint, phases ...int) bool {
+	_, state, err := hc.hackathonState(hackathonID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return false
+	}
+
+	if !state.Allows(phases...) {
+		middlewares.PhaseConflict(c, state, phases)
+		return false
+	}
+
+	return true
+}
+
+// ExtendPhase открывает этап хакатона до указанного момента независимо от дат
+func (hc *HackathonController) ExtendPhase(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	var dto hackathonDTO.PhaseOverride
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка валидации: " + err.Error()})
+		return
+	}
+
+	hackathon, state, err := hc.hackathonState(uint(hackathonID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return
+	}
+
+	phase := state.Phase
+	if dto.Phase != nil {
+		phase = *dto.Phase
+	}
+	if _, _, ok := hackathon.PhaseWindow(phase); !ok {
+		c.JSON(http.StatusConflict, gin.H{"error": "Продлить можно только регистрацию, работу над проектами или оценку", "state": state})
+		return
+	}
+
+	if !dto.Until.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Дата окончания продления должна быть в будущем"})
+		return
+	}
+
+	if err := hc.DB.Model(&hackathon).Updates(map[string]interface{}{
+		"phase_override":       phase,
+		"phase_override_open":  true,
+		"phase_override_until": dto.Until,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при изменении этапа хакатона"})
+		return
+	}
+
+	hackathon.PhaseOverride = &phase
+	hackathon.PhaseOverrideOpen = true
+	hackathon.PhaseOverrideUntil = &dto.Until
+
+	c.JSON(http.StatusOK, gin.H{"message": "Этап хакатона продлён", "state": hackathon.State(time.Now())})
+}
+
+// ClosePhase досрочно закрывает текущий этап хакатона до его планового окончания
+func (hc *HackathonController) ClosePhase(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	hackathon, state, err := hc.hackathonState(uint(hackathonID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return
+	}
+
+	if _, _, ok := hackathon.PhaseWindow(state.Phase); !ok || !state.Open {
+		c.JSON(http.StatusConflict, gin.H{"error": "Сейчас нет открытого этапа, который можно закрыть", "state": state})
+		return
+	}
+
+	// Этап, вычисленный только по датам, без ручных изменений
+	planned := hackathon
+	planned.PhaseOverride = nil
+	plannedState := planned.State(time.Now())
+
+	updates := map[string]interface{}{
+		"phase_override":       nil,
+		"phase_override_open":  false,
+		"phase_override_until": nil,
+	}
+	// Если по датам этап ещё открыт, закрываем его до планового окончания;
+	// иначе достаточно отменить продление
+	if plannedState.Allows(state.Phase) && plannedState.Until != nil {
+		updates["phase_override"] = state.Phase
+		updates["phase_override_until"] = *plannedState.Until
+	}
+
+	if err := hc.DB.Model(&hackathon).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при изменении этапа хакатона"})
+		return
+	}
+
+	// Перечитываем хакатон, чтобы вернуть актуальный этап
+	_, state, err = hc.hackathonState(uint(hackathonID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении этапа хакатона"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Этап хакатона закрыт", "state": state})
+}
@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"net/http"
+	"server/models"
+	"server/models/DTO/hackathonDTO"
+	"strconv"
+	"time"
+)
+
+// SubmitForReview отправляет черновик хакатона на проверку администратору
+func (hc *HackathonController) SubmitForReview(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	var hackathon models.Hackathon
+	if err := hc.DB.First(&hackathon, hackathonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return
+	}
+
+	if hackathon.Status != models.HackathonStatusDraft {
+		c.JSON(http.StatusConflict, gin.H{"error": "На проверку можно отправить только черновик", "status": hackathon.Status})
+		return
+	}
+
+	now := time.Now()
+	if err := hc.DB.Model(&hackathon).Updates(map[string]interface{}{
+		"status":       models.HackathonStatusPendingReview,
+		"submitted_at": now,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при отправке хакатона на проверку"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Хакатон отправлен на проверку", "status": models.HackathonStatusPendingReview})
+}
+
+// CancelHackathon отменяет хакатон; отменённый хакатон пропадает из списка и переходит в архив
+func (hc *HackathonController) CancelHackathon(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	var hackathon models.Hackathon
+	if err := hc.DB.First(&hackathon, hackathonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return
+	}
+
+	if hackathon.Status == models.HackathonStatusCancelled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Хакатон уже отменён"})
+		return
+	}
+
+	if err := hc.DB.Model(&hackathon).Update("status", models.HackathonStatusCancelled).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при отмене хакатона"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Хакатон отменён", "status": models.HackathonStatusCancelled})
+}
+
+// GetReviewQueue возвращает хакатоны, ожидающие проверки, в порядке отправки
+func (hc *HackathonController) GetReviewQueue(c *gin.Context) {
+	var filter hackathonDTO.ReviewFilter
+	if err := c.ShouldBindJSON(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат фильтров"})
+		return
+	}
+
+	query := hc.DB.Model(&models.Hackathon{}).Where("status = ?", models.HackathonStatusPendingReview)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при подсчете хакатонов", "details": err.Error()})
+		return
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	var hackathons []models.Hackathon
+	if err := query.Preload("Organization").
+		Order("submitted_at, id").
+		Limit(limit).
+		Offset(filter.Offset).
+		Find(&hackathons).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении хакатонов", "details": err.Error()})
+		return
+	}
+
+	list := make([]hackathonDTO.ReviewInfo, 0, len(hackathons))
+	for _, h := range hackathons {
+		info := hackathonDTO.ReviewInfo{
+			ID:             h.ID,
+			Name:           h.Name,
+			Description:    h.Description,
+			OrganizationID: h.OrganizationID,
+			RegDateFrom:    h.RegDateFrom,
+			EvalDateTo:     h.EvalDateTo,
+			SubmittedAt:    h.SubmittedAt,
+		}
+		if h.Organization != nil {
+			info.OrganizationName = h.Organization.LegalName
+			info.OrganizationStatus = h.Organization.Status
+		}
+		list = append(list, info)
+	}
+
+	c.JSON(http.StatusOK, hackathonDTO.ReviewListResponse{
+		List:   list,
+		Total:  total,
+		Limit:  limit,
+		Offset: filter.Offset,
+	})
+}
+
+// ApproveHackathon публикует хакатон. Организация должна быть подтверждена администратором
+func (hc *HackathonController) ApproveHackathon(c *gin.Context) {
+	hackathon, ok := hc.pendingHackathon(c)
+	if !ok {
+		return
+	}
+
+	if hackathon.Organization == nil || hackathon.Organization.Status != models.OrganizationStatusApproved {
+		c.JSON(http.StatusConflict, gin.H{"error": "Организация хакатона не подтверждена"})
+		return
+	}
+
+	if err := hc.DB.Model(&hackathon).Updates(map[string]interface{}{
+		"status":         models.HackathonStatusPublished,
+		"review_comment": "",
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при публикации хакатона"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Хакатон опубликован", "status": models.HackathonStatusPublished})
+}
+
+// RejectHackathon возвращает хакатон в черновик с комментарием администратора
+func (hc *HackathonController) RejectHackathon(c *gin.Context) {
+	var dto hackathonDTO.ReviewReject
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Необходимо указать причину отклонения"})
+		return
+	}
+
+	hackathon, ok := hc.pendingHackathon(c)
+	if !ok {
+		return
+	}
+
+	if err := hc.DB.Model(&hackathon).Updates(map[string]interface{}{
+		"status":         models.HackathonStatusDraft,
+		"review_comment": dto.Reason,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при отклонении хакатона"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Хакатон отклонён", "status": models.HackathonStatusDraft})
+}
+
+// pendingHackathon загружает хакатон из URL и проверяет, что он ожидает проверки
+func (hc *HackathonController) pendingHackathon(c *gin.Context) (models.Hackathon, bool) {
+	var hackathon models.Hackathon
+
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return hackathon, false
+	}
+
+	if err := hc.DB.Preload("Organization").First(&hackathon, hackathonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return hackathon, false
+	}
+
+	if hackathon.Status != models.HackathonStatusPendingReview {
+		c.JSON(http.StatusConflict, gin.H{"error": "Хакатон не ожидает проверки", "status": hackathon.Status})
+		return hackathon, false
+	}
+
+	return hackathon, true
+}
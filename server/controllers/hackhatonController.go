@@ -7,7 +7,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"log"
+	"mime/multipart"
 	"net/http"
 	"reflect"
 	"server/models"
@@ -17,12 +19,12 @@ import (
 	"server/models/DTO/hackathonDTO"
 	"server/models/DTO/hackathonStepDTO"
 	"server/models/DTO/mentorInviteDTO"
+	"server/models/DTO/similarityDTO"
 	"server/models/DTO/teamDTO"
 	"server/models/DTO/teamInviteDTO"
 	"server/models/DTO/technologyDTO"
 	"server/models/DTO/userDTO"
 	"server/types"
-	"sort"
 	"strconv"
 	"time"
 	_ "time"
@@ -40,6 +42,27 @@ func NewHackathonController(db *gorm.DB, fileController *FileController) *Hackat
 	}
 }
 
+// validationErrorResponse описывает ошибки валидации DTO хакатона по полям
+func validationErrorResponse(err error) gin.H {
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return gin.H{"error": "Ошибка валидации", "details": err.Error()}
+	}
+
+	// Преобразуем validator.ValidationErrors в понятный формат
+	errorDetails := make([]map[string]interface{}, 0)
+	for _, e := range validationErrors {
+		errorDetails = append(errorDetails, map[string]interface{}{
+			"field":   e.Field(),
+			"tag":     e.Tag(),
+			"value":   e.Value(),
+			"param":   e.Param(),
+			"message": fmt.Sprintf("Поле '%s' не прошло валидацию: %s", e.Field(), e.Tag()),
+		})
+	}
+	return gin.H{"error": "Ошибка валидации", "details": errorDetails}
+}
+
 // Создание хакатона
 // CreateHackathon - метод для создания хакатона
 func (hc *HackathonController) CreateHackathon(c *gin.Context) {
@@ -80,25 +103,22 @@ func (hc *HackathonController) CreateHackathon(c *gin.Context) {
 	// Валидируем DTO
 	validate := validator.New()
 	if err := validate.Struct(dto); err != nil {
-		validationErrors, ok := err.(validator.ValidationErrors)
-		if !ok {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка валидации", "details": err.Error()})
-			return
-		}
+		c.JSON(http.StatusBadRequest, validationErrorResponse(err))
+		return
+	}
 
-		// Преобразуем validator.ValidationErrors в понятный формат
-		errorDetails := make([]map[string]interface{}, 0)
-		for _, e := range validationErrors {
-			errorDetails = append(errorDetails, map[string]interface{}{
-				"field":   e.Field(),
-				"tag":     e.Tag(),
-				"value":   e.Value(),
-				"param":   e.Param(),
-				"message": fmt.Sprintf("Поле '%s' не прошло валидацию: %s", e.Field(), e.Tag()),
-			})
-		}
+	if dto.MaxTeamSize > 0 && dto.MinTeamSize > dto.MaxTeamSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Минимальный размер команды не может превышать максимальный"})
+		return
+	}
 
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка валидации", "details": errorDetails})
+	if err := dto.ToModel().ValidateVoting(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := dto.ToModel().ValidateArchiveRules(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -214,15 +234,8 @@ func (hc *HackathonController) CreateHackathon(c *gin.Context) {
 		// Создаем и связываем этапы хакатона
 		if len(dto.Steps) > 0 {
 			for _, stepDTO := range dto.Steps {
-				step := models.HackathonStep{
-					HackathonID: hackathon.ID,
-					Name:        stepDTO.Name,
-					Description: stepDTO.Description,
-					StartDate:   stepDTO.StartDate,
-					EndDate:     stepDTO.EndDate,
-				}
-
-				if err := tx.Create(&step).Error; err != nil {
+				// Критерии отборочного тура создаются вместе с этапом
+				if err := tx.Create(stepDTO.ToModel(hackathon.ID)).Error; err != nil {
 					return err
 				}
 			}
@@ -231,14 +244,9 @@ func (hc *HackathonController) CreateHackathon(c *gin.Context) {
 		// Создаем и связываем критерии оценки
 		if len(dto.Criteria) > 0 {
 			for _, criterionDTO := range dto.Criteria {
-				criterion := models.Criteria{
-					HackathonID: hackathon.ID,
-					Name:        criterionDTO.Name,
-					MinScore:    criterionDTO.MinScore,
-					MaxScore:    criterionDTO.MaxScore,
-				}
+				criterion := criterionDTO.ToModel(hackathon.ID)
 
-				if err := tx.Create(&criterion).Error; err != nil {
+				if err := tx.Create(criterion).Error; err != nil {
 					return err
 				}
 			}
@@ -294,11 +302,9 @@ func (hc *HackathonController) GetAll(c *gin.Context) {
 	userID := claims.UserID
 
 	// Базовый запрос для подсчета общего количества
-	// Добавляем фильтр по статусу = 1
 	countQuery := hc.DB.Model(&models.Hackathon{})
 
 	// Базовый запрос для получения данных с предзагрузкой связанных данных
-	// Также фильтруем по статусу = 1
 	dataQuery := hc.DB.Model(&models.Hackathon{}).
 		Preload("Organization").
 		Preload("Technologies").
@@ -307,6 +313,13 @@ func (hc *HackathonController) GetAll(c *gin.Context) {
 
 	// Применение дополнительных фильтров к обоим запросам
 	applyFilters := func(query *gorm.DB) *gorm.DB {
+		// Организатор видит свои хакатоны в любом статусе, остальные - только
+		// опубликованные хакатоны подтверждённых организаций
+		if filterData.Role != 3 {
+			query = query.Where("hackathons.status = ?", models.HackathonStatusPublished).
+				Where("EXISTS (SELECT 1 FROM organizations WHERE organizations.id = hackathons.organization_id AND organizations.status = ? AND organizations.deleted_at IS NULL)", models.OrganizationStatusApproved)
+		}
+
 		if filterData.Name != "" {
 			query = query.Where("hackathons.name LIKE ?", "%"+filterData.Name+"%")
 		}
@@ -329,6 +342,15 @@ func (hc *HackathonController) GetAll(c *gin.Context) {
 			}
 		}
 
+		// Хакатон подходит, если допустимый размер команды пересекается с запрошенным
+		if filterData.MinTeamSize > 0 {
+			query = query.Where("(hackathons.max_team_size = 0 OR hackathons.max_team_size >= ?)", filterData.MinTeamSize)
+		}
+
+		if filterData.MaxTeamSize > 0 {
+			query = query.Where("hackathons.min_team_size <= ?", filterData.MaxTeamSize)
+		}
+
 		if filterData.TotalAward > 0 {
 			// Подзапрос для суммы наград
 			query = query.Where("COALESCE((SELECT SUM(money_amount * (place_to - place_from + 1)) FROM awards WHERE hackathon_id = hackathons.id AND deleted_at IS NULL), 0) >= ?", filterData.TotalAward)
@@ -403,8 +425,10 @@ func (hc *HackathonController) GetAll(c *gin.Context) {
 
 		// Определяем URL логотипа
 		var logoId uint
+		var logoURL string
 		if h.Logo != nil {
 			logoId = h.Logo.ID
+			logoURL = hc.FileController.publicURL(c.Request.Context(), *h.Logo)
 		}
 
 		// Подсчет пользователей с ролью 1 в хакатоне
@@ -429,9 +453,13 @@ func (hc *HackathonController) GetAll(c *gin.Context) {
 			WorkDateTo:       h.WorkDateTo,
 			EvalDateFrom:     h.EvalDateFrom,
 			EvalDateTo:       h.EvalDateTo,
+			Status:           h.Status,
 			LogoId:           logoId,
+			LogoURL:          logoURL,
 			Technologies:     technologies,
 			TotalAward:       totalAward,
+			MinTeamSize:      h.MinTeamSize,
+			MaxTeamSize:      h.MaxTeamSize,
 			UserCount:        int(userCount),
 		}
 
@@ -452,7 +480,7 @@ func (hc *HackathonController) GetAll(c *gin.Context) {
 func (hc *HackathonController) GetAllFull(c *gin.Context) {
 	var hackathons []models.Hackathon
 
-	if err := hc.DB.Preload("Logo").Preload("Users").Preload("Files").Preload("Teams").Preload("Steps").Preload("Technologies").Preload("Awards").Preload("Users").Preload("Steps").Preload("Criteria").Preload("Organization").Find(&hackathons).Error; err != nil {
+	if err := hc.DB.Preload("Logo").Preload("Users").Preload("Files").Preload("Teams").Preload("Steps").Preload("Technologies").Preload("Awards").Preload("Users").Preload("Steps").Preload("Criteria", "step_id IS NULL").Preload("Organization").Find(&hackathons).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении хакатонов", "details": err.Error()})
 		return
 	}
@@ -495,6 +523,13 @@ func (hc *HackathonController) Update(c *gin.Context) {
 		return
 	}
 
+	// Валидируем DTO
+	validate := validator.New()
+	if err := validate.Struct(dto); err != nil {
+		c.JSON(http.StatusBadRequest, validationErrorResponse(err))
+		return
+	}
+
 	// Извлечение ID хакатона из URL
 	hackathonID := c.Param("hackathon_id")
 	if hackathonID == "" {
@@ -525,7 +560,7 @@ func (hc *HackathonController) Update(c *gin.Context) {
 		Preload("Technologies").
 		Preload("Steps").
 		Preload("Awards").
-		Preload("Criteria").
+		Preload("Criteria", "step_id IS NULL").
 		First(&hackathon, hackathonID).Error; err != nil {
 		rollbackWithError(http.StatusNotFound, "Хакатон не найден")
 		return
@@ -547,6 +582,31 @@ func (hc *HackathonController) Update(c *gin.Context) {
 	// Обновление основных полей хакатона
 	hackathon = dto.ToModel(hackathon)
 
+	if hackathon.MaxTeamSize > 0 && hackathon.MinTeamSize > hackathon.MaxTeamSize {
+		rollbackWithError(http.StatusBadRequest, "Минимальный размер команды не может превышать максимальный")
+		return
+	}
+
+	if hackathon.SubmissionGraceMinutes < 0 || hackathon.SubmissionGraceMinutes > 7*24*60 {
+		rollbackWithError(http.StatusBadRequest, "Льготный период сдачи должен быть от 0 минут до 7 дней")
+		return
+	}
+
+	if hackathon.ScoreAggregation < models.ScoreAggregationMean || hackathon.ScoreAggregation > models.ScoreAggregationTrimmedMean {
+		rollbackWithError(http.StatusBadRequest, "Неизвестный способ агрегации оценок")
+		return
+	}
+
+	if err := hackathon.ValidateVoting(); err != nil {
+		rollbackWithError(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := hackathon.ValidateArchiveRules(); err != nil {
+		rollbackWithError(http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// -------------------------------------------
 	// Обработка логотипа
 	// -------------------------------------------
@@ -579,7 +639,7 @@ func (hc *HackathonController) Update(c *gin.Context) {
 	// Если есть новый логотип, загружаем его
 	if hasNewLogo {
 		// Загружаем новый логотип
-		logoFile, err := hc.FileController.UploadFile(c, logoFile, hackathon.ID, "hackathon")
+		logoFile, err := hc.FileController.UploadFile(c, logoFile, hackathon.ID, "hackathon_logo")
 		if err != nil {
 			rollbackWithError(http.StatusInternalServerError, "Ошибка при загрузке логотипа: "+err.Error())
 			return
@@ -752,23 +812,27 @@ func (hc *HackathonController) Update(c *gin.Context) {
 	// Обновление этапов
 	// -------------------------------------------
 	if len(dto.Steps) > 0 {
-		// Удаляем существующие этапы
+		// Этапы пересоздаются, поэтому итоги закрытых туров потерялись бы
+		for _, step := range hackathon.Steps {
+			if step.IsRound && step.ClosedAt != nil {
+				rollbackWithError(http.StatusConflict, "Нельзя изменить этапы после подведения итогов отборочного тура")
+				return
+			}
+		}
+
+		// Удаляем существующие этапы вместе с критериями отборочных туров
 		if err := tx.Where("hackathon_id = ?", hackathon.ID).Delete(&models.HackathonStep{}).Error; err != nil {
 			rollbackWithError(http.StatusInternalServerError, "Ошибка при удалении старых этапов")
 			return
 		}
+		if err := tx.Where("hackathon_id = ? AND step_id IS NOT NULL", hackathon.ID).Delete(&models.Criteria{}).Error; err != nil {
+			rollbackWithError(http.StatusInternalServerError, "Ошибка при удалении критериев отборочных туров")
+			return
+		}
 
 		// Создаем новые этапы
 		for _, step := range dto.Steps {
-			stepModel := models.HackathonStep{
-				HackathonID: hackathon.ID,
-				Name:        step.Name,
-				Description: step.Description,
-				StartDate:   step.StartDate,
-				EndDate:     step.EndDate,
-			}
-
-			if err := tx.Create(&stepModel).Error; err != nil {
+			if err := tx.Create(step.ToModel(hackathon.ID)).Error; err != nil {
 				rollbackWithError(http.StatusInternalServerError, "Ошибка при создании этапа")
 				return
 			}
@@ -779,8 +843,8 @@ func (hc *HackathonController) Update(c *gin.Context) {
 	// Обновление наград
 	// -------------------------------------------
 	if len(dto.Awards) > 0 {
-		// Удаляем существующие награды
-		if err := tx.Where("hackathon_id = ?", hackathon.ID).Delete(&models.Award{}).Error; err != nil {
+		// Удаляем существующие награды общего рейтинга, призы номинаций остаются
+		if err := tx.Where("hackathon_id = ? AND track_id IS NULL", hackathon.ID).Delete(&models.Award{}).Error; err != nil {
 			rollbackWithError(http.StatusInternalServerError, "Ошибка при удалении старых наград")
 			return
 		}
@@ -806,25 +870,18 @@ func (hc *HackathonController) Update(c *gin.Context) {
 	// Обновление критериев оценки
 	// -------------------------------------------
 	if len(dto.Criteria) > 0 {
-		// Удаляем существующие критерии
-		if err := tx.Where("hackathon_id = ?", hackathon.ID).Delete(&models.Criteria{}).Error; err != nil {
-			rollbackWithError(http.StatusInternalServerError, "Ошибка при удалении старых критериев")
+		// Критерии туров задаются в этапах, здесь меняются только критерии финальной оценки
+		err := syncFinalCriteria(tx, hackathon.ID, dto.Criteria)
+		switch {
+		case errors.Is(err, errCriterionNotFound):
+			rollbackWithError(http.StatusBadRequest, "Критерий не найден среди критериев хакатона")
+			return
+		case errors.Is(err, errCriterionInUse):
+			rollbackWithError(http.StatusConflict, err.Error())
+			return
+		case err != nil:
+			rollbackWithError(http.StatusInternalServerError, "Ошибка при обновлении критериев")
 			return
-		}
-
-		// Создаем новые критерии
-		for _, criterion := range dto.Criteria {
-			criterionModel := models.Criteria{
-				HackathonID: hackathon.ID,
-				Name:        criterion.Name,
-				MinScore:    criterion.MinScore,
-				MaxScore:    criterion.MaxScore,
-			}
-
-			if err := tx.Create(&criterionModel).Error; err != nil {
-				rollbackWithError(http.StatusInternalServerError, "Ошибка при создании критерия")
-				return
-			}
 		}
 	}
 
@@ -834,6 +891,17 @@ func (hc *HackathonController) Update(c *gin.Context) {
 		return
 	}
 
+	// Если лимит участников увеличился, отдаём новые места очереди ожидания
+	locked, err := lockHackathon(tx, hackathon.ID)
+	if err != nil {
+		rollbackWithError(http.StatusInternalServerError, "Ошибка при обновлении хакатона")
+		return
+	}
+	if _, err := promoteWaitlist(tx, locked); err != nil {
+		rollbackWithError(http.StatusInternalServerError, "Ошибка при переводе участников из очереди ожидания")
+		return
+	}
+
 	// Завершаем транзакцию
 	if err := tx.Commit().Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при подтверждении транзакции"})
@@ -867,10 +935,11 @@ func (hc *HackathonController) GetByIDFull(c *gin.Context) {
 	var hackathon models.Hackathon
 	if err := hc.DB.Preload("Logo").
 		Preload("Files").
-		Preload("Steps").
+		Preload("Steps", func(db *gorm.DB) *gorm.DB { return db.Order("start_date") }).
+		Preload("Steps.Criteria", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
 		Preload("Technologies").
 		Preload("Awards").
-		Preload("Criteria").
+		Preload("Criteria", "step_id IS NULL").
 		Preload("Organization").
 		First(&hackathon, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
@@ -888,7 +957,12 @@ func (hc *HackathonController) GetByIDFull(c *gin.Context) {
 		hackathonRole = userHackathon.HackathonRole
 	}
 
-	// Проверяем права доступа
+	// Проверяем права доступа: неопубликованный хакатон видят только его
+	// организаторы, менторы и администраторы
+	if hackathon.Status != models.HackathonStatusPublished && hackathonRole < 2 && claims.SystemRole < 2 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return
+	}
 
 	// Подсчитываем количество пользователей в хакатоне
 	var userCount int64
@@ -916,13 +990,7 @@ func (hc *HackathonController) GetByIDFull(c *gin.Context) {
 	// Преобразуем шаги в DTO
 	stepsDTOs := make([]hackathonStepDTO.Get, 0, len(hackathon.Steps))
 	for _, step := range hackathon.Steps {
-		stepsDTOs = append(stepsDTOs, hackathonStepDTO.Get{
-			ID:          step.ID,
-			Name:        step.Name,
-			Description: step.Description,
-			StartDate:   step.StartDate,
-			EndDate:     step.EndDate,
-		})
+		stepsDTOs = append(stepsDTOs, toStepDTO(step))
 	}
 
 	// Преобразуем награды в DTO
@@ -934,6 +1002,7 @@ func (hc *HackathonController) GetByIDFull(c *gin.Context) {
 			Additionally: award.Additionally,
 			PlaceFrom:    award.PlaceFrom,
 			PlaceTo:      award.PlaceTo,
+			TrackID:      award.TrackID,
 		})
 	}
 
@@ -950,10 +1019,12 @@ func (hc *HackathonController) GetByIDFull(c *gin.Context) {
 	criteriaDTOs := make([]criteriaDTO.Get, 0, len(hackathon.Criteria))
 	for _, criteria := range hackathon.Criteria {
 		criteriaDTOs = append(criteriaDTOs, criteriaDTO.Get{
-			ID:       criteria.ID,
-			Name:     criteria.Name,
-			MaxScore: criteria.MaxScore,
-			MinScore: criteria.MinScore,
+			ID:            criteria.ID,
+			Name:          criteria.Name,
+			MaxScore:      criteria.MaxScore,
+			MinScore:      criteria.MinScore,
+			Weight:        criteria.Weight,
+			Normalization: criteria.Normalization,
 		})
 	}
 
@@ -979,12 +1050,36 @@ func (hc *HackathonController) GetByIDFull(c *gin.Context) {
 		TotalAward: totalAward,
 		UserCount:  int(userCount),
 
+		MinTeamSize:     hackathon.MinTeamSize,
+		MaxTeamSize:     hackathon.MaxTeamSize,
+		MaxParticipants: hackathon.MaxParticipants,
+
+		SubmissionGraceMinutes: hackathon.SubmissionGraceMinutes,
+
+		ArchiveRequiredFiles:       hackathon.ArchiveRequiredFiles,
+		ArchiveForbiddenExtensions: hackathon.ArchiveForbiddenExtensions,
+		ArchiveMaxSizeMB:           hackathon.ArchiveMaxSizeMB,
+
+		BlindReview:   hackathon.BlindReview,
+		BlindRevealed: hackathon.BlindRevealed,
+
+		VotingMode:   hackathon.VotingMode,
+		VoteBudget:   hackathon.VoteBudget,
+		VotingFrom:   hackathon.VotingFrom,
+		VotingTo:     hackathon.VotingTo,
+		VotingWeight: hackathon.VotingWeight,
+
+		GalleryEnabled: hackathon.GalleryEnabled,
+
 		Files:        filesDTOs,
 		Steps:        stepsDTOs,
 		Awards:       awardsDTOs,
 		Technologies: techDTOs,
 		Criteria:     criteriaDTOs,
 
+		ScoreAggregation: hackathon.ScoreAggregation,
+		Status:           hackathon.Status,
+
 		CanEdit:       canEdit,
 		HackathonRole: hackathonRole,
 	}
@@ -992,6 +1087,7 @@ func (hc *HackathonController) GetByIDFull(c *gin.Context) {
 	// Установим LogoId, если логотип есть
 	if hackathon.Logo != nil {
 		fullInfo.LogoId = hackathon.Logo.ID
+		fullInfo.LogoURL = hc.FileController.publicURL(c.Request.Context(), *hackathon.Logo)
 	}
 
 	c.JSON(http.StatusOK, fullInfo)
@@ -1046,6 +1142,17 @@ func (hc *HackathonController) AddUser(c *gin.Context) {
 		return
 	}
 
+	// Если у хакатона есть анкета, участие оформляется через заявку
+	hasForm, err := hackathonHasForm(hc.DB, hackathon.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке анкеты хакатона"})
+		return
+	}
+	if hasForm {
+		c.JSON(http.StatusConflict, gin.H{"error": "Для участия необходимо заполнить анкету", "form": true})
+		return
+	}
+
 	// Проверка, не добавлен ли пользователь уже
 	var existingUser models.BndUserHackathon
 	if err := hc.DB.Where("user_id = ? AND hackathon_id = ?", userID, hackathon.ID).First(&existingUser).Error; err == nil {
@@ -1053,18 +1160,60 @@ func (hc *HackathonController) AddUser(c *gin.Context) {
 		return
 	}
 
-	// Добавление пользователя к хакатону
-	userHackathon := models.BndUserHackathon{
-		UserID:        userID,
-		HackathonID:   hackathon.ID,
-		HackathonRole: 1,
-	}
+	// Если мест нет, пользователь встаёт в очередь ожидания
+	var waitlistEntry *models.HackathonWaitlist
+	err = hc.DB.Transaction(func(tx *gorm.DB) error {
+		locked, err := lockHackathon(tx, hackathon.ID)
+		if err != nil {
+			return err
+		}
+
+		if locked.MaxParticipants > 0 {
+			count, err := countParticipants(tx, locked.ID)
+			if err != nil {
+				return err
+			}
+
+			if count >= int64(locked.MaxParticipants) {
+				var entry models.HackathonWaitlist
+				if err := tx.Where(models.HackathonWaitlist{UserID: userID, HackathonID: locked.ID}).
+					FirstOrCreate(&entry).Error; err != nil {
+					return err
+				}
+				waitlistEntry = &entry
+				return nil
+			}
+		}
+
+		// Добавление пользователя к хакатону
+		userHackathon := models.BndUserHackathon{
+			UserID:        userID,
+			HackathonID:   locked.ID,
+			HackathonRole: 1,
+		}
+		return tx.Create(&userHackathon).Error
+	})
 
-	if err := hc.DB.Create(&userHackathon).Error; err != nil {
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при добавлении пользователя к хакатону", "details": err.Error()})
 		return
 	}
 
+	if waitlistEntry != nil {
+		position, err := waitlistPosition(hc.DB, *waitlistEntry)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при определении места в очереди"})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"message":  "Все места заняты, вы добавлены в очередь ожидания",
+			"waitlist": true,
+			"position": position,
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Пользователь успешно добавлен к хакатону"})
 }
 
@@ -1119,7 +1268,7 @@ func (pc *HackathonController) GetParticipants(c *gin.Context) {
 		Where("bnd_user_teams.user_id = ? AND teams.hackathon_id = ?", claims.UserID, hackathonID).
 		Limit(1)
 
-	if err := teamRoleQuery.First(&userTeamInfo).Error; err == nil && userTeamInfo.TeamRole == 2 {
+	if err := teamRoleQuery.First(&userTeamInfo).Error; err == nil && models.TeamRoleCan(userTeamInfo.TeamRole, models.TeamActionInvite) {
 		userIsCaptain = true
 		userTeamID = &userTeamInfo.TeamID
 	}
@@ -1315,7 +1464,7 @@ func (hc *HackathonController) CreateTeam(c *gin.Context) {
 	userTeam = models.BndUserTeam{
 		UserID:   userID,
 		TeamID:   teamModel.ID,
-		TeamRole: 2,
+		TeamRole: models.TeamRoleCaptain,
 	}
 
 	if err := hc.DB.Create(&userTeam).Error; err != nil {
@@ -1323,6 +1472,12 @@ func (hc *HackathonController) CreateTeam(c *gin.Context) {
 		return
 	}
 
+	// Создатель команды больше не ждёт ответа на свои заявки и приглашения
+	if err := cleanupPendingMembership(hc.DB, userID, uint(hackathonID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении заявок пользователя", "details": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusCreated, teamModel)
 }
 
@@ -1414,16 +1569,24 @@ func (hc *HackathonController) GetTeam(c *gin.Context) {
 		})
 	}
 
+	var hackathon models.Hackathon
+	if err := hc.DB.First(&hackathon, hackathonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return
+	}
+
 	// Используем указатель, чтобы значение могло быть null
 	teamData := userDTO.TeamData{
 		Name:         &userTeam.Name,
 		Participants: participants,
 		TeamRole:     userTeamLink.TeamRole, // Добавляем роль текущего пользователя
+		Eligible:     teamEligible(hackathon, int64(len(teamMembers)), time.Now()),
 	}
 
 	c.JSON(http.StatusOK, teamData)
 }
 
+// UpdateTeam переименовывает команду текущего пользователя в хакатоне
 func (hc *HackathonController) UpdateTeam(c *gin.Context) {
 	var team teamDTO.UpdateDTO
 	if err := c.ShouldBindJSON(&team); err != nil {
@@ -1431,22 +1594,34 @@ func (hc *HackathonController) UpdateTeam(c *gin.Context) {
 		return
 	}
 
-	teamIDStr := c.Param("team_id")
-	if teamIDStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Отсутствует идентификатор команды"})
+	validate := validator.New()
+	if err := validate.Struct(team); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка валидации данных", "details": err.Error()})
 		return
 	}
 
-	// Преобразование ID команды из строки в uint
-	teamID, err := strconv.ParseUint(teamIDStr, 10, 32)
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор команды"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор хакатона"})
+		return
+	}
+
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	link, err := userTeamInHackathon(hc.DB, userID, uint(hackathonID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Вы не состоите в команде этого хакатона"})
+		return
+	}
+
+	if !models.TeamRoleCan(link.TeamRole, models.TeamActionRename) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Переименовать команду может только капитан или его заместитель"})
 		return
 	}
 
 	var existingTeam models.Team
 	// Поиск команды по ID
-	if err := hc.DB.First(&existingTeam, teamID).Error; err != nil {
+	if err := hc.DB.First(&existingTeam, link.TeamID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Команда не найдена"})
 			return
@@ -1455,6 +1630,13 @@ func (hc *HackathonController) UpdateTeam(c *gin.Context) {
 		return
 	}
 
+	// Проверка уникальности названия команды в рамках хакатона
+	var sameName models.Team
+	if err := hc.DB.Where("hackathon_id = ? AND name = ? AND id != ?", hackathonID, *team.Name, existingTeam.ID).First(&sameName).Error; err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Команда с таким названием уже существует в этом хакатоне"})
+		return
+	}
+
 	// Обновление данных команды с использованием метода ToModel
 	updatedTeam := team.ToModel(existingTeam)
 
@@ -1490,10 +1672,11 @@ func (hc *HackathonController) GetByIDEditFull(c *gin.Context) {
 	var hackathon models.Hackathon
 	if err := hc.DB.Preload("Logo").
 		Preload("Files").
-		Preload("Steps").
+		Preload("Steps", func(db *gorm.DB) *gorm.DB { return db.Order("start_date") }).
+		Preload("Steps.Criteria", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
 		Preload("Technologies").
 		Preload("Awards").
-		Preload("Criteria").
+		Preload("Criteria", "step_id IS NULL").
 		Preload("Organization").
 		First(&hackathon, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
@@ -1544,13 +1727,7 @@ func (hc *HackathonController) GetByIDEditFull(c *gin.Context) {
 	// Преобразуем шаги в DTO
 	stepsDTOs := make([]hackathonStepDTO.Get, 0, len(hackathon.Steps))
 	for _, step := range hackathon.Steps {
-		stepsDTOs = append(stepsDTOs, hackathonStepDTO.Get{
-			ID:          step.ID,
-			Name:        step.Name,
-			Description: step.Description,
-			StartDate:   step.StartDate,
-			EndDate:     step.EndDate,
-		})
+		stepsDTOs = append(stepsDTOs, toStepDTO(step))
 	}
 
 	// Преобразуем награды в DTO
@@ -1562,6 +1739,7 @@ func (hc *HackathonController) GetByIDEditFull(c *gin.Context) {
 			Additionally: award.Additionally,
 			PlaceFrom:    award.PlaceFrom,
 			PlaceTo:      award.PlaceTo,
+			TrackID:      award.TrackID,
 		})
 	}
 
@@ -1578,10 +1756,12 @@ func (hc *HackathonController) GetByIDEditFull(c *gin.Context) {
 	criteriaDTOs := make([]criteriaDTO.Get, 0, len(hackathon.Criteria))
 	for _, criteria := range hackathon.Criteria {
 		criteriaDTOs = append(criteriaDTOs, criteriaDTO.Get{
-			ID:       criteria.ID,
-			Name:     criteria.Name,
-			MaxScore: criteria.MaxScore,
-			MinScore: criteria.MinScore,
+			ID:            criteria.ID,
+			Name:          criteria.Name,
+			MaxScore:      criteria.MaxScore,
+			MinScore:      criteria.MinScore,
+			Weight:        criteria.Weight,
+			Normalization: criteria.Normalization,
 		})
 	}
 
@@ -1623,6 +1803,27 @@ func (hc *HackathonController) GetByIDEditFull(c *gin.Context) {
 		TotalAward: totalAward,
 		UserCount:  int(userCount),
 
+		MinTeamSize:     hackathon.MinTeamSize,
+		MaxTeamSize:     hackathon.MaxTeamSize,
+		MaxParticipants: hackathon.MaxParticipants,
+
+		SubmissionGraceMinutes: hackathon.SubmissionGraceMinutes,
+
+		ArchiveRequiredFiles:       hackathon.ArchiveRequiredFiles,
+		ArchiveForbiddenExtensions: hackathon.ArchiveForbiddenExtensions,
+		ArchiveMaxSizeMB:           hackathon.ArchiveMaxSizeMB,
+
+		BlindReview:   hackathon.BlindReview,
+		BlindRevealed: hackathon.BlindRevealed,
+
+		VotingMode:   hackathon.VotingMode,
+		VoteBudget:   hackathon.VoteBudget,
+		VotingFrom:   hackathon.VotingFrom,
+		VotingTo:     hackathon.VotingTo,
+		VotingWeight: hackathon.VotingWeight,
+
+		GalleryEnabled: hackathon.GalleryEnabled,
+
 		Files:         filesDTOs,
 		Steps:         stepsDTOs,
 		Awards:        awardsDTOs,
@@ -1630,12 +1831,17 @@ func (hc *HackathonController) GetByIDEditFull(c *gin.Context) {
 		Criteria:      criteriaDTOs,
 		MentorInvites: mentorInvitesDTOs,
 
+		ScoreAggregation: hackathon.ScoreAggregation,
+		Status:           hackathon.Status,
+		ReviewComment:    hackathon.ReviewComment,
+
 		HackathonRole: hackathonRole,
 	}
 
 	// Установим LogoId, если логотип есть
 	if hackathon.Logo != nil {
 		fullInfo.LogoId = hackathon.Logo.ID
+		fullInfo.LogoURL = hc.FileController.publicURL(c.Request.Context(), *hackathon.Logo)
 	}
 
 	c.JSON(http.StatusOK, fullInfo)
@@ -1669,14 +1875,24 @@ func (hc *HackathonController) DeleteTeam(c *gin.Context) {
 		}
 	}()
 
-	// Find the team where user has role 2 in this hackathon
+	// Find the user's team in this hackathon
+	link, err := userTeamInHackathon(tx, userID, uint(hackathonID))
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, gin.H{"error": "Вы не состоите в команде этого хакатона"})
+		return
+	}
+
+	if !models.TeamRoleCan(link.TeamRole, models.TeamActionDisband) {
+		tx.Rollback()
+		c.JSON(http.StatusForbidden, gin.H{"error": "Расформировать команду может только капитан"})
+		return
+	}
+
 	var team models.Team
-	if err := tx.Table("teams").
-		Joins("JOIN bnd_user_teams ON bnd_user_teams.team_id = teams.id").
-		Where("teams.hackathon_id = ? AND bnd_user_teams.user_id = ? AND bnd_user_teams.team_role = 2", hackathonID, userID).
-		First(&team).Error; err != nil {
+	if err := tx.First(&team, link.TeamID).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusNotFound, gin.H{"error": "Не найдена команда с нужными правами в данном хакатоне"})
+		c.JSON(http.StatusNotFound, gin.H{"error": "Команда не найдена"})
 		return
 	}
 
@@ -1761,13 +1977,6 @@ func (hc *HackathonController) LeaveTeam(c *gin.Context) {
 		return
 	}
 
-	// Проверяем, не является ли пользователь капитаном
-	if userTeam.TeamRole == 2 {
-		tx.Rollback()
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Капитан не может покинуть команду. Вы можете только расформировать команду."})
-		return
-	}
-
 	// Удаляем связь пользователя с командой
 	if err := tx.Where("user_id = ? AND team_id = ?", userID, userTeam.TeamID).Delete(&models.BndUserTeam{}).Error; err != nil {
 		tx.Rollback()
@@ -1775,13 +1984,30 @@ func (hc *HackathonController) LeaveTeam(c *gin.Context) {
 		return
 	}
 
+	// Ушедший капитан передаёт команду участнику с наибольшим стажем
+	var succession teamSuccession
+	if userTeam.TeamRole == models.TeamRoleCaptain {
+		if succession, err = passCaptaincy(tx, userTeam.TeamID); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при передаче капитанства"})
+			return
+		}
+	}
+
 	// Фиксируем транзакцию
 	if err := tx.Commit().Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении изменений"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Вы успешно покинули команду"})
+	switch {
+	case succession.Disbanded:
+		c.JSON(http.StatusOK, gin.H{"message": "Вы покинули команду; в ней не осталось участников, и она расформирована"})
+	case succession.CaptainID != 0:
+		c.JSON(http.StatusOK, gin.H{"message": "Вы успешно покинули команду", "new_captain_id": succession.CaptainID})
+	default:
+		c.JSON(http.StatusOK, gin.H{"message": "Вы успешно покинули команду"})
+	}
 }
 
 func (hc *HackathonController) KickTeam(c *gin.Context) {
@@ -1848,10 +2074,10 @@ func (hc *HackathonController) KickTeam(c *gin.Context) {
 		return
 	}
 
-	// Проверяем, что пользователь является капитаном
-	if captainTeam.TeamRole != 2 {
+	// Проверяем, что пользователь может исключать участников
+	if !models.TeamRoleCan(captainTeam.TeamRole, models.TeamActionKick) {
 		tx.Rollback()
-		c.JSON(http.StatusForbidden, gin.H{"error": "Только капитан команды может исключать участников"})
+		c.JSON(http.StatusForbidden, gin.H{"error": "Исключать участников может только капитан или его заместитель"})
 		return
 	}
 
@@ -1867,10 +2093,10 @@ func (hc *HackathonController) KickTeam(c *gin.Context) {
 		return
 	}
 
-	// Проверяем, что исключаемый пользователь не является капитаном
-	if userToKickTeam.TeamRole == 2 {
+	// Капитана исключить нельзя, заместителя может исключить только капитан
+	if !models.TeamRoleCanKick(captainTeam.TeamRole, userToKickTeam.TeamRole) {
 		tx.Rollback()
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Нельзя исключить капитана команды"})
+		c.JSON(http.StatusForbidden, gin.H{"error": "Недостаточно прав для исключения этого участника"})
 		return
 	}
 
@@ -1946,8 +2172,24 @@ func (hc *HackathonController) GetTeamProject(c *gin.Context) {
 	// Инициализируем пустой массив файлов
 	files := []fileDTO.GetShort{}
 
-	// Если у команды есть проект, добавляем его в массив файлов
-	if team.Project != nil {
+	// Файлы итоговой версии сдачи; проекты, загруженные до появления версий, отдаются как раньше
+	submissions, err := finalSubmissions(hc.DB, []uint{team.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении версии проекта"})
+		return
+	}
+	if submission, ok := submissions[team.ID]; ok {
+		for _, artifact := range submission.Artifacts {
+			if artifact.File != nil {
+				files = append(files, fileDTO.GetShort{
+					ID:   artifact.File.ID,
+					Name: artifact.File.Name,
+					Type: artifact.File.Type,
+					Size: artifact.File.Size,
+				})
+			}
+		}
+	} else if team.Project != nil {
 		// Создаем DTO для файла проекта
 		fileDTO := fileDTO.GetShort{
 			ID:   team.Project.ID,
@@ -1962,96 +2204,19 @@ func (hc *HackathonController) GetTeamProject(c *gin.Context) {
 	c.JSON(http.StatusOK, files)
 }
 
-// UploadTeamProject загружает или обновляет проект команды на хакатоне
+// UploadTeamProject загружает или обновляет проект команды на хакатоне.
+// Каждая загрузка создаёт новую версию сдачи, в которой заменяется архив с кодом
 func (hc *HackathonController) UploadTeamProject(c *gin.Context) {
-	// Получаем ID хакатона из параметров URL
-	hackathonIDStr := c.Param("hackathon_id")
-	hackathonID, err := strconv.ParseUint(hackathonIDStr, 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
-		return
-	}
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
 
-	// Получаем данные текущего пользователя из JWT токена
-	userClaims, exists := c.Get("user_claims")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Необходима аутентификация"})
-		return
-	}
-
-	claims, ok := userClaims.(*types.Claims)
+	hackathon, link, late, ok := hc.submissionTeam(c, userID)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при извлечении данных пользователя"})
-		return
-	}
-	userID := claims.UserID
-
-	// Устанавливаем userID в контекст для FileController
-	c.Set("userID", userID)
-
-	var hackathon models.Hackathon
-	if err := hc.DB.Select("work_date_from, work_date_to").First(&hackathon, hackathonID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
-		return
-	}
-
-	// Проверяем текущую дату относительно дат работы хакатона
-	currentTime := time.Now()
-	if currentTime.Before(hackathon.WorkDateFrom) {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error":          "Загрузка проекта будет доступна только после начала работы хакатона",
-			"work_date_from": hackathon.WorkDateFrom,
-		})
-		return
-	}
-	if currentTime.After(hackathon.WorkDateTo) {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error":        "Загрузка проекта недоступна, период работы хакатона завершен",
-			"work_date_to": hackathon.WorkDateTo,
-		})
-		return
-	}
-
-	// Находим команду пользователя для данного хакатона
-	var userTeam struct {
-		TeamID uint
-	}
-	if err := hc.DB.Table("bnd_user_teams").
-		Select("team_id").
-		Joins("JOIN teams ON bnd_user_teams.team_id = teams.id").
-		Where("bnd_user_teams.user_id = ? AND teams.hackathon_id = ?", userID, hackathonID).
-		First(&userTeam).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Вы не состоите в команде на этом хакатоне"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при поиске команды"})
-		}
-		return
-	}
-
-	// Начинаем транзакцию
-	tx := hc.DB.Begin()
-	if tx.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при начале транзакции"})
-		return
-	}
-
-	// Функция для отката транзакции с возвратом ошибки
-	rollbackWithError := func(statusCode int, message string) {
-		tx.Rollback()
-		c.JSON(statusCode, gin.H{"error": message})
-	}
-
-	// Получаем информацию о команде с проектом
-	var team models.Team
-	if err := tx.Preload("Project").First(&team, userTeam.TeamID).Error; err != nil {
-		rollbackWithError(http.StatusInternalServerError, "Ошибка при получении данных команды")
 		return
 	}
 
 	// Парсим multipart форму
 	if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
-		rollbackWithError(http.StatusBadRequest, "Ошибка при парсинге формы: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка при парсинге формы: " + err.Error()})
 		return
 	}
 
@@ -2064,7 +2229,7 @@ func (hc *HackathonController) UploadTeamProject(c *gin.Context) {
 	projectDataJSON := c.Request.FormValue("data")
 	if projectDataJSON != "" {
 		if err := json.Unmarshal([]byte(projectDataJSON), &requestData); err != nil {
-			rollbackWithError(http.StatusBadRequest, "Ошибка при разборе JSON данных")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка при разборе JSON данных"})
 			return
 		}
 	}
@@ -2075,62 +2240,46 @@ func (hc *HackathonController) UploadTeamProject(c *gin.Context) {
 
 	// Проверяем, есть ли операции для выполнения
 	if !hasNewFiles && len(requestData.FilesToDelete) == 0 {
-		rollbackWithError(http.StatusBadRequest, "Не указаны файлы для загрузки или удаления")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Не указаны файлы для загрузки или удаления"})
 		return
 	}
 
-	// Обработка удаления файлов
-	if len(requestData.FilesToDelete) > 0 && team.Project != nil {
-		for _, fileID := range requestData.FilesToDelete {
-			if team.Project.ID == fileID {
-				// Удаляем файл проекта
-				if err := tx.Delete(&models.File{}, fileID).Error; err != nil {
-					rollbackWithError(http.StatusInternalServerError, "Ошибка при удалении файла проекта")
-					return
-				}
-				team.Project = nil
-				break
-			}
-		}
+	input := submissionInput{
+		Files: map[int]*multipart.FileHeader{},
+		Drop:  map[int]bool{},
 	}
-
-	// Загрузка нового проекта
 	if hasNewFiles {
-		// Если уже есть проект и мы его не удалили в предыдущем шаге, удаляем
-		if team.Project != nil {
-			oldFileID := team.Project.ID
-
-			// Удаляем старый файл (если его нет в списке filesToDelete)
-			alreadyDeleted := false
-			for _, id := range requestData.FilesToDelete {
-				if id == oldFileID {
-					alreadyDeleted = true
-					break
-				}
-			}
+		input.Files[models.ArtifactSourceArchive] = form.File["files"][0]
+	}
 
-			if !alreadyDeleted {
-				if err := tx.Delete(&models.File{}, oldFileID).Error; err != nil {
-					rollbackWithError(http.StatusInternalServerError, "Ошибка при удалении старого файла проекта")
-					return
-				}
-			}
+	// Удаление архива из текущей версии - новая версия без архива
+	if len(requestData.FilesToDelete) > 0 {
+		teams := []models.Team{{Model: gorm.Model{ID: link.TeamID}}}
+		if err := hc.DB.Preload("Project").First(&teams[0], link.TeamID).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении данных команды"})
+			return
 		}
-
-		// Загружаем файл проекта
-		newFile, err := hc.FileController.UploadFile(c, form.File["files"][0], team.ID, "team")
-		if err != nil {
-			rollbackWithError(http.StatusInternalServerError, "Ошибка при загрузке файла проекта: "+err.Error())
+		if _, err := applyFinalProjects(hc.DB, teams); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении версии проекта"})
 			return
 		}
-
-		// Обновляем проект команды
-		team.Project = newFile
+		for _, fileID := range requestData.FilesToDelete {
+			if teams[0].Project != nil && teams[0].Project.ID == fileID {
+				input.Drop[models.ArtifactSourceArchive] = true
+			}
+		}
 	}
 
-	// Применяем транзакцию
-	if err := tx.Commit().Error; err != nil {
-		rollbackWithError(http.StatusInternalServerError, "Ошибка при сохранении изменений")
+	if _, err := hc.saveSubmission(c, hackathon, link.TeamID, userID, input, late); err != nil {
+		if errors.Is(err, errSubmissionEmpty) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "В версии не осталось файлов"})
+			return
+		}
+		if errors.Is(err, errArchiveRejected) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при загрузке файла проекта: " + err.Error()})
 		return
 	}
 
@@ -2165,27 +2314,36 @@ func (hc *HackathonController) GetHackathonRole(c *gin.Context) {
 	var userHackathon models.BndUserHackathon
 	result := hc.DB.Where("user_id = ? AND hackathon_id = ?", userID, hackathonID).First(&userHackathon)
 
-	// Получаем информацию о хакатоне для определения состояний
-	var hackathon models.Hackathon
-	if err := hc.DB.First(&hackathon, hackathonID).Error; err != nil {
+	// Получаем текущий этап хакатона
+	_, state, err := hc.hackathonState(uint(hackathonID))
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
 		return
 	}
 
-	// Определяем текущие состояния хакатона
-	currentTime := time.Now()
-	isRegistration := currentTime.After(hackathon.RegDateFrom) && currentTime.Before(hackathon.RegDateTo)
-	isWork := currentTime.After(hackathon.WorkDateFrom) && currentTime.Before(hackathon.WorkDateTo)
-	isEvaluation := currentTime.After(hackathon.EvalDateFrom) && currentTime.Before(hackathon.EvalDateTo)
+	isRegistration := state.Allows(models.HackathonPhaseRegistration)
+	isWork := state.Allows(models.HackathonPhaseWork)
+	isEvaluation := state.Allows(models.HackathonPhaseEvaluation)
 
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			// Пользователь не связан с хакатоном
+			// Пользователь не связан с хакатоном, но может стоять в очереди ожидания
+			var waitlistPos int64
+			var entry models.HackathonWaitlist
+			if err := hc.DB.Where("user_id = ? AND hackathon_id = ?", userID, hackathonID).First(&entry).Error; err == nil {
+				if waitlistPos, err = waitlistPosition(hc.DB, entry); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при определении места в очереди"})
+					return
+				}
+			}
+
 			c.JSON(http.StatusOK, gin.H{
-				"role":           0,
-				"isRegistration": isRegistration,
-				"isWork":         isWork,
-				"isEvaluation":   isEvaluation,
+				"role":             0,
+				"waitlistPosition": waitlistPos,
+				"isRegistration":   isRegistration,
+				"isWork":           isWork,
+				"isEvaluation":     isEvaluation,
+				"phase":            state,
 			})
 			return
 		}
@@ -2200,6 +2358,7 @@ func (hc *HackathonController) GetHackathonRole(c *gin.Context) {
 		"isRegistration": isRegistration,
 		"isWork":         isWork,
 		"isEvaluation":   isEvaluation,
+		"phase":          state,
 	})
 }
 
@@ -2247,6 +2406,16 @@ func (hc *HackathonController) GetValidateProjects(c *gin.Context) {
 		return
 	}
 
+	// Параметр round выбирает отборочный тур, без него оценивается финал
+	round, criteria, ok := evaluationRound(c, hc.DB, uint(hackathonID))
+	if !ok {
+		return
+	}
+	var roundID uint
+	if round != nil {
+		roundID = round.ID
+	}
+
 	// Получаем все команды хакатона с проектами
 	var allTeams []models.Team
 	if err := hc.DB.Where("hackathon_id = ?", hackathonID).Preload("Project").Find(&allTeams).Error; err != nil {
@@ -2254,38 +2423,110 @@ func (hc *HackathonController) GetValidateProjects(c *gin.Context) {
 		return
 	}
 
-	// Получаем все оценки для команд хакатона
-	var allScores []models.Score
+	// Команды, выбывшие в предыдущих турах, дальше не оцениваются
+	allTeams, err = activeTeams(hc.DB, uint(hackathonID), allTeams, roundID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении итогов туров"})
+		return
+	}
+
+	// В туре оценивается версия, сданная во время тура, в финале - итоговая
+	var submissions map[uint]models.Submission
+	if round != nil {
+		roundTeamIDs := make([]uint, len(allTeams))
+		for i, team := range allTeams {
+			roundTeamIDs[i] = team.ID
+		}
+		submissions, err = roundSubmissions(hc.DB, round.ID, roundTeamIDs)
+		for i := range allTeams {
+			allTeams[i].Project = nil
+			if submission, ok := submissions[allTeams[i].ID]; ok {
+				allTeams[i].Project = submission.PrimaryFile()
+			}
+		}
+	} else {
+		submissions, err = applyFinalProjects(hc.DB, allTeams)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении версий проектов"})
+		return
+	}
+
+	// Судья видит только назначенные ему команды без конфликта интересов
+	access, err := loadJudgeAccess(hc.DB, uint(hackathonID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении назначений судей"})
+		return
+	}
+	assignedTeams := allTeams[:0]
+	for _, team := range allTeams {
+		if access.check(team.ID, userID) == "" {
+			assignedTeams = append(assignedTeams, team)
+		}
+	}
+	allTeams = assignedTeams
+
+	// Получаем оценки всех судей для команд хакатона
 	teamIDs := make([]uint, 0, len(allTeams))
 	for _, team := range allTeams {
 		teamIDs = append(teamIDs, team.ID)
 	}
 
-	if len(teamIDs) > 0 {
-		if err := hc.DB.Where("team_id IN ?", teamIDs).Find(&allScores).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении оценок"})
+	scoreTable, err := loadTeamScores(hc.DB, teamIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении оценок"})
+		return
+	}
+	scoreTable = scoreTable.only(criteria)
+
+	// Список судей нужен, чтобы показать, кто ещё не оценил команду
+	judges, err := loadHackathonJudges(hc.DB, uint(hackathonID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении списка судей"})
+		return
+	}
+
+	var hackathon models.Hackathon
+	if err := hc.DB.First(&hackathon, hackathonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return
+	}
+
+	// При слепой оценке судья видит команды только под кодами
+	blind := blindFor(hackathon, userHackathon.HackathonRole)
+
+	// Организаторы видят рядом с проектом самую похожую на него работу другой команды
+	var similarTeams map[uint]similarityDTO.Top
+	if userHackathon.HackathonRole >= 3 {
+		similarTeams, err = similarityTops(hc.DB, uint(hackathonID))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении отчёта о сходстве"})
 			return
 		}
 	}
-
-	// Создаем карту команд с оценками
-	teamsWithScores := make(map[uint]bool)
-	for _, score := range allScores {
-		teamsWithScores[score.TeamID] = true
+	if blind {
+		if err := ensureBlindCodes(hc.DB, allTeams); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обезличивании команд"})
+			return
+		}
 	}
 
-	// Фильтруем команды в соответствии с критерием
+	// Фильтруем команды по наличию оценок текущего судьи
 	var filteredTeams []models.Team
+	var scoredCount int
 
 	for _, team := range allTeams {
-		hasScores := teamsWithScores[team.ID]
+		hasScores := scoreTable.judgesScored(team.ID)[userID]
+		if hasScores {
+			scoredCount++
+		}
 
 		// Применяем фильтр
 		if filter.Validate == 1 && hasScores {
-			// Только с оценками
+			// Только оцененные мной
 			filteredTeams = append(filteredTeams, team)
 		} else if filter.Validate == -1 && !hasScores {
-			// Только без оценок
+			// Только не оцененные мной
 			filteredTeams = append(filteredTeams, team)
 		} else if filter.Validate == 0 {
 			// Все команды
@@ -2312,25 +2553,12 @@ func (hc *HackathonController) GetValidateProjects(c *gin.Context) {
 		paginatedTeams = filteredTeams[start:end]
 	}
 
-	// Получаем критерии оценки для этого хакатона
-	var criteria []models.Criteria
-	if err := hc.DB.Where("hackathon_id = ?", hackathonID).Order("id").Find(&criteria).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении критериев оценки"})
-		return
-	}
-
 	// Подсчитываем максимально возможное количество баллов
 	var maxScore uint
 	for _, criterion := range criteria {
 		maxScore += criterion.MaxScore
 	}
 
-	// Создаем карту оценок по командам
-	scoreByTeam := make(map[uint][]models.Score)
-	for _, score := range allScores {
-		scoreByTeam[score.TeamID] = append(scoreByTeam[score.TeamID], score)
-	}
-
 	// Формируем ответ в соответствии с ожидаемой структурой на фронтенде
 	var validateProjects []gin.H
 	for _, team := range paginatedTeams {
@@ -2339,42 +2567,43 @@ func (hc *HackathonController) GetValidateProjects(c *gin.Context) {
 			continue // Пропускаем команды без проектов
 		}
 
-		// Вычисляем общую оценку, если она есть
-		var summaryScore float64
+		// Собственная оценка судьи и сводная оценка всех судей
+		var summaryScore, aggregateScore float64
 		var hasScore bool
 
-		teamScores := scoreByTeam[team.ID]
+		teamScores := scoreTable[team.ID]
 
 		// Создаем персонализированные критерии для проекта
 		var projectCriteria []gin.H
 		for _, criterion := range criteria {
-			// Ищем оценку для конкретного критерия и команды
+			// Судья видит и редактирует только свою оценку
 			var value int = 0
 			var comment string = ""
 
-			for _, score := range teamScores {
-				if score.CriteriaID == criterion.ID {
-					value = int(score.Score)
-					comment = score.Comment
-					break
-				}
+			if score, ok := teamScores[criterion.ID][userID]; ok {
+				value = int(score.Score)
+				comment = score.Comment
+				summaryScore += score.Score
+				hasScore = true
+			}
+
+			values := make([]float64, 0, len(teamScores[criterion.ID]))
+			for _, score := range teamScores[criterion.ID] {
+				values = append(values, score.Score)
 			}
+			aggregated := aggregateScores(values, hackathon.ScoreAggregation)
+			aggregateScore += aggregated
 
 			criteriaInfo := gin.H{
-				"name":     criterion.Name,
-				"maxScore": criterion.MaxScore,
-				"minScore": criterion.MinScore,
-				"value":    value,
-				"comment":  comment,
+				"name":      criterion.Name,
+				"maxScore":  criterion.MaxScore,
+				"minScore":  criterion.MinScore,
+				"value":     value,
+				"comment":   comment,
+				"aggregate": aggregated,
 			}
 
 			projectCriteria = append(projectCriteria, criteriaInfo)
-
-			// Одновременно считаем общую сумму баллов
-			if value > 0 {
-				summaryScore += float64(value)
-				hasScore = true
-			}
 		}
 
 		var summaryValue interface{} = nil
@@ -2382,6 +2611,13 @@ func (hc *HackathonController) GetValidateProjects(c *gin.Context) {
 			summaryValue = summaryScore
 		}
 
+		scored := scoreTable.judgesScored(team.ID)
+
+		var aggregateValue interface{} = nil
+		if len(scored) > 0 {
+			aggregateValue = aggregateScore
+		}
+
 		// Создаем объект ValidateProject
 		projectInfo := gin.H{
 			"project": gin.H{
@@ -2390,10 +2626,40 @@ func (hc *HackathonController) GetValidateProjects(c *gin.Context) {
 				"type": team.Project.Type,
 				"size": team.Project.Size,
 			},
-			"summary":  summaryValue,
-			"teamName": team.Name,
-			"teamId":   team.ID,
-			"criteria": projectCriteria,
+			"summary":       summaryValue,
+			"aggregate":     aggregateValue,
+			"judgesScored":  len(scored),
+			"pendingJudges": pendingJudges(access.teamJudges(team.ID, judges), scored),
+			"teamName":      team.Name,
+			"teamId":        team.ID,
+			"criteria":      projectCriteria,
+		}
+		submission, hasSubmission := submissions[team.ID]
+		if hasSubmission {
+			projectInfo["submission"] = toSubmissionDTO(submission, true)
+		}
+		if top, ok := similarTeams[team.ID]; ok {
+			projectInfo["similarity"] = top
+		}
+		if blind {
+			label := blindProjectLabel
+			if hasSubmission {
+				projectInfo["submission"] = blindSubmission(toSubmissionDTO(submission, true), hackathon.ID, team.BlindCode)
+				for _, artifact := range submission.Artifacts {
+					if artifact.FileID != nil && *artifact.FileID == team.Project.ID {
+						label = blindArtifactLabels[artifact.Kind]
+					}
+				}
+			}
+			projectInfo["project"] = gin.H{
+				"id":          team.Project.ID,
+				"name":        blindFileName(team.BlindCode, label, team.Project.Name),
+				"type":        team.Project.Type,
+				"size":        team.Project.Size,
+				"downloadUrl": blindDownloadURL(hackathon.ID, team.BlindCode, team.Project.ID),
+			}
+			projectInfo["teamName"] = team.BlindCode
+			projectInfo["teamId"] = team.BlindCode
 		}
 
 		validateProjects = append(validateProjects, projectInfo)
@@ -2401,9 +2667,16 @@ func (hc *HackathonController) GetValidateProjects(c *gin.Context) {
 
 	// Убираем отдельный блок criteria, так как он теперь входит в каждый проект
 	response := gin.H{
-		"list":     validateProjects,
-		"total":    totalCount,
-		"maxScore": maxScore,
+		"list":        validateProjects,
+		"total":       totalCount,
+		"maxScore":    maxScore,
+		"aggregation": hackathon.ScoreAggregation,
+		"blind":       blind,
+		"round":       roundID,
+		"progress": gin.H{
+			"assigned": len(allTeams),
+			"scored":   scoredCount,
+		},
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -2411,8 +2684,9 @@ func (hc *HackathonController) GetValidateProjects(c *gin.Context) {
 
 func (h *HackathonController) SubmitProjectRating(c *gin.Context) {
 	// Получаем ID хакатона и команды из параметров URL
+	// При слепой оценке вместо ID команды передаётся её код
 	hackathonIDStr := c.Param("hackathon_id")
-	teamIDStr := c.Param("team_id")
+	teamRef := c.Param("team_id")
 
 	hackathonID, err := strconv.ParseUint(hackathonIDStr, 10, 32)
 	if err != nil {
@@ -2420,12 +2694,6 @@ func (h *HackathonController) SubmitProjectRating(c *gin.Context) {
 		return
 	}
 
-	teamID, err := strconv.ParseUint(teamIDStr, 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный ID команды"})
-		return
-	}
-
 	// Получаем ID текущего пользователя из контекста
 	userClaims, exists := c.Get("user_claims")
 	if !exists {
@@ -2450,24 +2718,64 @@ func (h *HackathonController) SubmitProjectRating(c *gin.Context) {
 	}
 
 	// Проверяем, существует ли команда в данном хакатоне
-	var teamCount int64
-	if err := h.DB.Model(&models.Team{}).
-		Where("id = ? AND hackathon_id = ?", teamID, hackathonID).
-		Count(&teamCount).Error; err != nil {
+	team, err := findHackathonTeam(h.DB, uint(hackathonID), teamRef)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Команда не найдена или не принадлежит данному хакатону"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке команды"})
 		return
 	}
+	teamID := team.ID
 
-	if teamCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Команда не найдена или не принадлежит данному хакатону"})
+	// Судья оценивает только назначенные ему команды и не может оценивать команды,
+	// с которыми у него конфликт интересов
+	access, err := loadJudgeAccess(h.DB, uint(hackathonID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке назначений судьи"})
+		return
+	}
+	if reason := access.check(teamID, userID); reason != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": reason})
 		return
 	}
 
-	// Получаем список критериев для хакатона
-	var criteria []models.Criteria
-	if err := h.DB.Where("hackathon_id = ?", hackathonID).
-		Order("id").Find(&criteria).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении критериев"})
+	// Критерии выбранного тура или финальной оценки
+	round, criteria, ok := evaluationRound(c, h.DB, uint(hackathonID))
+	if !ok {
+		return
+	}
+	// Финальные оценки принимаются на этапе оценки, оценки тура - с его начала и до подведения итогов:
+	// отборочные туры проходят во время работы над проектами
+	var roundID uint
+	if round == nil {
+		if !h.requirePhase(c, uint(hackathonID), models.HackathonPhaseEvaluation) {
+			return
+		}
+	} else {
+		if !h.requirePhase(c, uint(hackathonID), models.HackathonPhaseWork, models.HackathonPhaseEvaluation) {
+			return
+		}
+		if time.Now().Before(round.StartDate) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Тур ещё не начался, оценки не принимаются"})
+			return
+		}
+		if round.ClosedAt != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "Итоги тура уже подведены, оценки не принимаются"})
+			return
+		}
+		roundID = round.ID
+	}
+
+	// Выбывшую в предыдущих турах команду дальше не оценивают
+	eliminated, err := eliminatedTeams(h.DB, uint(hackathonID), roundID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении итогов туров"})
+		return
+	}
+	if stepName, out := eliminated[teamID]; out {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Команда не прошла отборочный тур «" + stepName + "»"})
 		return
 	}
 
@@ -2490,8 +2798,13 @@ func (h *HackathonController) SubmitProjectRating(c *gin.Context) {
 
 	// Валидируем оценки и сохраняем их в транзакции
 	err = h.DB.Transaction(func(tx *gorm.DB) error {
-		// Удаляем существующие оценки данного судьи для этой команды
-		if err := tx.Where("team_id = ?", teamID).
+		// Удаляем существующие оценки данного судьи для этой команды по критериям тура,
+		// оценки остальных судей и других туров остаются нетронутыми
+		criteriaIDs := make([]uint, len(criteria))
+		for i, criterion := range criteria {
+			criteriaIDs[i] = criterion.ID
+		}
+		if err := tx.Where("team_id = ? AND user_id = ? AND criteria_id IN ?", teamID, userID, criteriaIDs).
 			Delete(&models.Score{}).Error; err != nil {
 			return err
 		}
@@ -2510,8 +2823,9 @@ func (h *HackathonController) SubmitProjectRating(c *gin.Context) {
 
 			// Создаем запись оценки (используем UserID для идентификации судьи)
 			score := models.Score{
-				TeamID:     uint(teamID),
+				TeamID:     teamID,
 				CriteriaID: criterion.ID,
+				UserID:     userID,
 				Score:      float64(input.Value),
 				Comment:    input.Comment,
 			}
@@ -2535,6 +2849,9 @@ func (h *HackathonController) SubmitProjectRating(c *gin.Context) {
 	})
 }
 
+// GetResults возвращает таблицу результатов. После публикации её видят все, до публикации
+// промежуточные результаты доступны организаторам, наставникам и судьям; судьям на время
+// слепой оценки - нет, потому что в таблице видны названия команд
 func (hc *HackathonController) GetResults(c *gin.Context) {
 	// Получаем ID хакатона из параметров URL
 	hackathonIDStr := c.Param("hackathon_id")
@@ -2551,195 +2868,144 @@ func (hc *HackathonController) GetResults(c *gin.Context) {
 		return
 	}
 
-	// Получаем максимально возможный балл (сумма maxScore всех критериев)
-	var maxScore float64
-	if err := hc.DB.Model(&models.Criteria{}).
-		Where("hackathon_id = ?", hackathonID).
-		Select("COALESCE(SUM(max_score), 0)").
-		Scan(&maxScore).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при подсчете максимального балла"})
-		return
-	}
-
-	// Получаем все команды для этого хакатона
-	var teams []models.Team
-	if err := hc.DB.Where("hackathon_id = ?", hackathonID).
-		Preload("Project").
-		Find(&teams).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении команд"})
-		return
-	}
-
-	// Собираем все ID команд
-	var teamIDs []uint
-	for _, team := range teams {
-		teamIDs = append(teamIDs, team.ID)
-	}
-
-	// Структура для хранения данных соединенных таблиц
-	type ScoreWithCriteria struct {
-		TeamID       uint    `gorm:"column:team_id"`
-		ScoreID      uint    `gorm:"column:id"`
-		CriteriaID   uint    `gorm:"column:criteria_id"`
-		Score        float64 `gorm:"column:score"`
-		Comment      string  `gorm:"column:comment"`
-		CriteriaName string  `gorm:"column:name"`
-		MaxScore     uint    `gorm:"column:max_score"`
-	}
-
-	// Используем GORM для соединения таблиц
-	var scoresWithCriteria []ScoreWithCriteria
-	if err := hc.DB.Model(&models.Score{}).
-		Select("scores.team_id, scores.id, scores.criteria_id, scores.score, scores.comment, criteria.name, criteria.max_score").
-		Joins("INNER JOIN criteria ON scores.criteria_id = criteria.id").
-		Where("scores.team_id IN ?", teamIDs).
-		Scan(&scoresWithCriteria).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении оценок: " + err.Error()})
-		return
-	}
-
-	// Группируем оценки по командам
-	teamScoresMap := make(map[uint][]ScoreWithCriteria)
-	for _, score := range scoresWithCriteria {
-		teamScoresMap[score.TeamID] = append(teamScoresMap[score.TeamID], score)
-	}
+	// После публикации все видят зафиксированную таблицу
+	if hackathon.ResultsPublished() {
+		list, tracks, err := loadPublishedResults(hc.DB, hackathon.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении результатов", "details": err.Error()})
+			return
+		}
 
-	// Рассчитываем суммарные баллы для каждой команды
-	type TeamWithScore struct {
-		ID    uint
-		Name  string
-		Score float64
-	}
+		criteria, err := mainCriteria(hc.DB, hackathon.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении критериев оценки"})
+			return
+		}
+		formula := hackathonFormula(hackathon, criteria)
 
-	var teamsWithScores []TeamWithScore
-	for _, team := range teams {
-		var totalScore float64
-		for _, score := range teamScoresMap[team.ID] {
-			totalScore += score.Score
+		rounds, err := loadRoundResults(hc.DB, hackathon, false)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении результатов туров", "details": err.Error()})
+			return
 		}
 
-		teamsWithScores = append(teamsWithScores, TeamWithScore{
-			ID:    team.ID,
-			Name:  team.Name,
-			Score: totalScore,
+		c.JSON(http.StatusOK, gin.H{
+			"list":        list,
+			"tracks":      tracks,
+			"rounds":      rounds,
+			"maxScore":    formula.maxTotal(criteria),
+			"aggregation": formula.Aggregation,
+			"preview":     false,
+			"published":   true,
+			"publishedAt": hackathon.ResultsPublishedAt,
 		})
+		return
 	}
 
-	// Сортируем команды по убыванию баллов
-	sort.Slice(teamsWithScores, func(i, j int) bool {
-		return teamsWithScores[i].Score > teamsWithScores[j].Score
-	})
-
-	// Определяем место каждой команды
-	teamPlaces := make(map[uint]int)
-	currentPlace := 1
-	previousScore := -1.0
-
-	for _, team := range teamsWithScores {
-		if previousScore != team.Score {
-			currentPlace = len(teamPlaces) + 1
-			previousScore = team.Score
-		}
-		teamPlaces[team.ID] = currentPlace
+	// До публикации промежуточные результаты участникам не показываются
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+	var userHackathon models.BndUserHackathon
+	if err := hc.DB.Where("user_id = ? AND hackathon_id = ?", userID, hackathon.ID).First(&userHackathon).Error; err != nil || userHackathon.HackathonRole < 2 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Результаты ещё не опубликованы"})
+		return
 	}
-
-	// Получаем все награды для этого хакатона
-	var awards []models.Award
-	if err := hc.DB.Where("hackathon_id = ?", hackathonID).Find(&awards).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении наград"})
+	if blindFor(hackathon, userHackathon.HackathonRole) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Промежуточные результаты скрыты на время слепой оценки"})
 		return
 	}
 
-	// Создаем маппинг для быстрого поиска награды по месту
-	awardsByPlace := make(map[int]models.Award)
-	for _, award := range awards {
-		for place := award.PlaceFrom; place <= award.PlaceTo; place++ {
-			awardsByPlace[place] = award
-		}
-	}
+	hc.respondResults(c, hackathon, nil)
+}
 
-	// Создаем мапу баллов команд для быстрого доступа
-	teamScoresTotal := make(map[uint]float64)
-	for _, team := range teamsWithScores {
-		teamScoresTotal[team.ID] = team.Score
+// PreviewResults пересчитывает таблицу результатов по предложенной формуле, ничего не сохраняя
+func (hc *HackathonController) PreviewResults(c *gin.Context) {
+	hackathonIDStr := c.Param("hackathon_id")
+	hackathonID, err := strconv.ParseUint(hackathonIDStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
 	}
 
-	// Формируем ответ
-	type AwardInfo struct {
-		MoneyAmount  float64 `json:"moneyAmount"`
-		Additionally string  `json:"additionally"`
+	var dto hackathonDTO.ResultsPreview
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
 	}
 
-	type CriteriaScore struct {
-		Name     string  `json:"name"`
-		MaxScore uint    `json:"maxScore"`
-		Score    float64 `json:"score"`
-		Comment  string  `json:"comment"`
+	validate := validator.New()
+	if err := validate.Struct(dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка валидации: " + err.Error()})
+		return
 	}
 
-	type Result struct {
-		TeamName string            `json:"teamName"`
-		Score    float64           `json:"score"`
-		Project  *fileDTO.GetShort `json:"project,omitempty"`
-		Award    *AwardInfo        `json:"award,omitempty"`
-		Criteria []CriteriaScore   `json:"criteria"`
+	var hackathon models.Hackathon
+	if err := hc.DB.First(&hackathon, hackathonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return
 	}
 
-	// Формируем массив результатов
-	results := make([]Result, 0, len(teams))
-	for _, team := range teams {
-		// Получаем оценки для текущей команды
-		teamScores := teamScoresMap[team.ID]
+	hc.respondResults(c, hackathon, &dto)
+}
 
-		criteriaScores := make([]CriteriaScore, 0, len(teamScores))
+// respondResults считает результаты хакатона и отдаёт их клиенту.
+// Если передан preview, сохранённая формула подменяется предложенной
+func (hc *HackathonController) respondResults(c *gin.Context, hackathon models.Hackathon, preview *hackathonDTO.ResultsPreview) {
+	// Получаем критерии финальной оценки хакатона
+	criteria, err := mainCriteria(hc.DB, hackathon.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении критериев оценки"})
+		return
+	}
 
-		// Перебираем все оценки команды
-		for _, score := range teamScores {
-			criteriaScores = append(criteriaScores, CriteriaScore{
-				Name:     score.CriteriaName,
-				MaxScore: score.MaxScore,
-				Score:    score.Score,
-				Comment:  score.Comment,
-			})
+	formula := hackathonFormula(hackathon, criteria)
+	if preview != nil {
+		if preview.Aggregation != nil {
+			formula.Aggregation = *preview.Aggregation
 		}
-
-		result := Result{
-			TeamName: team.Name,
-			Score:    teamScoresTotal[team.ID],
-			Criteria: criteriaScores,
+		if preview.VotingWeight != nil && hackathon.VotingMode != models.VotingOff {
+			formula.VotingWeight = *preview.VotingWeight
 		}
-
-		// Добавляем информацию о проекте, если он есть
-		if team.Project != nil {
-			result.Project = &fileDTO.GetShort{
-				ID:   team.Project.ID,
-				Name: team.Project.Name,
-				Size: team.Project.Size,
-				Type: team.Project.Type,
+		for _, candidate := range preview.Criteria {
+			rule, ok := formula.Criteria[candidate.ID]
+			if !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Критерий %d не относится к хакатону", candidate.ID)})
+				return
 			}
-		}
-
-		// Добавляем информацию о награде, если команда в призовых местах
-		if place, exists := teamPlaces[team.ID]; exists {
-			if award, hasAward := awardsByPlace[place]; hasAward {
-				result.Award = &AwardInfo{
-					MoneyAmount:  award.MoneyAmount,
-					Additionally: award.Additionally,
-				}
+			if candidate.Weight != nil {
+				rule.Weight = *candidate.Weight
+			}
+			if candidate.Normalization != nil {
+				rule.Normalization = *candidate.Normalization
 			}
+			formula.Criteria[candidate.ID] = rule
 		}
+	}
 
-		results = append(results, result)
+	// Предложенная формула влияет только на общий рейтинг, номинации считаются по сохранённой
+	table, tracks, err := computeResults(hc.DB, hackathon, criteria, formula)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при подсчёте результатов", "details": err.Error()})
+		return
 	}
 
-	// Сортируем результаты по баллам (убывание)
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].Score > results[j].Score
-	})
+	// Незакрытые туры считаются по текущим оценкам
+	rounds, err := loadRoundResults(hc.DB, hackathon, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при подсчёте результатов туров", "details": err.Error()})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"list":     results,
-		"maxScore": maxScore,
+		"list":        table.List,
+		"tracks":      tracks,
+		"rounds":      rounds,
+		"maxScore":    table.MaxScore,
+		"aggregation": formula.Aggregation,
+		"judges":      table.Judges,
+		"ties":        table.Ties,
+		"votesHidden": table.VotesHidden,
+		"preview":     preview != nil,
+		"published":   hackathon.ResultsPublished(),
 	})
 }
 
@@ -2805,7 +3071,7 @@ func (hc *HackathonController) InviteUser(c *gin.Context) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Вы не участвуете в данном хакатоне"})
 		return
 	}
-	fmt.Println("Текущий пользователь участвует в хакатоне: UserID=%d, HackathonID=%d", currentUserHackathon.UserID, currentUserHackathon.HackathonID)
+	log.Printf("Текущий пользователь участвует в хакатоне: UserID=%d, HackathonID=%d", currentUserHackathon.UserID, currentUserHackathon.HackathonID)
 
 	// Проверка, участвует ли целевой пользователь в хакатоне
 	var targetUserHackathon models.BndUserHackathon
@@ -2814,7 +3080,7 @@ func (hc *HackathonController) InviteUser(c *gin.Context) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Приглашаемый пользователь не участвует в хакатоне"})
 		return
 	}
-	fmt.Println("Целевой пользователь участвует в хакатоне: UserID=%d, HackathonID=%d", targetUserHackathon.UserID, targetUserHackathon.HackathonID)
+	log.Printf("Целевой пользователь участвует в хакатоне: UserID=%d, HackathonID=%d", targetUserHackathon.UserID, targetUserHackathon.HackathonID)
 
 	// Находим команду текущего пользователя в этом хакатоне
 	var userTeamInfo struct {
@@ -2827,7 +3093,7 @@ func (hc *HackathonController) InviteUser(c *gin.Context) {
 		Joins("JOIN teams ON bnd_user_teams.team_id = teams.id").
 		Where("bnd_user_teams.user_id = ? AND teams.hackathon_id = ?", claims.UserID, hackathonID)
 
-	fmt.Println("SQL запрос для поиска команды: %v", teamQuery.Statement.SQL.String())
+	log.Printf("SQL запрос для поиска команды: %v", teamQuery.Statement.SQL.String())
 
 	if err := teamQuery.First(&userTeamInfo).Error; err != nil {
 		log.Printf("Ошибка: текущий пользователь не состоит в команде хакатона: %v", err)
@@ -2836,13 +3102,24 @@ func (hc *HackathonController) InviteUser(c *gin.Context) {
 	}
 	log.Printf("Найдена команда пользователя: TeamID=%d, TeamRole=%d", userTeamInfo.TeamID, userTeamInfo.TeamRole)
 
-	// Проверка, является ли текущий пользователь капитаном команды
-	if userTeamInfo.TeamRole != 2 {
-		log.Printf("Ошибка: пользователь не является капитаном команды (роль=%d)", userTeamInfo.TeamRole)
-		c.JSON(http.StatusForbidden, gin.H{"error": "Только капитан команды может отправлять приглашения"})
+	// Проверка, может ли текущий пользователь приглашать в команду
+	if !models.TeamRoleCan(userTeamInfo.TeamRole, models.TeamActionInvite) {
+		log.Printf("Ошибка: у пользователя нет права приглашать в команду (роль=%d)", userTeamInfo.TeamRole)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Приглашать в команду может только капитан или его заместитель"})
+		return
+	}
+	log.Printf("Пользователь может приглашать в команду")
+
+	// Проверка, есть ли в команде свободные места
+	full, err := teamIsFull(hc.DB, hackathon, userTeamInfo.TeamID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке размера команды"})
+		return
+	}
+	if full {
+		c.JSON(http.StatusConflict, gin.H{"error": "В команде нет свободных мест", "max_team_size": hackathon.MaxTeamSize})
 		return
 	}
-	log.Printf("Пользователь является капитаном команды")
 
 	// Проверка, есть ли у приглашаемого пользователя уже команда для этого хакатона
 	var targetUserTeam models.BndUserTeam
@@ -2921,23 +3198,61 @@ func (hc *HackathonController) AcceptTeamInvite(c *gin.Context) {
 		return
 	}
 
-	// Добавление пользователя в команду
-	teamID := invite.TeamID
-	bndUserTeam := models.BndUserTeam{
-		UserID:   userID,
-		TeamID:   teamID,
-		TeamRole: 1, // Предположим, что 1 - это роль участника команды
+	// Вступить в команду можно только во время регистрации и работы над проектами
+	var team models.Team
+	if err := hc.DB.Select("id, hackathon_id").First(&team, invite.TeamID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Команда не найдена"})
+		return
 	}
-
-	if err := hc.DB.Create(&bndUserTeam).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при добавлении пользователя в команду", "details": err.Error()})
+	if !hc.requirePhase(c, team.HackathonID, models.HackathonPhaseRegistration, models.HackathonPhaseWork) {
 		return
 	}
 
-	// Обновление статуса приглашения
-	invite.Status = 1 // Статус 1 для принятого приглашения
-	if err := hc.DB.Save(&invite).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении статуса приглашения", "details": err.Error()})
+	err := hc.DB.Transaction(func(tx *gorm.DB) error {
+		// Блокируем команду, чтобы параллельные принятия приглашений не превысили лимит
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&team, team.ID).Error; err != nil {
+			return err
+		}
+
+		var hackathon models.Hackathon
+		if err := tx.First(&hackathon, team.HackathonID).Error; err != nil {
+			return err
+		}
+
+		full, err := teamIsFull(tx, hackathon, team.ID)
+		if err != nil {
+			return err
+		}
+		if full {
+			return errTeamFull
+		}
+
+		// Добавление пользователя в команду
+		bndUserTeam := models.BndUserTeam{
+			UserID:   userID,
+			TeamID:   team.ID,
+			TeamRole: models.TeamRoleMember,
+		}
+		if err := tx.Create(&bndUserTeam).Error; err != nil {
+			return err
+		}
+
+		// Обновление статуса приглашения
+		invite.Status = 1 // Статус 1 для принятого приглашения
+		if err := tx.Save(&invite).Error; err != nil {
+			return err
+		}
+
+		// Остальные заявки и приглашения пользователя в этом хакатоне больше не актуальны
+		return cleanupPendingMembership(tx, userID, team.HackathonID)
+	})
+
+	if err != nil {
+		if errors.Is(err, errTeamFull) {
+			c.JSON(http.StatusConflict, gin.H{"error": "В команде нет свободных мест"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при добавлении пользователя в команду", "details": err.Error()})
 		return
 	}
 
@@ -0,0 +1,490 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"net/http"
+	"server/models"
+	"server/models/DTO/judgeDTO"
+	"sort"
+	"strconv"
+)
+
+var errJudgeConflict = errors.New("конфликт интересов")
+
+// judgeAccess - распределение судей по командам и конфликты интересов хакатона
+type judgeAccess struct {
+	assigned  map[uint]map[uint]bool   // команда -> назначенные судьи
+	auto      map[uint]map[uint]bool   // команда -> судьи, назначенные автоматически
+	conflicts map[uint]map[uint]string // команда -> судья -> причина конфликта
+	list      []judgeDTO.Conflict
+}
+
+// loadJudgeAccess загружает назначения и конфликты интересов. Кроме заявленных
+// организатором конфликтов учитываются сообщения судьи в чате команды
+func loadJudgeAccess(db *gorm.DB, hackathonID uint) (judgeAccess, error) {
+	access := judgeAccess{
+		assigned:  map[uint]map[uint]bool{},
+		auto:      map[uint]map[uint]bool{},
+		conflicts: map[uint]map[uint]string{},
+		list:      []judgeDTO.Conflict{},
+	}
+
+	var assignments []models.JudgeAssignment
+	if err := db.Where("hackathon_id = ?", hackathonID).Find(&assignments).Error; err != nil {
+		return access, err
+	}
+	for _, assignment := range assignments {
+		if access.assigned[assignment.TeamID] == nil {
+			access.assigned[assignment.TeamID] = map[uint]bool{}
+			access.auto[assignment.TeamID] = map[uint]bool{}
+		}
+		access.assigned[assignment.TeamID][assignment.UserID] = true
+		access.auto[assignment.TeamID][assignment.UserID] = assignment.Auto
+	}
+
+	var declared []models.JudgeConflict
+	if err := db.Preload("User").Preload("Team").Where("hackathon_id = ?", hackathonID).Find(&declared).Error; err != nil {
+		return access, err
+	}
+	for _, conflict := range declared {
+		access.add(judgeDTO.Conflict{
+			ID:       conflict.ID,
+			UserID:   conflict.UserID,
+			Username: conflict.User.Username,
+			TeamID:   conflict.TeamID,
+			TeamName: conflict.Team.Name,
+			Reason:   conflict.Reason,
+		})
+	}
+
+	// Ментор, писавший в чат команды, помогал ей и не может её оценивать
+	var chatted []judgeDTO.Conflict
+	if err := db.Table("chat_messages").
+		Select("DISTINCT chat_messages.user_id, users.username, chats.team_id, teams.name AS team_name").
+		Joins("JOIN chats ON chats.id = chat_messages.chat_id AND chats.deleted_at IS NULL").
+		Joins("JOIN teams ON teams.id = chats.team_id AND teams.deleted_at IS NULL").
+		Joins("JOIN users ON users.id = chat_messages.user_id").
+		Joins("JOIN bnd_user_hackathons ON bnd_user_hackathons.user_id = chat_messages.user_id AND bnd_user_hackathons.hackathon_id = chats.hackathon_id").
+		Where("chats.hackathon_id = ? AND chats.type = 3 AND chat_messages.deleted_at IS NULL", hackathonID).
+		Where("bnd_user_hackathons.hackathon_role IN ?", []int{2, 3}).
+		Scan(&chatted).Error; err != nil {
+		return access, err
+	}
+	for _, conflict := range chatted {
+		conflict.Reason = "писал в чате команды"
+		conflict.Auto = true
+		access.add(conflict)
+	}
+
+	return access, nil
+}
+
+func (a judgeAccess) add(conflict judgeDTO.Conflict) {
+	if a.conflicts[conflict.TeamID] == nil {
+		a.conflicts[conflict.TeamID] = map[uint]string{}
+	}
+	if _, exists := a.conflicts[conflict.TeamID][conflict.UserID]; exists {
+		return
+	}
+	a.conflicts[conflict.TeamID][conflict.UserID] = conflict.Reason
+	a.list = append(a.list, conflict)
+}
+
+// enabled - в хакатоне распределены судьи; иначе каждый судья оценивает все команды
+func (a judgeAccess) enabled() bool {
+	return len(a.assigned) > 0
+}
+
+// check возвращает причину, по которой судья не может оценивать команду, или пустую строку
+func (a judgeAccess) check(teamID, userID uint) string {
+	if reason, ok := a.conflicts[teamID][userID]; ok {
+		return "Конфликт интересов: " + reason
+	}
+	if a.enabled() && !a.assigned[teamID][userID] {
+		return "Вы не назначены судьёй этой команды"
+	}
+	return ""
+}
+
+// teamJudges возвращает судей, которые должны оценить команду
+func (a judgeAccess) teamJudges(teamID uint, judges []hackathonJudge) []hackathonJudge {
+	result := make([]hackathonJudge, 0, len(judges))
+	for _, judge := range judges {
+		if a.check(teamID, judge.ID) == "" {
+			result = append(result, judge)
+		}
+	}
+	return result
+}
+
+// GetJudgeAssignments возвращает распределение судей, их нагрузку и конфликты интересов
+func (hc *HackathonController) GetJudgeAssignments(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	hc.respondJudgeAssignments(c, uint(hackathonID), nil)
+}
+
+func (hc *HackathonController) respondJudgeAssignments(c *gin.Context, hackathonID uint, understaffed []uint) {
+	var hackathon models.Hackathon
+	if err := hc.DB.First(&hackathon, hackathonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return
+	}
+
+	var teams []models.Team
+	if err := hc.DB.Where("hackathon_id = ?", hackathonID).Order("id").Find(&teams).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении команд"})
+		return
+	}
+
+	judges, err := loadHackathonJudges(hc.DB, hackathonID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении списка судей"})
+		return
+	}
+
+	access, err := loadJudgeAccess(hc.DB, hackathonID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении назначений", "details": err.Error()})
+		return
+	}
+
+	teamIDs := make([]uint, len(teams))
+	for i, team := range teams {
+		teamIDs[i] = team.ID
+	}
+	scoreTable, err := loadTeamScores(hc.DB, teamIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении оценок"})
+		return
+	}
+
+	loads := make(map[uint]*judgeDTO.JudgeLoad, len(judges))
+	response := judgeDTO.AssignmentsResponse{
+		JudgesPerTeam: hackathon.JudgesPerTeam,
+		Teams:         make([]judgeDTO.TeamAssignments, 0, len(teams)),
+		Judges:        make([]judgeDTO.JudgeLoad, len(judges)),
+		Conflicts:     access.list,
+		Understaffed:  understaffed,
+	}
+	for i, judge := range judges {
+		response.Judges[i] = judgeDTO.JudgeLoad{ID: judge.ID, Username: judge.Username}
+		loads[judge.ID] = &response.Judges[i]
+	}
+	if response.Understaffed == nil {
+		response.Understaffed = []uint{}
+	}
+
+	for _, team := range teams {
+		scored := scoreTable.judgesScored(team.ID)
+		item := judgeDTO.TeamAssignments{
+			TeamID:   team.ID,
+			TeamName: team.Name,
+			Required: hackathon.JudgesPerTeam,
+			Judges:   []judgeDTO.AssignedJudge{},
+		}
+		for _, judge := range judges {
+			if !access.assigned[team.ID][judge.ID] {
+				continue
+			}
+			item.Judges = append(item.Judges, judgeDTO.AssignedJudge{
+				ID:       judge.ID,
+				Username: judge.Username,
+				Auto:     access.auto[team.ID][judge.ID],
+				Scored:   scored[judge.ID],
+			})
+			loads[judge.ID].Assigned++
+			if scored[judge.ID] {
+				loads[judge.ID].Scored++
+			}
+		}
+		response.Teams = append(response.Teams, item)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SetTeamJudges вручную задаёт состав судей команды
+func (hc *HackathonController) SetTeamJudges(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	var dto judgeDTO.AssignmentSet
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка валидации", "details": err.Error()})
+		return
+	}
+
+	var team models.Team
+	if err := hc.DB.Where("id = ? AND hackathon_id = ?", c.Param("team_id"), hackathonID).First(&team).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Команда не найдена"})
+		return
+	}
+
+	judges, err := loadHackathonJudges(hc.DB, uint(hackathonID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении списка судей"})
+		return
+	}
+	isJudge := make(map[uint]bool, len(judges))
+	for _, judge := range judges {
+		isJudge[judge.ID] = true
+	}
+
+	access, err := loadJudgeAccess(hc.DB, uint(hackathonID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке конфликтов", "details": err.Error()})
+		return
+	}
+
+	selected := uniqueIDs(dto.Judges)
+	for judgeID := range selected {
+		if !isJudge[judgeID] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Пользователь не является судьёй хакатона", "user_id": judgeID})
+			return
+		}
+		if reason, ok := access.conflicts[team.ID][judgeID]; ok {
+			c.JSON(http.StatusConflict, gin.H{"error": "Конфликт интересов: " + reason, "user_id": judgeID})
+			return
+		}
+	}
+
+	err = hc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("team_id = ?", team.ID).Delete(&models.JudgeAssignment{}).Error; err != nil {
+			return err
+		}
+		for judgeID := range selected {
+			if err := tx.Create(&models.JudgeAssignment{
+				HackathonID: uint(hackathonID),
+				TeamID:      team.ID,
+				UserID:      judgeID,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении назначений", "details": err.Error()})
+		return
+	}
+
+	hc.respondJudgeAssignments(c, uint(hackathonID), nil)
+}
+
+// AutoAssignJudges распределяет судей так, чтобы каждую команду оценивали N судей
+// без конфликтов интересов, а нагрузка на судей была равномерной
+func (hc *HackathonController) AutoAssignJudges(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	var dto judgeDTO.AutoAssign
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка валидации", "details": err.Error()})
+		return
+	}
+
+	var understaffed []uint
+	err = hc.DB.Transaction(func(tx *gorm.DB) error {
+		hackathon, err := lockHackathon(tx, uint(hackathonID))
+		if err != nil {
+			return err
+		}
+
+		if dto.Replace {
+			if err := tx.Unscoped().Where("hackathon_id = ? AND auto = ?", hackathon.ID, true).Delete(&models.JudgeAssignment{}).Error; err != nil {
+				return err
+			}
+		}
+
+		var teams []models.Team
+		if err := tx.Where("hackathon_id = ?", hackathon.ID).Order("id").Find(&teams).Error; err != nil {
+			return err
+		}
+
+		judges, err := loadHackathonJudges(tx, hackathon.ID)
+		if err != nil {
+			return err
+		}
+
+		access, err := loadJudgeAccess(tx, hackathon.ID)
+		if err != nil {
+			return err
+		}
+
+		load := make(map[uint]int, len(judges))
+		for _, byJudge := range access.assigned {
+			for judgeID := range byJudge {
+				load[judgeID]++
+			}
+		}
+
+		// Команды по очереди получают по одному судье: сначала те, у кого судей меньше
+		pending := make([]uint, 0, len(teams))
+		for _, team := range teams {
+			if len(access.assigned[team.ID]) < dto.JudgesPerTeam {
+				pending = append(pending, team.ID)
+			}
+		}
+
+		for len(pending) > 0 {
+			sort.SliceStable(pending, func(i, j int) bool {
+				return len(access.assigned[pending[i]]) < len(access.assigned[pending[j]])
+			})
+			teamID := pending[0]
+
+			var best *hackathonJudge
+			for i := range judges {
+				judge := &judges[i]
+				if access.assigned[teamID][judge.ID] || access.conflicts[teamID][judge.ID] != "" {
+					continue
+				}
+				if best == nil || load[judge.ID] < load[best.ID] {
+					best = judge
+				}
+			}
+
+			if best == nil {
+				understaffed = append(understaffed, teamID)
+				pending = pending[1:]
+				continue
+			}
+
+			if err := tx.Create(&models.JudgeAssignment{
+				HackathonID: hackathon.ID,
+				TeamID:      teamID,
+				UserID:      best.ID,
+				Auto:        true,
+			}).Error; err != nil {
+				return err
+			}
+			if access.assigned[teamID] == nil {
+				access.assigned[teamID] = map[uint]bool{}
+			}
+			access.assigned[teamID][best.ID] = true
+			load[best.ID]++
+
+			if len(access.assigned[teamID]) >= dto.JudgesPerTeam {
+				pending = pending[1:]
+			}
+		}
+
+		return tx.Model(&hackathon).Update("judges_per_team", dto.JudgesPerTeam).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при распределении судей", "details": err.Error()})
+		return
+	}
+
+	hc.respondJudgeAssignments(c, uint(hackathonID), understaffed)
+}
+
+// CreateJudgeConflict заявляет конфликт интересов судьи и команды;
+// существующее назначение судьи на эту команду снимается
+func (hc *HackathonController) CreateJudgeConflict(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	var dto judgeDTO.ConflictCreate
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка валидации", "details": err.Error()})
+		return
+	}
+
+	var team models.Team
+	if err := hc.DB.Where("id = ? AND hackathon_id = ?", dto.TeamID, hackathonID).First(&team).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Команда не найдена"})
+		return
+	}
+
+	var judge models.BndUserHackathon
+	if err := hc.DB.Where("user_id = ? AND hackathon_id = ?", dto.UserID, hackathonID).First(&judge).Error; err != nil || (judge.HackathonRole != 2 && judge.HackathonRole != 3) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Пользователь не является судьёй хакатона"})
+		return
+	}
+
+	err = hc.DB.Transaction(func(tx *gorm.DB) error {
+		var existing models.JudgeConflict
+		if err := tx.Where("team_id = ? AND user_id = ?", team.ID, dto.UserID).First(&existing).Error; err == nil {
+			return errJudgeConflict
+		}
+
+		if err := tx.Create(&models.JudgeConflict{
+			HackathonID: uint(hackathonID),
+			TeamID:      team.ID,
+			UserID:      dto.UserID,
+			Reason:      dto.Reason,
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Where("team_id = ? AND user_id = ?", team.ID, dto.UserID).Delete(&models.JudgeAssignment{}).Error
+	})
+	if err != nil {
+		if errors.Is(err, errJudgeConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Конфликт интересов уже заявлен"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении конфликта", "details": err.Error()})
+		return
+	}
+
+	hc.respondJudgeAssignments(c, uint(hackathonID), nil)
+}
+
+// DeleteJudgeConflict снимает заявленный организатором конфликт интересов
+func (hc *HackathonController) DeleteJudgeConflict(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	result := hc.DB.Unscoped().
+		Where("id = ? AND hackathon_id = ?", c.Param("conflict_id"), hackathonID).
+		Delete(&models.JudgeConflict{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении конфликта"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Конфликт не найден"})
+		return
+	}
+
+	hc.respondJudgeAssignments(c, uint(hackathonID), nil)
+}
@@ -0,0 +1,312 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"server/models"
+	"server/models/DTO/recommendationDTO"
+	"server/models/DTO/technologyDTO"
+	"server/types"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// techSet - множество технологий: ID -> название
+type techSet map[uint]string
+
+func (s techSet) add(other techSet) {
+	for id, name := range other {
+		s[id] = name
+	}
+}
+
+// minus возвращает технологии s, которых нет в other
+func (s techSet) minus(other techSet) techSet {
+	result := techSet{}
+	for id, name := range s {
+		if _, ok := other[id]; !ok {
+			result[id] = name
+		}
+	}
+	return result
+}
+
+// within оставляет только технологии из target; пустой target означает «любые»
+func (s techSet) within(target techSet) techSet {
+	if len(target) == 0 {
+		return s
+	}
+	result := techSet{}
+	for id, name := range s {
+		if _, ok := target[id]; ok {
+			result[id] = name
+		}
+	}
+	return result
+}
+
+func (s techSet) short() []technologyDTO.GetShort {
+	result := make([]technologyDTO.GetShort, 0, len(s))
+	for id, name := range s {
+		result = append(result, technologyDTO.GetShort{ID: id, Name: name})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+func (s techSet) names() string {
+	short := s.short()
+	names := make([]string, len(short))
+	for i, technology := range short {
+		names[i] = technology.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// userTechnologies загружает технологии пользователей, сгруппированные по пользователю
+func userTechnologies(db *gorm.DB, userIDs []uint) (map[uint]techSet, error) {
+	result := make(map[uint]techSet, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		UserID uint
+		ID     uint
+		Name   string
+	}
+	if err := db.Table("user_technologies").
+		Select("user_technologies.user_id, technologies.id, technologies.name").
+		Joins("JOIN technologies ON technologies.id = user_technologies.technology_id AND technologies.deleted_at IS NULL").
+		Where("user_technologies.user_id IN ?", userIDs).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		if result[row.UserID] == nil {
+			result[row.UserID] = techSet{}
+		}
+		result[row.UserID][row.ID] = row.Name
+	}
+	return result, nil
+}
+
+// GetRecommendations подбирает команды свободному участнику или участников капитану
+// по технологиям хакатона. Кандидаты без пользы по технологиям не предлагаются
+func (hc *HackathonController) GetRecommendations(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор хакатона"})
+		return
+	}
+
+	var filter recommendationDTO.Filter
+	if err := c.ShouldBindJSON(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат фильтров"})
+		return
+	}
+
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	var hackathon models.Hackathon
+	if err := hc.DB.Preload("Technologies").First(&hackathon, hackathonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return
+	}
+
+	target := techSet{}
+	for _, technology := range hackathon.Technologies {
+		target[technology.ID] = technology.Name
+	}
+
+	var participant models.BndUserHackathon
+	if err := hc.DB.Where("user_id = ? AND hackathon_id = ?", userID, hackathon.ID).First(&participant).Error; err != nil || participant.HackathonRole != 1 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Рекомендации доступны только участникам хакатона"})
+		return
+	}
+
+	var response recommendationDTO.ListResponse
+	link, err := userTeamInHackathon(hc.DB, userID, hackathon.ID)
+	switch {
+	case err != nil:
+		response, err = hc.recommendTeams(hackathon, target, userID)
+	case models.TeamRoleCan(link.TeamRole, models.TeamActionInvite):
+		response, err = hc.recommendParticipants(hackathon, target, link.TeamID)
+	default:
+		c.JSON(http.StatusForbidden, gin.H{"error": "Подбор участников доступен только капитану и его заместителям"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при подборе рекомендаций", "details": err.Error()})
+		return
+	}
+
+	// Сначала самые полезные кандидаты, при равенстве - по алфавиту
+	sort.SliceStable(response.List, func(i, j int) bool {
+		if response.List[i].Score != response.List[j].Score {
+			return response.List[i].Score > response.List[j].Score
+		}
+		return response.List[i].Name < response.List[j].Name
+	})
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	response.Total = len(response.List)
+	response.Limit = limit
+	response.Offset = offset
+	if offset > len(response.List) {
+		offset = len(response.List)
+	}
+	end := offset + limit
+	if end > len(response.List) {
+		end = len(response.List)
+	}
+	response.List = response.List[offset:end]
+
+	c.JSON(http.StatusOK, response)
+}
+
+// recommendTeams подбирает свободному участнику команды со свободными местами,
+// которые дополняют его технологии, а он - их
+func (hc *HackathonController) recommendTeams(hackathon models.Hackathon, target techSet, userID uint) (recommendationDTO.ListResponse, error) {
+	response := recommendationDTO.ListResponse{Mode: recommendationDTO.ModeTeams, List: []recommendationDTO.Item{}}
+
+	var teams []models.Team
+	if err := hc.DB.Preload("Users").Where("hackathon_id = ?", hackathon.ID).Find(&teams).Error; err != nil {
+		return response, err
+	}
+
+	userIDs := []uint{userID}
+	for _, team := range teams {
+		for _, member := range team.Users {
+			userIDs = append(userIDs, member.UserID)
+		}
+	}
+
+	skills, err := userTechnologies(hc.DB, userIDs)
+	if err != nil {
+		return response, err
+	}
+
+	mine := skills[userID]
+	if mine == nil {
+		mine = techSet{}
+	}
+	response.Missing = target.minus(mine).short()
+
+	for _, team := range teams {
+		size := int64(len(team.Users))
+		if hackathon.MaxTeamSize > 0 && size >= int64(hackathon.MaxTeamSize) {
+			continue
+		}
+
+		combined := techSet{}
+		for _, member := range team.Users {
+			combined.add(skills[member.UserID])
+		}
+
+		covers := combined.minus(mine).within(target)
+		brings := mine.minus(combined).within(target)
+		score := len(covers) + len(brings)
+		if score == 0 {
+			continue
+		}
+
+		var explanation []string
+		if len(covers) > 0 {
+			explanation = append(explanation, "покрывает "+covers.names())
+		}
+		if len(brings) > 0 {
+			explanation = append(explanation, "вы закрываете "+brings.names())
+		}
+
+		response.List = append(response.List, recommendationDTO.Item{
+			ID:          team.ID,
+			Name:        team.Name,
+			Score:       score,
+			Covers:      covers.short(),
+			Brings:      brings.short(),
+			Explanation: strings.Join(explanation, "; "),
+			Size:        size,
+			MaxSize:     hackathon.MaxTeamSize,
+		})
+	}
+
+	return response, nil
+}
+
+// recommendParticipants подбирает капитану или заместителю свободных участников, владеющих
+// технологиями хакатона, которых не хватает команде
+func (hc *HackathonController) recommendParticipants(hackathon models.Hackathon, target techSet, teamID uint) (recommendationDTO.ListResponse, error) {
+	response := recommendationDTO.ListResponse{Mode: recommendationDTO.ModeParticipants, List: []recommendationDTO.Item{}}
+
+	var members []models.BndUserTeam
+	if err := hc.DB.Where("team_id = ?", teamID).Find(&members).Error; err != nil {
+		return response, err
+	}
+
+	var free []models.User
+	if err := hc.DB.Model(&models.User{}).
+		Joins("JOIN bnd_user_hackathons ON bnd_user_hackathons.user_id = users.id").
+		Where("bnd_user_hackathons.hackathon_id = ? AND bnd_user_hackathons.hackathon_role = 1", hackathon.ID).
+		Where("NOT EXISTS (SELECT 1 FROM bnd_user_teams "+
+			"JOIN teams ON bnd_user_teams.team_id = teams.id "+
+			"WHERE bnd_user_teams.user_id = users.id AND teams.hackathon_id = ? AND teams.deleted_at IS NULL)", hackathon.ID).
+		Find(&free).Error; err != nil {
+		return response, err
+	}
+
+	userIDs := make([]uint, 0, len(members)+len(free))
+	for _, member := range members {
+		userIDs = append(userIDs, member.UserID)
+	}
+	for _, user := range free {
+		userIDs = append(userIDs, user.ID)
+	}
+
+	skills, err := userTechnologies(hc.DB, userIDs)
+	if err != nil {
+		return response, err
+	}
+
+	combined := techSet{}
+	for _, member := range members {
+		combined.add(skills[member.UserID])
+	}
+
+	missing := target.minus(combined)
+	response.Missing = missing.short()
+
+	// В заполненную команду подбирать некого
+	if hackathon.MaxTeamSize > 0 && int64(len(members)) >= int64(hackathon.MaxTeamSize) {
+		return response, nil
+	}
+
+	for _, user := range free {
+		covers := skills[user.ID].minus(combined).within(target)
+		if len(covers) == 0 {
+			continue
+		}
+
+		response.List = append(response.List, recommendationDTO.Item{
+			ID:          user.ID,
+			Name:        user.Username,
+			Score:       len(covers),
+			Covers:      covers.short(),
+			Brings:      []technologyDTO.GetShort{},
+			Explanation: "покрывает " + covers.names(),
+		})
+	}
+
+	return response, nil
+}
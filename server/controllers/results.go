@@ -0,0 +1,806 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"server/models"
+	"server/models/DTO/fileDTO"
+	"server/models/DTO/resultDTO"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type resultCriteriaScore struct {
+	Name          string   `json:"name"`
+	MaxScore      uint     `json:"maxScore"`
+	Weight        float64  `json:"weight"`
+	Normalization int      `json:"normalization"`
+	Score         float64  `json:"score"`
+	Normalized    float64  `json:"normalized"`
+	Weighted      float64  `json:"weighted"`
+	Comment       string   `json:"comment"`
+	Comments      []string `json:"comments"`
+	Judges        int      `json:"judges"`
+}
+
+type resultAward struct {
+	MoneyAmount  float64 `json:"moneyAmount"`
+	Additionally string  `json:"additionally"`
+}
+
+// resultRow - строка таблицы результатов
+type resultRow struct {
+	TeamID        uint                  `json:"teamId"`
+	TeamName      string                `json:"teamName"`
+	Place         int                   `json:"place"`
+	Score         float64               `json:"score"`
+	Votes         *int                  `json:"votes,omitempty"`
+	Audience      float64               `json:"audience,omitempty"`
+	TieBreak      string                `json:"tieBreak,omitempty"`
+	Project       *fileDTO.GetShort     `json:"project,omitempty"`
+	Award         *resultAward          `json:"award,omitempty"`
+	Criteria      []resultCriteriaScore `json:"criteria"`
+	JudgesScored  int                   `json:"judgesScored"`
+	PendingJudges []hackathonJudge      `json:"pendingJudges"`
+
+	awardID *uint
+}
+
+// resultsTable - посчитанные результаты общего рейтинга или номинации
+type resultsTable struct {
+	List     []resultRow
+	MaxScore float64
+	Judges   []hackathonJudge
+	// Ничьи, которые не разрешило ни одно правило и на которые приходятся разные награды
+	Ties []resultDTO.Tie
+	// Голосование ещё идёт, его итоги в результатах не учтены
+	VotesHidden bool
+}
+
+// trackResults - результаты номинации
+type trackResults struct {
+	ID   uint            `json:"id"`
+	Name string          `json:"name"`
+	Kind int             `json:"kind"`
+	List []resultRow     `json:"list"`
+	Ties []resultDTO.Tie `json:"ties,omitempty"`
+}
+
+// resultsInput - данные хакатона, из которых считаются общий рейтинг и номинации
+type resultsInput struct {
+	teams    []models.Team
+	teamByID map[uint]models.Team
+	scores   teamScoreTable
+	judges   []hackathonJudge
+	breakers []tieBreaker
+
+	// Баллы голосования зрителей; до окончания голосования не загружаются
+	votes       map[uint]int
+	votesHidden bool
+}
+
+// errTieBreakCriterion - правило «балл по критерию» указывает на критерий другого хакатона
+var errTieBreakCriterion = errors.New("критерий правила разрешения ничьих не относится к хакатону")
+
+// loadTieBreakers собирает правила разрешения ничьих хакатона в порядке применения.
+// submittedAt - время сдачи итоговой версии проекта по командам
+func loadTieBreakers(db *gorm.DB, hackathonID uint, criteria []models.Criteria, submittedAt map[uint]time.Time) ([]tieBreaker, error) {
+	var rules []models.TieBreaker
+	if err := db.Where("hackathon_id = ?", hackathonID).Order("position").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+
+	criteriaByID := make(map[uint]models.Criteria, len(criteria))
+	for _, criterion := range criteria {
+		criteriaByID[criterion.ID] = criterion
+	}
+
+	breakers := make([]tieBreaker, 0, len(rules))
+	for _, rule := range rules {
+		switch rule.Kind {
+		case models.TieBreakCriterion:
+			if rule.CriteriaID == nil {
+				return nil, errTieBreakCriterion
+			}
+			criterion, ok := criteriaByID[*rule.CriteriaID]
+			if !ok {
+				return nil, errTieBreakCriterion
+			}
+			breakers = append(breakers, criterionTieBreaker(criterion))
+
+		case models.TieBreakSubmission:
+			breakers = append(breakers, submissionTieBreaker(submittedAt))
+
+		case models.TieBreakOrganizer:
+			var decisions []models.TieDecision
+			if err := db.Where("hackathon_id = ?", hackathonID).Find(&decisions).Error; err != nil {
+				return nil, err
+			}
+			ranks := make(map[uint]int, len(decisions))
+			for _, decision := range decisions {
+				ranks[decision.TeamID] = decision.Rank
+			}
+			breakers = append(breakers, organizerTieBreaker(ranks))
+		}
+	}
+	return breakers, nil
+}
+
+// loadResultsInput загружает команды с итоговыми проектами, оценки, судей и правила разрешения ничьих
+func loadResultsInput(db *gorm.DB, hackathon models.Hackathon, criteria []models.Criteria) (resultsInput, error) {
+	var input resultsInput
+
+	// Получаем все команды для этого хакатона
+	if err := db.Where("hackathon_id = ?", hackathon.ID).
+		Preload("Project").
+		Find(&input.teams).Error; err != nil {
+		return input, err
+	}
+
+	// Команды, выбывшие в отборочных турах, в финальном рейтинге не участвуют
+	var err error
+	if input.teams, err = activeTeams(db, hackathon.ID, input.teams, 0); err != nil {
+		return input, err
+	}
+
+	// Время сдачи берётся из итоговой версии, для старых проектов - из времени загрузки файла
+	submittedAt := make(map[uint]time.Time, len(input.teams))
+	for _, team := range input.teams {
+		if team.Project != nil {
+			submittedAt[team.ID] = team.Project.CreatedAt
+		}
+	}
+
+	submissions, err := applyFinalProjects(db, input.teams)
+	if err != nil {
+		return input, err
+	}
+	for teamID, submission := range submissions {
+		submittedAt[teamID] = submission.CreatedAt
+	}
+
+	// Собираем все ID команд
+	teamIDs := make([]uint, 0, len(input.teams))
+	input.teamByID = make(map[uint]models.Team, len(input.teams))
+	for _, team := range input.teams {
+		teamIDs = append(teamIDs, team.ID)
+		input.teamByID[team.ID] = team
+	}
+
+	// Оценки всех судей по командам и критериям финальной оценки
+	scores, err := loadTeamScores(db, teamIDs)
+	if err != nil {
+		return input, err
+	}
+	input.scores = scores.only(criteria)
+	if input.judges, err = loadHackathonJudges(db, hackathon.ID); err != nil {
+		return input, err
+	}
+	if input.breakers, err = loadTieBreakers(db, hackathon.ID, criteria, submittedAt); err != nil {
+		return input, err
+	}
+
+	// Итоги голосования скрыты, пока оно не закончилось
+	input.votes = map[uint]int{}
+	if hackathon.VotingMode != models.VotingOff {
+		if !hackathon.VotingClosed(time.Now()) {
+			input.votesHidden = true
+			return input, nil
+		}
+		totals, err := loadVoteTotals(db, hackathon.ID)
+		if err != nil {
+			return input, err
+		}
+		for teamID, total := range totals {
+			input.votes[teamID] = total.Points
+		}
+	}
+	return input, nil
+}
+
+// table формирует таблицу результатов по расставленным командам и раздаёт призы по местам
+func (input resultsInput) table(standings []teamStanding, criteria []models.Criteria, formula scoringFormula, awards []models.Award) resultsTable {
+	table := resultsTable{MaxScore: formula.maxTotal(criteria), Judges: input.judges, VotesHidden: input.votesHidden}
+
+	// Создаем маппинг для быстрого поиска награды по месту
+	awardsByPlace := make(map[int]models.Award)
+	for _, award := range awards {
+		for place := award.PlaceFrom; place <= award.PlaceTo; place++ {
+			awardsByPlace[place] = award
+		}
+	}
+
+	// Формируем массив результатов в порядке мест
+	table.List = make([]resultRow, 0, len(standings))
+	for _, standing := range standings {
+		scored := input.scores.judgesScored(standing.Team.ID)
+
+		criteriaScores := make([]resultCriteriaScore, 0, len(standing.Criteria))
+		for _, criterion := range standing.Criteria {
+			rule := formula.Criteria[criterion.Criteria.ID]
+			criteriaScores = append(criteriaScores, resultCriteriaScore{
+				Name:          criterion.Criteria.Name,
+				MaxScore:      criterion.Criteria.MaxScore,
+				Weight:        rule.Weight,
+				Normalization: rule.Normalization,
+				Score:         criterion.Raw,
+				Normalized:    criterion.Normalized,
+				Weighted:      criterion.Weighted,
+				Comment:       strings.Join(criterion.Comments, "\n"),
+				Comments:      criterion.Comments,
+				Judges:        criterion.Judges,
+			})
+		}
+
+		result := resultRow{
+			TeamID:        standing.Team.ID,
+			TeamName:      standing.Team.Name,
+			Place:         standing.Place,
+			Score:         standing.Total,
+			TieBreak:      standing.TieBreak,
+			Audience:      standing.Audience,
+			Criteria:      criteriaScores,
+			JudgesScored:  len(scored),
+			PendingJudges: pendingJudges(input.judges, scored),
+		}
+
+		if formula.VotingWeight > 0 && !input.votesHidden {
+			votes := input.votes[standing.Team.ID]
+			result.Votes = &votes
+		}
+
+		// Добавляем информацию о проекте, если он есть
+		if standing.Team.Project != nil {
+			result.Project = &fileDTO.GetShort{
+				ID:   standing.Team.Project.ID,
+				Name: standing.Team.Project.Name,
+				Size: standing.Team.Project.Size,
+				Type: standing.Team.Project.Type,
+			}
+		}
+
+		// Добавляем информацию о награде, если команда в призовых местах
+		if award, hasAward := awardsByPlace[result.Place]; hasAward {
+			awardID := award.ID
+			result.awardID = &awardID
+			result.Award = &resultAward{
+				MoneyAmount:  award.MoneyAmount,
+				Additionally: award.Additionally,
+			}
+		}
+
+		table.List = append(table.List, result)
+	}
+
+	table.Ties = prizeTies(table.List, awardsByPlace)
+	return table
+}
+
+// computeResults считает общий рейтинг хакатона по формуле formula и результаты номинаций.
+// Номинации всегда считаются по сохранённой формуле: номинация по критериям ранжирует команды
+// только по своим критериям, в номинации организатора места берутся из его выбора
+func computeResults(db *gorm.DB, hackathon models.Hackathon, criteria []models.Criteria, formula scoringFormula) (resultsTable, []trackResults, error) {
+	input, err := loadResultsInput(db, hackathon, criteria)
+	if err != nil {
+		return resultsTable{}, nil, err
+	}
+
+	// Призы номинаций в общий рейтинг не входят
+	var awards []models.Award
+	if err := db.Where("hackathon_id = ? AND track_id IS NULL", hackathon.ID).Find(&awards).Error; err != nil {
+		return resultsTable{}, nil, err
+	}
+
+	standings := computeStandings(input.teams, criteria, input.scores, formula, input.votes, input.breakers)
+	overall := input.table(standings, criteria, formula, awards)
+
+	tracks, err := input.tracks(db, hackathon)
+	if err != nil {
+		return resultsTable{}, nil, err
+	}
+	return overall, tracks, nil
+}
+
+// tracks считает результаты всех номинаций хакатона
+func (input resultsInput) tracks(db *gorm.DB, hackathon models.Hackathon) ([]trackResults, error) {
+	var tracks []models.AwardTrack
+	if err := db.Preload("Criteria", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Awards").
+		Where("hackathon_id = ?", hackathon.ID).
+		Order("id").
+		Find(&tracks).Error; err != nil {
+		return nil, err
+	}
+
+	results := make([]trackResults, 0, len(tracks))
+
+	for _, track := range tracks {
+		teams := input.teams
+		if track.OptIn {
+			var entries []models.AwardTrackEntry
+			if err := db.Where("track_id = ?", track.ID).Find(&entries).Error; err != nil {
+				return nil, err
+			}
+			teams = make([]models.Team, 0, len(entries))
+			for _, entry := range entries {
+				if team, ok := input.teamByID[entry.TeamID]; ok {
+					teams = append(teams, team)
+				}
+			}
+		}
+
+		// Голоса учитываются только в номинации зрительских симпатий
+		formula := hackathonFormula(hackathon, track.Criteria)
+		formula.VotingWeight = 0
+
+		var standings []teamStanding
+		switch track.Kind {
+		case models.AwardTrackAudience:
+			for _, team := range teams {
+				standings = append(standings, teamStanding{Team: team, Total: float64(input.votes[team.ID]), Votes: input.votes[team.ID]})
+			}
+			assignPlaces(standings, input.breakers)
+		case models.AwardTrackManual:
+			var picks []models.AwardTrackPick
+			if err := db.Where("track_id = ?", track.ID).Order("place").Find(&picks).Error; err != nil {
+				return nil, err
+			}
+			for _, pick := range picks {
+				if team, ok := input.teamByID[pick.TeamID]; ok {
+					standings = append(standings, teamStanding{Team: team, Place: pick.Place})
+				}
+			}
+		default:
+			standings = computeStandings(teams, track.Criteria, input.scores, formula, nil, input.breakers)
+		}
+
+		table := input.table(standings, track.Criteria, formula, track.Awards)
+		if track.Kind == models.AwardTrackAudience && !input.votesHidden {
+			for i := range table.List {
+				votes := standings[i].Votes
+				table.List[i].Votes = &votes
+			}
+		}
+		results = append(results, trackResults{
+			ID:   track.ID,
+			Name: track.Name,
+			Kind: track.Kind,
+			List: table.List,
+			Ties: table.Ties,
+		})
+	}
+	return results, nil
+}
+
+// prizeTies находит команды, делящие место, если на занятые ими места приходятся разные награды
+// или часть из них без награды. Такие ничьи нужно разрешить до публикации
+func prizeTies(list []resultRow, awardsByPlace map[int]models.Award) []resultDTO.Tie {
+	ties := make([]resultDTO.Tie, 0)
+	for start := 0; start < len(list); {
+		end := start + 1
+		for end < len(list) && list[end].Place == list[start].Place {
+			end++
+		}
+
+		if end-start > 1 {
+			place := list[start].Place
+			first, firstOK := awardsByPlace[place]
+			for p := place + 1; p < place+end-start; p++ {
+				award, ok := awardsByPlace[p]
+				if ok != firstOK || award.ID != first.ID {
+					tie := resultDTO.Tie{Place: place}
+					for _, row := range list[start:end] {
+						tie.Teams = append(tie.Teams, row.TeamName)
+					}
+					ties = append(ties, tie)
+					break
+				}
+			}
+		}
+		start = end
+	}
+	return ties
+}
+
+// loadPublishedResults читает зафиксированные при публикации таблицы общего рейтинга и номинаций
+func loadPublishedResults(db *gorm.DB, hackathonID uint) ([]resultRow, []trackResults, error) {
+	var snapshots []models.ResultSnapshot
+	if err := db.Where("hackathon_id = ?", hackathonID).Order("position").Find(&snapshots).Error; err != nil {
+		return nil, nil, err
+	}
+
+	list := make([]resultRow, 0, len(snapshots))
+	byTrack := make(map[uint][]resultRow)
+	for _, snapshot := range snapshots {
+		var row resultRow
+		if err := json.Unmarshal([]byte(snapshot.Details), &row); err != nil {
+			return nil, nil, err
+		}
+		if snapshot.TrackID != nil {
+			byTrack[*snapshot.TrackID] = append(byTrack[*snapshot.TrackID], row)
+			continue
+		}
+		list = append(list, row)
+	}
+
+	var tracks []models.AwardTrack
+	if err := db.Where("hackathon_id = ?", hackathonID).Order("id").Find(&tracks).Error; err != nil {
+		return nil, nil, err
+	}
+	trackList := make([]trackResults, 0, len(tracks))
+	for _, track := range tracks {
+		rows := byTrack[track.ID]
+		if rows == nil {
+			rows = []resultRow{}
+		}
+		trackList = append(trackList, trackResults{ID: track.ID, Name: track.Name, Kind: track.Kind, List: rows})
+	}
+	return list, trackList, nil
+}
+
+// saveSnapshot сохраняет строки таблицы результатов и собирает победителей наград по ID награды
+func saveSnapshot(tx *gorm.DB, hackathonID uint, trackID *uint, list []resultRow, winners map[uint][]models.Team) error {
+	for i, row := range list {
+		details, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		snapshot := models.ResultSnapshot{
+			HackathonID: hackathonID,
+			TrackID:     trackID,
+			TeamID:      row.TeamID,
+			TeamName:    row.TeamName,
+			Position:    i + 1,
+			Place:       row.Place,
+			Score:       row.Score,
+			AwardID:     row.awardID,
+			Details:     string(details),
+		}
+		if err := tx.Create(&snapshot).Error; err != nil {
+			return err
+		}
+		if row.awardID != nil {
+			winners[*row.awardID] = append(winners[*row.awardID], models.Team{Model: gorm.Model{ID: row.TeamID}})
+		}
+	}
+	return nil
+}
+
+// PublishResults фиксирует таблицы общего рейтинга и номинаций и вручает награды командам.
+// Ничьи, на которые приходятся разные награды, должны быть разрешены заранее
+func (hc *HackathonController) PublishResults(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	var ties []resultDTO.Tie
+	var publishedAt time.Time
+	err = hc.DB.Transaction(func(tx *gorm.DB) error {
+		var hackathon models.Hackathon
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&hackathon, hackathonID).Error; err != nil {
+			return err
+		}
+		if hackathon.ResultsPublished() {
+			return errResultsPublished
+		}
+		if hackathon.VotingMode != models.VotingOff && !hackathon.VotingClosed(time.Now()) {
+			return errVotingInProgress
+		}
+
+		// Пока тур не закрыт, неизвестно, какие команды выбыли
+		var openRounds int64
+		if err := tx.Model(&models.HackathonStep{}).
+			Where("hackathon_id = ? AND is_round = ? AND closed_at IS NULL", hackathon.ID, true).
+			Count(&openRounds).Error; err != nil {
+			return err
+		}
+		if openRounds > 0 {
+			return errRoundsOpen
+		}
+
+		criteria, err := mainCriteria(tx, hackathon.ID)
+		if err != nil {
+			return err
+		}
+
+		table, tracks, err := computeResults(tx, hackathon, criteria, hackathonFormula(hackathon, criteria))
+		if err != nil {
+			return err
+		}
+
+		ties = append(ties, table.Ties...)
+		for _, track := range tracks {
+			for _, tie := range track.Ties {
+				tie.Track = track.Name
+				ties = append(ties, tie)
+			}
+		}
+		if len(ties) > 0 {
+			return errUnresolvedTies
+		}
+
+		winners := make(map[uint][]models.Team)
+		if err := saveSnapshot(tx, hackathon.ID, nil, table.List, winners); err != nil {
+			return err
+		}
+		for _, track := range tracks {
+			trackID := track.ID
+			if err := saveSnapshot(tx, hackathon.ID, &trackID, track.List, winners); err != nil {
+				return err
+			}
+		}
+
+		if err := replaceAwardWinners(tx, hackathon.ID, winners); err != nil {
+			return err
+		}
+
+		publishedAt = time.Now()
+		return tx.Model(&hackathon).Update("results_published_at", publishedAt).Error
+	})
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+	case errors.Is(err, errResultsPublished), errors.Is(err, errVotingInProgress), errors.Is(err, errRoundsOpen):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, errUnresolvedTies):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "ties": ties})
+	case errors.Is(err, errTieBreakCriterion):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при публикации результатов", "details": err.Error()})
+	default:
+		c.JSON(http.StatusOK, gin.H{"message": "Результаты опубликованы", "publishedAt": publishedAt})
+	}
+}
+
+// UnpublishResults отменяет публикацию: снимок таблицы и вручённые награды удаляются,
+// сертификаты победителей отзываются до повторной выдачи
+func (hc *HackathonController) UnpublishResults(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	err = hc.DB.Transaction(func(tx *gorm.DB) error {
+		var hackathon models.Hackathon
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&hackathon, hackathonID).Error; err != nil {
+			return err
+		}
+		if !hackathon.ResultsPublished() {
+			return errResultsNotPublished
+		}
+
+		if err := tx.Unscoped().Where("hackathon_id = ?", hackathon.ID).Delete(&models.ResultSnapshot{}).Error; err != nil {
+			return err
+		}
+		if err := replaceAwardWinners(tx, hackathon.ID, nil); err != nil {
+			return err
+		}
+		if _, err := revokeStaleWinnerCertificates(tx, hackathon.ID, nil); err != nil {
+			return err
+		}
+		return tx.Model(&hackathon).Update("results_published_at", nil).Error
+	})
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+	case errors.Is(err, errResultsNotPublished):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при отмене публикации", "details": err.Error()})
+	default:
+		c.JSON(http.StatusOK, gin.H{"message": "Публикация результатов отменена"})
+	}
+}
+
+var (
+	errResultsPublished    = errors.New("результаты уже опубликованы")
+	errResultsNotPublished = errors.New("результаты ещё не опубликованы")
+	errUnresolvedTies      = errors.New("есть неразрешённые ничьи на призовых местах")
+	errVotingInProgress    = errors.New("голосование зрителей ещё не закончилось")
+	errRoundsOpen          = errors.New("есть незакрытые отборочные туры")
+)
+
+// replaceAwardWinners заменяет победителей всех наград хакатона
+func replaceAwardWinners(tx *gorm.DB, hackathonID uint, winners map[uint][]models.Team) error {
+	var awards []models.Award
+	if err := tx.Where("hackathon_id = ?", hackathonID).Find(&awards).Error; err != nil {
+		return err
+	}
+
+	for i := range awards {
+		association := tx.Model(&awards[i]).Omit("Teams.*").Association("Teams")
+		if err := association.Clear(); err != nil {
+			return err
+		}
+		if teams := winners[awards[i].ID]; len(teams) > 0 {
+			if err := association.Append(teams); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// GetTieSettings возвращает правила разрешения ничьих и порядок, заданный организатором
+func (hc *HackathonController) GetTieSettings(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	var rules []models.TieBreaker
+	if err := hc.DB.Preload("Criteria").Where("hackathon_id = ?", hackathonID).Order("position").Find(&rules).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении правил", "details": err.Error()})
+		return
+	}
+
+	var decisions []models.TieDecision
+	if err := hc.DB.Preload("Team").Where("hackathon_id = ?", hackathonID).Order("rank").Find(&decisions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении решений организатора", "details": err.Error()})
+		return
+	}
+
+	settings := resultDTO.TieSettings{
+		Rules:     make([]resultDTO.TieBreaker, 0, len(rules)),
+		Decisions: make([]resultDTO.TieDecision, 0, len(decisions)),
+	}
+	for _, rule := range rules {
+		name := models.TieBreakNames[rule.Kind]
+		if rule.Criteria != nil {
+			name += " «" + rule.Criteria.Name + "»"
+		}
+		settings.Rules = append(settings.Rules, resultDTO.TieBreaker{Kind: rule.Kind, Name: name, CriteriaID: rule.CriteriaID})
+	}
+	for _, decision := range decisions {
+		settings.Decisions = append(settings.Decisions, resultDTO.TieDecision{
+			TeamID:   decision.TeamID,
+			TeamName: decision.Team.Name,
+			Rank:     decision.Rank,
+		})
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// unpublishedHackathon загружает хакатон и проверяет, что результаты ещё не опубликованы:
+// после публикации правила подсчёта менять нельзя
+func (hc *HackathonController) unpublishedHackathon(c *gin.Context) (models.Hackathon, bool) {
+	var hackathon models.Hackathon
+	if err := hc.DB.First(&hackathon, c.Param("hackathon_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return hackathon, false
+	}
+	if hackathon.ResultsPublished() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Результаты уже опубликованы, сначала отмените публикацию"})
+		return hackathon, false
+	}
+	return hackathon, true
+}
+
+// SetTieBreakers заменяет правила разрешения ничьих хакатона
+func (hc *HackathonController) SetTieBreakers(c *gin.Context) {
+	var dto resultDTO.TieBreakersSet
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+	if err := validator.New().Struct(dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка валидации: " + err.Error()})
+		return
+	}
+
+	hackathon, ok := hc.unpublishedHackathon(c)
+	if !ok {
+		return
+	}
+
+	seen := make(map[string]bool, len(dto.Rules))
+	rules := make([]models.TieBreaker, 0, len(dto.Rules))
+	for i, input := range dto.Rules {
+		rule := models.TieBreaker{HackathonID: hackathon.ID, Position: i + 1, Kind: input.Kind}
+
+		key := strconv.Itoa(input.Kind)
+		if input.Kind == models.TieBreakCriterion {
+			if input.CriteriaID == nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Для правила по критерию нужно указать критерий"})
+				return
+			}
+			var count int64
+			if err := hc.DB.Model(&models.Criteria{}).Where("id = ? AND hackathon_id = ? AND step_id IS NULL", *input.CriteriaID, hackathon.ID).Count(&count).Error; err != nil || count == 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": errTieBreakCriterion.Error(), "criteriaId": *input.CriteriaID})
+				return
+			}
+			rule.CriteriaID = input.CriteriaID
+			key += ":" + strconv.FormatUint(uint64(*input.CriteriaID), 10)
+		}
+
+		if seen[key] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Правила разрешения ничьих не должны повторяться"})
+			return
+		}
+		seen[key] = true
+		rules = append(rules, rule)
+	}
+
+	err := hc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("hackathon_id = ?", hackathon.ID).Delete(&models.TieBreaker{}).Error; err != nil {
+			return err
+		}
+		if len(rules) == 0 {
+			return nil
+		}
+		return tx.Create(&rules).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении правил", "details": err.Error()})
+		return
+	}
+
+	hc.GetTieSettings(c)
+}
+
+// SetTieDecisions сохраняет порядок команд, которым организатор разрешает ничьи
+func (hc *HackathonController) SetTieDecisions(c *gin.Context) {
+	var dto resultDTO.TieDecisionsSet
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+	if err := validator.New().Struct(dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка валидации: " + err.Error()})
+		return
+	}
+
+	hackathon, ok := hc.unpublishedHackathon(c)
+	if !ok {
+		return
+	}
+
+	teamIDs := uniqueIDs(dto.Teams)
+	if len(teamIDs) != len(dto.Teams) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Команды в порядке не должны повторяться"})
+		return
+	}
+
+	var count int64
+	if err := hc.DB.Model(&models.Team{}).Where("id IN ? AND hackathon_id = ?", dto.Teams, hackathon.ID).Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке команд", "details": err.Error()})
+		return
+	}
+	if int(count) != len(dto.Teams) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Не все команды относятся к хакатону"})
+		return
+	}
+
+	err := hc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("hackathon_id = ?", hackathon.ID).Delete(&models.TieDecision{}).Error; err != nil {
+			return err
+		}
+		for i, teamID := range dto.Teams {
+			decision := models.TieDecision{HackathonID: hackathon.ID, TeamID: teamID, Rank: i + 1}
+			if err := tx.Create(&decision).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении порядка команд", "details": err.Error()})
+		return
+	}
+
+	hc.GetTieSettings(c)
+}
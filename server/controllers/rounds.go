@@ -0,0 +1,473 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"server/models"
+	"server/models/DTO/criteriaDTO"
+	"server/models/DTO/hackathonStepDTO"
+	"strconv"
+	"time"
+)
+
+// roundRow - строка результатов отборочного тура
+type roundRow struct {
+	TeamID   uint    `json:"teamId"`
+	TeamName string  `json:"teamName"`
+	Place    int     `json:"place"`
+	Score    float64 `json:"score"`
+	Advanced bool    `json:"advanced"`
+}
+
+// roundResults - результаты отборочного тура. У незакрытого тура прохождение дальше предварительное
+type roundResults struct {
+	ID          uint       `json:"id"`
+	Name        string     `json:"name"`
+	CutoffKind  int        `json:"cutoffKind"`
+	CutoffValue float64    `json:"cutoffValue"`
+	MaxScore    float64    `json:"maxScore"`
+	Closed      bool       `json:"closed"`
+	ClosedAt    *time.Time `json:"closedAt,omitempty"`
+	List        []roundRow `json:"list"`
+}
+
+var (
+	errRoundClosed        = errors.New("тур уже закрыт")
+	errRoundNotClosed     = errors.New("тур ещё не закрыт")
+	errPreviousRoundOpen  = errors.New("предыдущий тур ещё не закрыт")
+	errLaterRoundClosed   = errors.New("следующий тур уже закрыт")
+	errRoundNotStarted    = errors.New("тур ещё не начался")
+	errRoundNotEvaluation = errors.New("этап не является отборочным туром")
+)
+
+func toStepDTO(step *models.HackathonStep) hackathonStepDTO.Get {
+	result := hackathonStepDTO.Get{
+		ID:          step.ID,
+		Name:        step.Name,
+		Description: step.Description,
+		StartDate:   step.StartDate,
+		EndDate:     step.EndDate,
+		IsRound:     step.IsRound,
+	}
+	if !step.IsRound {
+		return result
+	}
+
+	result.CutoffKind = step.CutoffKind
+	result.CutoffValue = step.CutoffValue
+	result.ClosedAt = step.ClosedAt
+	result.Criteria = make([]criteriaDTO.Get, 0, len(step.Criteria))
+	for _, criterion := range step.Criteria {
+		result.Criteria = append(result.Criteria, criteriaDTO.Get{
+			ID:            criterion.ID,
+			Name:          criterion.Name,
+			MaxScore:      criterion.MaxScore,
+			MinScore:      criterion.MinScore,
+			Weight:        criterion.Weight,
+			Normalization: criterion.Normalization,
+		})
+	}
+	return result
+}
+
+// mainCriteria возвращает критерии финальной оценки хакатона без критериев отборочных туров
+func mainCriteria(db *gorm.DB, hackathonID uint) ([]models.Criteria, error) {
+	var criteria []models.Criteria
+	err := db.Where("hackathon_id = ? AND step_id IS NULL", hackathonID).Order("id").Find(&criteria).Error
+	return criteria, err
+}
+
+// loadRounds возвращает отборочные туры хакатона в порядке проведения
+func loadRounds(db *gorm.DB, hackathonID uint) ([]models.HackathonStep, error) {
+	var rounds []models.HackathonStep
+	err := db.Preload("Criteria", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where("hackathon_id = ? AND is_round = ?", hackathonID, true).
+		Order("start_date").
+		Find(&rounds).Error
+	return rounds, err
+}
+
+// currentRound возвращает идущий в момент now отборочный тур или nil
+func currentRound(db *gorm.DB, hackathonID uint, now time.Time) (*models.HackathonStep, error) {
+	rounds, err := loadRounds(db, hackathonID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rounds {
+		if rounds[i].RoundOpen(now) {
+			return &rounds[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// eliminatedTeams возвращает команды, не прошедшие закрытые туры, с названием тура.
+// exceptStepID исключает тур, для которого считается состав участников
+func eliminatedTeams(db *gorm.DB, hackathonID uint, exceptStepID uint) (map[uint]string, error) {
+	var rows []struct {
+		TeamID uint
+		Name   string
+	}
+	if err := db.Model(&models.RoundResult{}).
+		Select("round_results.team_id, hackathon_steps.name").
+		Joins("JOIN hackathon_steps ON hackathon_steps.id = round_results.step_id AND hackathon_steps.deleted_at IS NULL").
+		Where("round_results.hackathon_id = ? AND round_results.advanced = ? AND round_results.step_id <> ?", hackathonID, false, exceptStepID).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	eliminated := make(map[uint]string, len(rows))
+	for _, row := range rows {
+		eliminated[row.TeamID] = row.Name
+	}
+	return eliminated, nil
+}
+
+// activeTeams оставляет команды, не выбывшие в турах, кроме exceptStepID
+func activeTeams(db *gorm.DB, hackathonID uint, teams []models.Team, exceptStepID uint) ([]models.Team, error) {
+	eliminated, err := eliminatedTeams(db, hackathonID, exceptStepID)
+	if err != nil {
+		return nil, err
+	}
+	if len(eliminated) == 0 {
+		return teams, nil
+	}
+
+	active := make([]models.Team, 0, len(teams))
+	for _, team := range teams {
+		if _, out := eliminated[team.ID]; !out {
+			active = append(active, team)
+		}
+	}
+	return active, nil
+}
+
+// roundSubmissions возвращает последние версии сдачи команд, сданные во время тура
+func roundSubmissions(db *gorm.DB, stepID uint, teamIDs []uint) (map[uint]models.Submission, error) {
+	result := make(map[uint]models.Submission, len(teamIDs))
+	if len(teamIDs) == 0 {
+		return result, nil
+	}
+
+	var submissions []models.Submission
+	if err := db.Preload("Artifacts.File").
+		Where("(team_id, version) IN (?)", db.Model(&models.Submission{}).
+			Select("team_id, MAX(version)").
+			Where("team_id IN ? AND step_id = ?", teamIDs, stepID).
+			Group("team_id")).
+		Find(&submissions).Error; err != nil {
+		return nil, err
+	}
+
+	for _, submission := range submissions {
+		result[submission.TeamID] = submission
+	}
+	return result, nil
+}
+
+// evaluationRound определяет, что оценивает судья: отборочный тур из параметра round
+// или финальную оценку. Возвращает тур (nil для финала) и его критерии.
+// Возвращает false, если ответ уже отправлен
+func evaluationRound(c *gin.Context, db *gorm.DB, hackathonID uint) (*models.HackathonStep, []models.Criteria, bool) {
+	ref := c.Query("round")
+	if ref == "" {
+		criteria, err := mainCriteria(db, hackathonID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении критериев оценки"})
+			return nil, nil, false
+		}
+		return nil, criteria, true
+	}
+
+	stepID, err := strconv.ParseUint(ref, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID тура"})
+		return nil, nil, false
+	}
+
+	var step models.HackathonStep
+	if err := db.Preload("Criteria", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where("id = ? AND hackathon_id = ? AND is_round = ?", stepID, hackathonID, true).
+		First(&step).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Отборочный тур не найден"})
+		return nil, nil, false
+	}
+	return &step, step.Criteria, true
+}
+
+// computeRound ранжирует команды тура по его критериям и отмечает прошедших дальше.
+// Команды, делящие проходное место, проходят все
+func computeRound(hackathon models.Hackathon, step models.HackathonStep, teams []models.Team, scores teamScoreTable) ([]roundRow, float64) {
+	formula := hackathonFormula(hackathon, step.Criteria)
+	formula.VotingWeight = 0
+
+	standings := computeStandings(teams, step.Criteria, scores.only(step.Criteria), formula, nil, nil)
+	rows := make([]roundRow, 0, len(standings))
+	for _, standing := range standings {
+		rows = append(rows, roundRow{
+			TeamID:   standing.Team.ID,
+			TeamName: standing.Team.Name,
+			Place:    standing.Place,
+			Score:    standing.Total,
+			Advanced: step.Advances(standing.Place, standing.Total),
+		})
+	}
+	return rows, formula.maxTotal(step.Criteria)
+}
+
+// loadRoundResults собирает результаты туров. Закрытые туры читаются из сохранённых итогов,
+// незакрытые при live считаются по текущим оценкам, иначе пропускаются
+func loadRoundResults(db *gorm.DB, hackathon models.Hackathon, live bool) ([]roundResults, error) {
+	rounds, err := loadRounds(db, hackathon.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var teams []models.Team
+	var scores teamScoreTable
+	if live {
+		if err := db.Where("hackathon_id = ?", hackathon.ID).Find(&teams).Error; err != nil {
+			return nil, err
+		}
+		teamIDs := make([]uint, len(teams))
+		for i, team := range teams {
+			teamIDs[i] = team.ID
+		}
+		if scores, err = loadTeamScores(db, teamIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]roundResults, 0, len(rounds))
+	for _, round := range rounds {
+		if round.ClosedAt == nil && !live {
+			continue
+		}
+
+		item := roundResults{
+			ID:          round.ID,
+			Name:        round.Name,
+			CutoffKind:  round.CutoffKind,
+			CutoffValue: round.CutoffValue,
+			Closed:      round.ClosedAt != nil,
+			ClosedAt:    round.ClosedAt,
+			List:        []roundRow{},
+		}
+
+		if round.ClosedAt != nil {
+			var saved []models.RoundResult
+			if err := db.Preload("Team").Where("step_id = ?", round.ID).Order("place, id").Find(&saved).Error; err != nil {
+				return nil, err
+			}
+			for _, row := range saved {
+				item.List = append(item.List, roundRow{
+					TeamID:   row.TeamID,
+					TeamName: row.Team.Name,
+					Place:    row.Place,
+					Score:    row.Score,
+					Advanced: row.Advanced,
+				})
+			}
+			item.MaxScore = hackathonFormula(hackathon, round.Criteria).maxTotal(round.Criteria)
+		} else {
+			participants, err := activeTeams(db, hackathon.ID, teams, round.ID)
+			if err != nil {
+				return nil, err
+			}
+			item.List, item.MaxScore = computeRound(hackathon, round, participants, scores)
+		}
+
+		results = append(results, item)
+	}
+	return results, nil
+}
+
+// roundByID находит отборочный тур хакатона из параметров URL и блокирует его до конца транзакции
+func roundByID(tx *gorm.DB, hackathonID, stepID uint) (models.HackathonStep, error) {
+	var step models.HackathonStep
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND hackathon_id = ?", stepID, hackathonID).
+		First(&step).Error
+	if err == nil && !step.IsRound {
+		err = errRoundNotEvaluation
+	}
+	return step, err
+}
+
+// roundParams разбирает ID хакатона и тура из URL. Возвращает false, если ответ уже отправлен
+func roundParams(c *gin.Context) (uint, uint, bool) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return 0, 0, false
+	}
+	stepID, err := strconv.ParseUint(c.Param("step_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID тура"})
+		return 0, 0, false
+	}
+	return uint(hackathonID), uint(stepID), true
+}
+
+// respondRoundError отправляет ответ на ошибку закрытия или открытия тура
+func respondRoundError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, errRoundNotEvaluation):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Отборочный тур не найден"})
+	case errors.Is(err, errRoundClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": "Тур уже закрыт"})
+	case errors.Is(err, errRoundNotClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": "Тур ещё не закрыт"})
+	case errors.Is(err, errPreviousRoundOpen):
+		c.JSON(http.StatusConflict, gin.H{"error": "Сначала закройте предыдущие туры"})
+	case errors.Is(err, errLaterRoundClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": "Следующий тур уже закрыт, сначала откройте его"})
+	case errors.Is(err, errRoundNotStarted):
+		c.JSON(http.StatusConflict, gin.H{"error": "Тур ещё не начался"})
+	case errors.Is(err, errResultsPublished):
+		c.JSON(http.StatusConflict, gin.H{"error": "Результаты уже опубликованы, сначала снимите публикацию"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при подведении итогов тура", "details": err.Error()})
+	}
+}
+
+// CloseRound подводит итоги отборочного тура: ранжирует команды по критериям тура
+// и применяет правило прохода. Не прошедшие команды не могут сдавать проект в следующих турах
+func (hc *HackathonController) CloseRound(c *gin.Context) {
+	hackathonID, stepID, ok := roundParams(c)
+	if !ok {
+		return
+	}
+
+	var rows []roundRow
+	err := hc.DB.Transaction(func(tx *gorm.DB) error {
+		var hackathon models.Hackathon
+		if err := tx.First(&hackathon, hackathonID).Error; err != nil {
+			return err
+		}
+		if hackathon.ResultsPublished() {
+			return errResultsPublished
+		}
+
+		step, err := roundByID(tx, hackathonID, stepID)
+		if err != nil {
+			return err
+		}
+		if step.ClosedAt != nil {
+			return errRoundClosed
+		}
+		now := time.Now()
+		if now.Before(step.StartDate) {
+			return errRoundNotStarted
+		}
+		if err := tx.Model(&step).Association("Criteria").Find(&step.Criteria); err != nil {
+			return err
+		}
+
+		// Туры закрываются по порядку, иначе состав участников тура был бы неизвестен
+		var openBefore int64
+		if err := tx.Model(&models.HackathonStep{}).
+			Where("hackathon_id = ? AND is_round = ? AND closed_at IS NULL AND start_date < ? AND id <> ?", hackathonID, true, step.StartDate, step.ID).
+			Count(&openBefore).Error; err != nil {
+			return err
+		}
+		if openBefore > 0 {
+			return errPreviousRoundOpen
+		}
+
+		var teams []models.Team
+		if err := tx.Where("hackathon_id = ?", hackathonID).Find(&teams).Error; err != nil {
+			return err
+		}
+		if teams, err = activeTeams(tx, hackathonID, teams, step.ID); err != nil {
+			return err
+		}
+		teamIDs := make([]uint, len(teams))
+		for i, team := range teams {
+			teamIDs[i] = team.ID
+		}
+		scores, err := loadTeamScores(tx, teamIDs)
+		if err != nil {
+			return err
+		}
+
+		rows, _ = computeRound(hackathon, step, teams, scores)
+		for _, row := range rows {
+			result := models.RoundResult{
+				HackathonID: hackathonID,
+				StepID:      step.ID,
+				TeamID:      row.TeamID,
+				Place:       row.Place,
+				Score:       row.Score,
+				Advanced:    row.Advanced,
+			}
+			if err := tx.Create(&result).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&step).Update("closed_at", now).Error
+	})
+	if err != nil {
+		respondRoundError(c, err)
+		return
+	}
+
+	advanced := 0
+	for _, row := range rows {
+		if row.Advanced {
+			advanced++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Итоги тура подведены", "list": rows, "advanced": advanced})
+}
+
+// ReopenRound отменяет итоги отборочного тура, выбывшие в нём команды снова участвуют
+func (hc *HackathonController) ReopenRound(c *gin.Context) {
+	hackathonID, stepID, ok := roundParams(c)
+	if !ok {
+		return
+	}
+
+	err := hc.DB.Transaction(func(tx *gorm.DB) error {
+		var hackathon models.Hackathon
+		if err := tx.First(&hackathon, hackathonID).Error; err != nil {
+			return err
+		}
+		if hackathon.ResultsPublished() {
+			return errResultsPublished
+		}
+
+		step, err := roundByID(tx, hackathonID, stepID)
+		if err != nil {
+			return err
+		}
+		if step.ClosedAt == nil {
+			return errRoundNotClosed
+		}
+
+		var closedAfter int64
+		if err := tx.Model(&models.HackathonStep{}).
+			Where("hackathon_id = ? AND is_round = ? AND closed_at IS NOT NULL AND start_date > ? AND id <> ?", hackathonID, true, step.StartDate, step.ID).
+			Count(&closedAfter).Error; err != nil {
+			return err
+		}
+		if closedAfter > 0 {
+			return errLaterRoundClosed
+		}
+
+		if err := tx.Unscoped().Where("step_id = ?", step.ID).Delete(&models.RoundResult{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&step).Update("closed_at", nil).Error
+	})
+	if err != nil {
+		respondRoundError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Итоги тура отменены"})
+}
@@ -0,0 +1,435 @@
+package controllers
+
+import (
+	"gorm.io/gorm"
+	"math"
+	"server/models"
+	"sort"
+	"time"
+)
+
+// hackathonJudge - пользователь, который может оценивать проекты хакатона
+type hackathonJudge struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+}
+
+// teamScoreTable - оценки хакатона, сгруппированные по команде, критерию и судье
+type teamScoreTable map[uint]map[uint]map[uint]models.Score
+
+// loadHackathonJudges возвращает судей хакатона (менторов и организаторов)
+func loadHackathonJudges(db *gorm.DB, hackathonID uint) ([]hackathonJudge, error) {
+	judges := make([]hackathonJudge, 0)
+	err := db.Model(&models.User{}).
+		Select("users.id, users.username").
+		Joins("JOIN bnd_user_hackathons ON bnd_user_hackathons.user_id = users.id").
+		Where("bnd_user_hackathons.hackathon_id = ? AND bnd_user_hackathons.hackathon_role IN ?", hackathonID, []int{2, 3}).
+		Order("users.id").
+		Scan(&judges).Error
+	return judges, err
+}
+
+// loadTeamScores загружает оценки всех судей для указанных команд. Оценки, сохранённые до учёта судей,
+// ни к кому не привязаны и не учитываются, иначе они считались бы оценками отдельного судьи
+func loadTeamScores(db *gorm.DB, teamIDs []uint) (teamScoreTable, error) {
+	table := make(teamScoreTable)
+	if len(teamIDs) == 0 {
+		return table, nil
+	}
+
+	var scores []models.Score
+	if err := db.Where("team_id IN ? AND user_id IS NOT NULL AND user_id <> 0", teamIDs).Find(&scores).Error; err != nil {
+		return nil, err
+	}
+
+	for _, score := range scores {
+		if table[score.TeamID] == nil {
+			table[score.TeamID] = make(map[uint]map[uint]models.Score)
+		}
+		if table[score.TeamID][score.CriteriaID] == nil {
+			table[score.TeamID][score.CriteriaID] = make(map[uint]models.Score)
+		}
+		table[score.TeamID][score.CriteriaID][score.UserID] = score
+	}
+
+	return table, nil
+}
+
+// judgesScored возвращает множество судей, оценивших команду хотя бы по одному критерию
+func (t teamScoreTable) judgesScored(teamID uint) map[uint]bool {
+	scored := make(map[uint]bool)
+	for _, byJudge := range t[teamID] {
+		for judgeID := range byJudge {
+			scored[judgeID] = true
+		}
+	}
+	return scored
+}
+
+// only оставляет в таблице оценки только по указанным критериям,
+// чтобы оценки отборочных туров не смешивались с финальными
+func (t teamScoreTable) only(criteria []models.Criteria) teamScoreTable {
+	filtered := make(teamScoreTable, len(t))
+	for teamID, byCriteria := range t {
+		for _, criterion := range criteria {
+			if byJudge, ok := byCriteria[criterion.ID]; ok {
+				if filtered[teamID] == nil {
+					filtered[teamID] = make(map[uint]map[uint]models.Score)
+				}
+				filtered[teamID][criterion.ID] = byJudge
+			}
+		}
+	}
+	return filtered
+}
+
+// pendingJudges возвращает судей, которые ещё не оценили команду
+func pendingJudges(judges []hackathonJudge, scored map[uint]bool) []hackathonJudge {
+	pending := make([]hackathonJudge, 0)
+	for _, judge := range judges {
+		if !scored[judge.ID] {
+			pending = append(pending, judge)
+		}
+	}
+	return pending
+}
+
+// aggregateScores сводит оценки разных судей по одному критерию в одно значение
+func aggregateScores(values []float64, method int) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	switch method {
+	case models.ScoreAggregationMedian:
+		middle := len(sorted) / 2
+		if len(sorted)%2 == 0 {
+			return (sorted[middle-1] + sorted[middle]) / 2
+		}
+		return sorted[middle]
+
+	case models.ScoreAggregationTrimmedMean:
+		// Отбрасываем по 20% крайних оценок с каждой стороны, но не меньше одной при трёх и более судьях
+		trim := int(math.Floor(float64(len(sorted)) * 0.2))
+		if trim == 0 && len(sorted) >= 3 {
+			trim = 1
+		}
+		sorted = sorted[trim : len(sorted)-trim]
+	}
+
+	var sum float64
+	for _, value := range sorted {
+		sum += value
+	}
+	return sum / float64(len(sorted))
+}
+
+// criterionFormula - вес и способ нормализации одного критерия
+type criterionFormula struct {
+	Weight        float64
+	Normalization int
+}
+
+// scoringFormula - правила, по которым из оценок судей получается итоговый балл команды
+type scoringFormula struct {
+	Aggregation int
+	Criteria    map[uint]criterionFormula
+
+	// Вес доли голосов зрителей; лидер голосования получает VotingWeight баллов
+	VotingWeight float64
+}
+
+// hackathonFormula возвращает формулу, сохранённую в настройках хакатона и его критериев
+func hackathonFormula(hackathon models.Hackathon, criteria []models.Criteria) scoringFormula {
+	formula := scoringFormula{
+		Aggregation: hackathon.ScoreAggregation,
+		Criteria:    make(map[uint]criterionFormula, len(criteria)),
+	}
+	if hackathon.VotingMode != models.VotingOff {
+		formula.VotingWeight = hackathon.VotingWeight
+	}
+	for _, criterion := range criteria {
+		formula.Criteria[criterion.ID] = criterionFormula{
+			Weight:        criterion.Weight,
+			Normalization: criterion.Normalization,
+		}
+	}
+	return formula
+}
+
+// maxTotal возвращает максимально возможный итоговый балл по формуле.
+// Для z-оценки верхней границы нет, такие критерии в сумму не входят
+func (f scoringFormula) maxTotal(criteria []models.Criteria) float64 {
+	total := f.VotingWeight
+	for _, criterion := range criteria {
+		rule := f.Criteria[criterion.ID]
+		switch rule.Normalization {
+		case models.CriteriaNormalizationNone:
+			total += rule.Weight * float64(criterion.MaxScore)
+		case models.CriteriaNormalizationMinMax:
+			total += rule.Weight
+		}
+	}
+	return total
+}
+
+// criterionResult - сводная оценка команды по одному критерию
+type criterionResult struct {
+	Criteria   models.Criteria
+	Raw        float64
+	Normalized float64
+	Weighted   float64
+	Comments   []string
+	Judges     int
+}
+
+// teamStanding - позиция команды в рейтинге
+type teamStanding struct {
+	Team     models.Team
+	Total    float64
+	Place    int
+	Criteria []criterionResult
+
+	// Баллы голосования зрителей и их вклад в итоговый балл
+	Votes    int
+	Audience float64
+
+	// Правило, по которому команда оказалась ниже предыдущей при равном балле
+	TieBreak string
+}
+
+// criterion возвращает сводную оценку команды по критерию
+func (s *teamStanding) criterion(criteriaID uint) (criterionResult, bool) {
+	for _, result := range s.Criteria {
+		if result.Criteria.ID == criteriaID {
+			return result, true
+		}
+	}
+	return criterionResult{}, false
+}
+
+// tieBreaker - правило разрешения ничьей. Compare возвращает отрицательное число,
+// если команда a должна стоять выше b, положительное - если ниже, и 0, если правило их не различает
+type tieBreaker struct {
+	Name    string
+	Compare func(a, b *teamStanding) int
+}
+
+// criterionTieBreaker ставит выше команду с большим сводным баллом по критерию
+func criterionTieBreaker(criterion models.Criteria) tieBreaker {
+	return tieBreaker{
+		Name: models.TieBreakNames[models.TieBreakCriterion] + " «" + criterion.Name + "»",
+		Compare: func(a, b *teamStanding) int {
+			left, _ := a.criterion(criterion.ID)
+			right, _ := b.criterion(criterion.ID)
+			return compareFloatDesc(left.Raw, right.Raw)
+		},
+	}
+}
+
+// submissionTieBreaker ставит выше команду, раньше сдавшую итоговую версию; команды без сдачи - ниже
+func submissionTieBreaker(submittedAt map[uint]time.Time) tieBreaker {
+	return tieBreaker{
+		Name: models.TieBreakNames[models.TieBreakSubmission],
+		Compare: func(a, b *teamStanding) int {
+			left, leftOK := submittedAt[a.Team.ID]
+			right, rightOK := submittedAt[b.Team.ID]
+			switch {
+			case leftOK && rightOK:
+				return left.Compare(right)
+			case leftOK:
+				return -1
+			case rightOK:
+				return 1
+			}
+			return 0
+		},
+	}
+}
+
+// organizerTieBreaker ставит команды в порядке, заданном организатором; команды вне порядка - ниже
+func organizerTieBreaker(ranks map[uint]int) tieBreaker {
+	return tieBreaker{
+		Name: models.TieBreakNames[models.TieBreakOrganizer],
+		Compare: func(a, b *teamStanding) int {
+			left, leftOK := ranks[a.Team.ID]
+			right, rightOK := ranks[b.Team.ID]
+			switch {
+			case leftOK && rightOK:
+				return left - right
+			case leftOK:
+				return -1
+			case rightOK:
+				return 1
+			}
+			return 0
+		},
+	}
+}
+
+// compareFloatDesc сравнивает баллы по убыванию с учётом погрешности вычислений
+func compareFloatDesc(a, b float64) int {
+	switch {
+	case math.Abs(a-b) < 1e-9:
+		return 0
+	case a > b:
+		return -1
+	}
+	return 1
+}
+
+// judgeStats - среднее и стандартное отклонение оценок одного судьи по критерию
+type judgeStats struct {
+	Mean float64
+	Std  float64
+}
+
+// computeStandings считает итоговые баллы команд по формуле и расставляет места,
+// разрешая ничьи правилами breakers. votes - баллы голосования зрителей по командам
+func computeStandings(teams []models.Team, criteria []models.Criteria, table teamScoreTable, formula scoringFormula, votes map[uint]int, breakers []tieBreaker) []teamStanding {
+	// Для z-оценки нужна статистика каждого судьи по каждому критерию
+	samples := make(map[uint]map[uint][]float64)
+	for _, team := range teams {
+		for criteriaID, byJudge := range table[team.ID] {
+			for judgeID, score := range byJudge {
+				if samples[criteriaID] == nil {
+					samples[criteriaID] = make(map[uint][]float64)
+				}
+				samples[criteriaID][judgeID] = append(samples[criteriaID][judgeID], score.Score)
+			}
+		}
+	}
+
+	stats := make(map[uint]map[uint]judgeStats)
+	for criteriaID, byJudge := range samples {
+		stats[criteriaID] = make(map[uint]judgeStats)
+		for judgeID, values := range byJudge {
+			var mean float64
+			for _, value := range values {
+				mean += value
+			}
+			mean /= float64(len(values))
+
+			var variance float64
+			for _, value := range values {
+				variance += (value - mean) * (value - mean)
+			}
+			variance /= float64(len(values))
+
+			stats[criteriaID][judgeID] = judgeStats{Mean: mean, Std: math.Sqrt(variance)}
+		}
+	}
+
+	normalize := func(criterion models.Criteria, judgeID uint, value float64) float64 {
+		switch formula.Criteria[criterion.ID].Normalization {
+		case models.CriteriaNormalizationMinMax:
+			if criterion.MaxScore <= criterion.MinScore {
+				return 0
+			}
+			return (value - float64(criterion.MinScore)) / float64(criterion.MaxScore-criterion.MinScore)
+		case models.CriteriaNormalizationZScore:
+			s := stats[criterion.ID][judgeID]
+			if s.Std == 0 {
+				return 0
+			}
+			return (value - s.Mean) / s.Std
+		}
+		return value
+	}
+
+	// Голоса переводятся в долю от результата лидера голосования
+	maxVotes := 0
+	for _, team := range teams {
+		if votes[team.ID] > maxVotes {
+			maxVotes = votes[team.ID]
+		}
+	}
+
+	standings := make([]teamStanding, 0, len(teams))
+	for _, team := range teams {
+		standing := teamStanding{Team: team, Criteria: make([]criterionResult, 0, len(criteria))}
+
+		for _, criterion := range criteria {
+			rule, ok := formula.Criteria[criterion.ID]
+			if !ok {
+				continue
+			}
+
+			byJudge := table[team.ID][criterion.ID]
+			if len(byJudge) == 0 {
+				continue
+			}
+
+			raw := make([]float64, 0, len(byJudge))
+			normalized := make([]float64, 0, len(byJudge))
+			comments := make([]string, 0, len(byJudge))
+			for judgeID, score := range byJudge {
+				raw = append(raw, score.Score)
+				normalized = append(normalized, normalize(criterion, judgeID, score.Score))
+				if score.Comment != "" {
+					comments = append(comments, score.Comment)
+				}
+			}
+			sort.Strings(comments)
+
+			result := criterionResult{
+				Criteria:   criterion,
+				Raw:        aggregateScores(raw, formula.Aggregation),
+				Normalized: aggregateScores(normalized, formula.Aggregation),
+				Comments:   comments,
+				Judges:     len(byJudge),
+			}
+			result.Weighted = rule.Weight * result.Normalized
+
+			standing.Total += result.Weighted
+			standing.Criteria = append(standing.Criteria, result)
+		}
+
+		if formula.VotingWeight > 0 && maxVotes > 0 {
+			standing.Votes = votes[team.ID]
+			standing.Audience = formula.VotingWeight * float64(standing.Votes) / float64(maxVotes)
+			standing.Total += standing.Audience
+		}
+
+		standings = append(standings, standing)
+	}
+
+	assignPlaces(standings, breakers)
+	return standings
+}
+
+// assignPlaces сортирует команды по убыванию балла, а равные баллы - по правилам breakers.
+// Команды, которые не различает ни одно правило, делят место
+func assignPlaces(standings []teamStanding, breakers []tieBreaker) {
+	compare := func(a, b *teamStanding) (int, string) {
+		if result := compareFloatDesc(a.Total, b.Total); result != 0 {
+			return result, ""
+		}
+		for _, breaker := range breakers {
+			if result := breaker.Compare(a, b); result != 0 {
+				return result, breaker.Name
+			}
+		}
+		return 0, ""
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		result, _ := compare(&standings[i], &standings[j])
+		return result < 0
+	})
+
+	for i := range standings {
+		if i > 0 {
+			result, rule := compare(&standings[i-1], &standings[i])
+			if result == 0 {
+				standings[i].Place = standings[i-1].Place
+				continue
+			}
+			standings[i].TieBreak = rule
+		}
+		standings[i].Place = i + 1
+	}
+}
@@ -0,0 +1,395 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"net/http"
+	"os"
+	"server/archive"
+	"server/models"
+	"server/models/DTO/similarityDTO"
+	"server/similarity"
+	"server/types"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var errSimilarityRunning = errors.New("проверка на сходство уже идёт")
+
+type SimilarityController struct {
+	DB             *gorm.DB
+	FileController *FileController
+}
+
+func NewSimilarityController(db *gorm.DB, fileController *FileController) *SimilarityController {
+	return &SimilarityController{
+		DB:             db,
+		FileController: fileController,
+	}
+}
+
+func similarityPairURL(hackathonID, pairID uint) string {
+	return fmt.Sprintf("/hackathon/%d/similarity/pairs/%d", hackathonID, pairID)
+}
+
+// StartSimilarityCheck запускает в фоне проверку итоговых сдач хакатона на сходство.
+// Результаты предыдущей проверки заменяются по её завершении
+func (sc *SimilarityController) StartSimilarityCheck(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	var report models.SimilarityReport
+	err = sc.DB.Transaction(func(tx *gorm.DB) error {
+		// Блокировка хакатона не даёт запустить две проверки одновременно
+		var hackathon models.Hackathon
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&hackathon, hackathonID).Error; err != nil {
+			return err
+		}
+
+		err := tx.Where("hackathon_id = ?", hackathonID).First(&report).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		now := time.Now()
+		if report.Running(now) {
+			return errSimilarityRunning
+		}
+
+		report.HackathonID = uint(hackathonID)
+		report.Status = models.SimilarityRunning
+		report.Error = ""
+		report.StartedAt = now
+		report.FinishedAt = nil
+		report.StartedBy = userID
+		return tx.Save(&report).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return
+	}
+	if errors.Is(err, errSimilarityRunning) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Проверка на сходство уже идёт"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при запуске проверки"})
+		return
+	}
+
+	go sc.runSimilarityCheck(report.ID, uint(hackathonID))
+
+	c.JSON(http.StatusAccepted, similarityDTO.Report{
+		Status:       report.Status,
+		StartedAt:    report.StartedAt,
+		SkippedTeams: []similarityDTO.Team{},
+		Pairs:        []similarityDTO.Pair{},
+	})
+}
+
+// runSimilarityCheck извлекает исходники из итоговых сдач, сравнивает их и сохраняет отчёт
+func (sc *SimilarityController) runSimilarityCheck(reportID, hackathonID uint) {
+	fail := func(message string) {
+		now := time.Now()
+		if err := sc.DB.Model(&models.SimilarityReport{}).Where("id = ?", reportID).Updates(map[string]interface{}{
+			"status":      models.SimilarityFailed,
+			"error":       message,
+			"finished_at": &now,
+		}).Error; err != nil {
+			log.Printf("Не удалось сохранить ошибку проверки на сходство хакатона %d: %v", hackathonID, err)
+		}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Сбой проверки на сходство хакатона %d: %v", hackathonID, r)
+			fail("Внутренняя ошибка проверки")
+		}
+	}()
+
+	var teams []models.Team
+	if err := sc.DB.Where("hackathon_id = ?", hackathonID).Preload("Project").Order("id").Find(&teams).Error; err != nil {
+		fail("Ошибка при получении команд")
+		return
+	}
+	if _, err := applyFinalProjects(sc.DB, teams); err != nil {
+		fail("Ошибка при получении версий проектов")
+		return
+	}
+
+	// Документы упорядочены по ID команды, поэтому в паре команда A всегда с меньшим ID
+	var docs []*similarity.Document
+	skipped := []uint{}
+	for _, team := range teams {
+		doc, err := sc.teamDocument(team)
+		if err != nil {
+			log.Printf("Проект команды %d не проверен на сходство: %v", team.ID, err)
+		}
+		if doc == nil || len(doc.Files) == 0 {
+			skipped = append(skipped, team.ID)
+			continue
+		}
+		docs = append(docs, doc)
+	}
+
+	result := similarity.Compare(docs)
+
+	pairs := make([]models.SimilarityPair, len(result.Pairs))
+	for i, pair := range result.Pairs {
+		regions := make([]models.SimilarityRegion, len(pair.Regions))
+		for j, region := range pair.Regions {
+			regions[j] = models.SimilarityRegion(region)
+		}
+		pairs[i] = models.SimilarityPair{
+			HackathonID: hackathonID,
+			TeamAID:     pair.A,
+			TeamBID:     pair.B,
+			Score:       pair.Score(),
+			ScoreA:      pair.ScoreA,
+			ScoreB:      pair.ScoreB,
+			Matches:     pair.Matches,
+			Regions:     regions,
+		}
+	}
+
+	err := sc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("hackathon_id = ?", hackathonID).Delete(&models.SimilarityPair{}).Error; err != nil {
+			return err
+		}
+		if len(pairs) > 0 {
+			if err := tx.CreateInBatches(&pairs, 100).Error; err != nil {
+				return err
+			}
+		}
+		now := time.Now()
+		return tx.Model(&models.SimilarityReport{}).Where("id = ?", reportID).
+			Select("Status", "FinishedAt", "Teams", "SkippedTeams", "Boilerplate", "BoilerplateDocs").
+			Updates(models.SimilarityReport{
+				Status:          models.SimilarityDone,
+				FinishedAt:      &now,
+				Teams:           len(docs),
+				SkippedTeams:    skipped,
+				Boilerplate:     result.Boilerplate,
+				BoilerplateDocs: result.BoilerplateDocs,
+			}).Error
+	})
+	if err != nil {
+		log.Printf("Не удалось сохранить отчёт о сходстве хакатона %d: %v", hackathonID, err)
+		fail("Ошибка при сохранении отчёта")
+	}
+}
+
+// teamDocument строит отпечаток итогового проекта команды. Для команд без архива с кодом возвращается nil
+func (sc *SimilarityController) teamDocument(team models.Team) (*similarity.Document, error) {
+	if team.Project == nil {
+		return nil, nil
+	}
+	file, size, err := sc.FileController.tempFile(*team.Project)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	start := make([]byte, 512)
+	n, err := file.ReadAt(start, 0)
+	if err != nil && n == 0 {
+		return nil, nil
+	}
+	format := archive.Detect(start[:n])
+	if format == "" {
+		return nil, nil
+	}
+	files, err := similarity.Sources(file, size, format)
+	if err != nil {
+		return nil, err
+	}
+	return similarity.Fingerprint(team.ID, files), nil
+}
+
+// similarityTeams возвращает названия команд, включая расформированные
+func similarityTeams(db *gorm.DB, teamIDs []uint) (map[uint]similarityDTO.Team, error) {
+	result := make(map[uint]similarityDTO.Team, len(teamIDs))
+	if len(teamIDs) == 0 {
+		return result, nil
+	}
+	var teams []models.Team
+	if err := db.Unscoped().Select("id", "name").Where("id IN ?", teamIDs).Find(&teams).Error; err != nil {
+		return nil, err
+	}
+	for _, team := range teams {
+		result[team.ID] = similarityDTO.Team{ID: team.ID, Name: team.Name}
+	}
+	return result, nil
+}
+
+func toSimilarityPairDTO(pair models.SimilarityPair, teams map[uint]similarityDTO.Team, regions int) similarityDTO.Pair {
+	return similarityDTO.Pair{
+		ID:          pair.ID,
+		TeamA:       teams[pair.TeamAID],
+		TeamB:       teams[pair.TeamBID],
+		Score:       pair.Score,
+		ScoreA:      pair.ScoreA,
+		ScoreB:      pair.ScoreB,
+		Matches:     pair.Matches,
+		RegionCount: regions,
+		URL:         similarityPairURL(pair.HackathonID, pair.ID),
+	}
+}
+
+// GetSimilarityReport возвращает состояние последней проверки и пары похожих проектов.
+// Параметр min_score (от 0 до 1) скрывает пары с меньшим сходством
+func (sc *SimilarityController) GetSimilarityReport(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+	var minScore float64
+	if value := c.Query("min_score"); value != "" {
+		minScore, err = strconv.ParseFloat(value, 64)
+		if err != nil || minScore < 0 || minScore > 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "min_score должен быть числом от 0 до 1"})
+			return
+		}
+	}
+
+	var report models.SimilarityReport
+	if err := sc.DB.Where("hackathon_id = ?", hackathonID).First(&report).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Проверка на сходство ещё не запускалась"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении отчёта"})
+		return
+	}
+
+	// Фрагменты не загружаются: для списка достаточно их числа
+	type pairRow struct {
+		models.SimilarityPair
+		RegionCount int
+	}
+	var rows []pairRow
+	if err := sc.DB.Model(&models.SimilarityPair{}).
+		Select("id, hackathon_id, team_a_id, team_b_id, score, score_a, score_b, matches, "+
+			"COALESCE(json_array_length(NULLIF(regions, '')::json), 0) AS region_count").
+		Where("hackathon_id = ? AND score >= ?", hackathonID, minScore).
+		Order("score DESC, id").
+		Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении пар команд"})
+		return
+	}
+
+	teamIDs := append([]uint{}, report.SkippedTeams...)
+	for _, row := range rows {
+		teamIDs = append(teamIDs, row.TeamAID, row.TeamBID)
+	}
+	teams, err := similarityTeams(sc.DB, teamIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении команд"})
+		return
+	}
+
+	response := similarityDTO.Report{
+		Status:           report.Status,
+		Error:            report.Error,
+		StartedAt:        report.StartedAt,
+		FinishedAt:       report.FinishedAt,
+		Teams:            report.Teams,
+		SkippedTeams:     make([]similarityDTO.Team, 0, len(report.SkippedTeams)),
+		Boilerplate:      report.Boilerplate,
+		BoilerplateTeams: report.BoilerplateDocs,
+		Pairs:            make([]similarityDTO.Pair, len(rows)),
+	}
+	if report.Status == models.SimilarityRunning && !report.Running(time.Now()) {
+		response.Status = models.SimilarityFailed
+		response.Error = "Проверка прервана"
+	}
+	for _, teamID := range report.SkippedTeams {
+		response.SkippedTeams = append(response.SkippedTeams, teams[teamID])
+	}
+	for i, row := range rows {
+		response.Pairs[i] = toSimilarityPairDTO(row.SimilarityPair, teams, row.RegionCount)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetSimilarityPair возвращает совпадающие фрагменты файлов пары команд
+func (sc *SimilarityController) GetSimilarityPair(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+	pairID, err := strconv.ParseUint(c.Param("pair_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID пары"})
+		return
+	}
+
+	var pair models.SimilarityPair
+	if err := sc.DB.Where("id = ? AND hackathon_id = ?", pairID, hackathonID).First(&pair).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Пара команд не найдена"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении пары команд"})
+		return
+	}
+
+	teams, err := similarityTeams(sc.DB, []uint{pair.TeamAID, pair.TeamBID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении команд"})
+		return
+	}
+
+	detail := similarityDTO.PairDetail{
+		Pair:    toSimilarityPairDTO(pair, teams, len(pair.Regions)),
+		Regions: make([]similarityDTO.Region, len(pair.Regions)),
+	}
+	for i, region := range pair.Regions {
+		detail.Regions[i] = similarityDTO.Region(region)
+	}
+	c.JSON(http.StatusOK, detail)
+}
+
+// similarityTops возвращает для каждой команды самую похожую на неё команду по последней проверке
+func similarityTops(db *gorm.DB, hackathonID uint) (map[uint]similarityDTO.Top, error) {
+	var pairs []models.SimilarityPair
+	if err := db.Omit("Regions").Where("hackathon_id = ?", hackathonID).Find(&pairs).Error; err != nil {
+		return nil, err
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].Score > pairs[j].Score })
+
+	teamIDs := make([]uint, 0, 2*len(pairs))
+	for _, pair := range pairs {
+		teamIDs = append(teamIDs, pair.TeamAID, pair.TeamBID)
+	}
+	teams, err := similarityTeams(db, teamIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	tops := make(map[uint]similarityDTO.Top)
+	for _, pair := range pairs {
+		for _, side := range [][2]uint{{pair.TeamAID, pair.TeamBID}, {pair.TeamBID, pair.TeamAID}} {
+			if _, ok := tops[side[0]]; ok {
+				continue
+			}
+			tops[side[0]] = similarityDTO.Top{
+				Score:  pair.Score,
+				TeamID: side[1],
+				Team:   teams[side[1]].Name,
+				URL:    similarityPairURL(hackathonID, pair.ID),
+			}
+		}
+	}
+	return tops, nil
+}
@@ -0,0 +1,544 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"server/archive"
+	"server/models"
+	"server/models/DTO/fileDTO"
+	"server/models/DTO/submissionDTO"
+	"server/types"
+	"strconv"
+	"time"
+)
+
+var errSubmissionEmpty = errors.New("версия не содержит ни одного артефакта")
+
+// submissionInput - изменения новой версии относительно предыдущей
+type submissionInput struct {
+	Description *string
+	Files       map[int]*multipart.FileHeader
+	Uploads     map[int]models.UploadSession // завершённые возобновляемые загрузки
+	Links       map[int]string
+	Keep        []uint       // nil - перенести все незаменённые артефакты
+	Drop        map[int]bool // типы артефактов, которые не переносятся
+}
+
+// finalSubmissions возвращает итоговые (последние) версии сдачи команд
+func finalSubmissions(db *gorm.DB, teamIDs []uint) (map[uint]models.Submission, error) {
+	result := make(map[uint]models.Submission, len(teamIDs))
+	if len(teamIDs) == 0 {
+		return result, nil
+	}
+
+	var submissions []models.Submission
+	if err := db.Preload("Artifacts.File").
+		Where("(team_id, version) IN (?)", db.Model(&models.Submission{}).
+			Select("team_id, MAX(version)").
+			Where("team_id IN ?", teamIDs).
+			Group("team_id")).
+		Find(&submissions).Error; err != nil {
+		return nil, err
+	}
+
+	for _, submission := range submissions {
+		result[submission.TeamID] = submission
+	}
+	return result, nil
+}
+
+// applyFinalProjects подставляет в Project команд основной файл итоговой версии сдачи.
+// Команды, сдававшие проект до появления версий, сохраняют прежний файл
+func applyFinalProjects(db *gorm.DB, teams []models.Team) (map[uint]models.Submission, error) {
+	teamIDs := make([]uint, len(teams))
+	for i, team := range teams {
+		teamIDs[i] = team.ID
+	}
+
+	submissions, err := finalSubmissions(db, teamIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range teams {
+		if submission, ok := submissions[teams[i].ID]; ok {
+			teams[i].Project = submission.PrimaryFile()
+		}
+	}
+	return submissions, nil
+}
+
+func toSubmissionDTO(submission models.Submission, isFinal bool) submissionDTO.Get {
+	result := submissionDTO.Get{
+		ID:          submission.ID,
+		TeamID:      submission.TeamID,
+		Version:     submission.Version,
+		Description: submission.Description,
+		IsLate:      submission.IsLate,
+		IsFinal:     isFinal,
+		SubmittedAt: submission.CreatedAt,
+		Artifacts:   make([]submissionDTO.Artifact, 0, len(submission.Artifacts)),
+	}
+	if submission.SubmittedBy != nil {
+		result.SubmittedBy = submission.SubmittedBy.Username
+	}
+
+	for _, artifact := range submission.Artifacts {
+		item := submissionDTO.Artifact{
+			ID:   artifact.ID,
+			Kind: artifact.Kind,
+			Name: models.ArtifactKindNames[artifact.Kind],
+			URL:  artifact.URL,
+		}
+		if artifact.File != nil {
+			item.File = &fileDTO.GetShort{
+				ID:   artifact.File.ID,
+				Name: artifact.File.Name,
+				Size: artifact.File.Size,
+				Type: artifact.File.Type,
+			}
+		}
+		result.Artifacts = append(result.Artifacts, item)
+	}
+	return result
+}
+
+// submissionTeam находит команду текущего пользователя и проверяет, что он может сдавать проект
+// и что срок сдачи не истёк. Возвращает хакатон, команду и признак опоздания
+func (hc *HackathonController) submissionTeam(c *gin.Context, userID uint) (models.Hackathon, models.BndUserTeam, bool, bool) {
+	var hackathon models.Hackathon
+	var link models.BndUserTeam
+
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return hackathon, link, false, false
+	}
+
+	if err := hc.DB.First(&hackathon, hackathonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return hackathon, link, false, false
+	}
+
+	link, err = userTeamInHackathon(hc.DB, userID, hackathon.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Вы не состоите в команде на этом хакатоне"})
+		return hackathon, link, false, false
+	}
+
+	if !models.TeamRoleCan(link.TeamRole, models.TeamActionUpload) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Загружать проект может только капитан или его заместитель"})
+		return hackathon, link, false, false
+	}
+
+	// Команда, не прошедшая отборочный тур, в следующих турах проект не сдаёт; чат ей по-прежнему доступен
+	eliminated, err := eliminatedTeams(hc.DB, hackathon.ID, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке итогов туров"})
+		return hackathon, link, false, false
+	}
+	if stepName, out := eliminated[link.TeamID]; out {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Команда не прошла отборочный тур «" + stepName + "» и не может сдавать проект"})
+		return hackathon, link, false, false
+	}
+
+	now := time.Now()
+	open, late := hackathon.SubmissionOpen(now)
+	if !open {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":    "Сдача проекта закрыта",
+			"deadline": hackathon.SubmissionDeadline(),
+			"state":    hackathon.State(now),
+		})
+		return hackathon, link, false, false
+	}
+
+	// Команда меньше минимального размера после окончания регистрации к сдаче не допускается
+	size, err := teamSize(hc.DB, link.TeamID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке размера команды"})
+		return hackathon, link, false, false
+	}
+	if !teamEligible(hackathon, size, now) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":         "Команда меньше минимального размера и не допущена к сдаче проекта",
+			"min_team_size": hackathon.MinTeamSize,
+		})
+		return hackathon, link, false, false
+	}
+
+	return hackathon, link, late, true
+}
+
+// saveSubmission создаёт новую версию сдачи. Неизменённые артефакты предыдущей
+// версии переносятся, сами предыдущие версии не изменяются
+func (hc *HackathonController) saveSubmission(c *gin.Context, hackathon models.Hackathon, teamID, userID uint, input submissionInput, late bool) (models.Submission, error) {
+	var submission models.Submission
+
+	// Архив с кодом проверяется до сохранения: повреждённые и опасные архивы не принимаются
+	var report *archive.Report
+	if header, ok := input.Files[models.ArtifactSourceArchive]; ok {
+		var err error
+		if report, err = inspectUpload(header, archiveRules(hackathon)); err != nil {
+			return submission, err
+		}
+	}
+	if session, ok := input.Uploads[models.ArtifactSourceArchive]; ok {
+		var err error
+		if report, err = hc.FileController.inspectStaged(session, archiveRules(hackathon)); err != nil {
+			return submission, err
+		}
+	}
+
+	// Файлы загружаются до транзакции: версия ссылается на уже сохранённые записи
+	c.Set("userID", userID)
+	uploaded := make(map[int]*models.File, len(input.Files)+len(input.Uploads))
+	removeUploaded := func() {
+		for _, file := range uploaded {
+			if err := hc.FileController.DeleteFile(file); err != nil {
+				log.Printf("Не удалось удалить файл %d: %v", file.ID, err)
+			}
+		}
+	}
+	for kind, header := range input.Files {
+		file, err := hc.FileController.UploadFile(c, header, teamID, "submission")
+		if err != nil {
+			removeUploaded()
+			return submission, fmt.Errorf("ошибка при загрузке файла «%s»: %w", models.ArtifactKindNames[kind], err)
+		}
+		uploaded[kind] = file
+	}
+	// Загрузки частями переносятся в хранилище; сами загрузки удаляются только после сохранения версии
+	for kind, session := range input.Uploads {
+		file, err := hc.FileController.FinalizeUpload(c.Request.Context(), session, teamID, "submission")
+		if err != nil {
+			removeUploaded()
+			return submission, fmt.Errorf("ошибка при загрузке файла «%s»: %w", models.ArtifactKindNames[kind], err)
+		}
+		uploaded[kind] = file
+	}
+
+	// Версия, сданная во время отборочного тура, оценивается в этом туре
+	round, err := currentRound(hc.DB, hackathon.ID, time.Now())
+	if err != nil {
+		removeUploaded()
+		return submission, err
+	}
+
+	err = hc.DB.Transaction(func(tx *gorm.DB) error {
+		// Блокируем команду, чтобы параллельные сдачи не получили один номер версии
+		var team models.Team
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&team, teamID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&team).Association("Project").Find(&team.Project); err != nil {
+			return err
+		}
+
+		var previous models.Submission
+		if err := tx.Preload("Artifacts").Where("team_id = ?", teamID).Order("version DESC").First(&previous).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			// Проект, загруженный до появления версий, становится частью первой версии
+			if team.Project != nil {
+				fileID := team.Project.ID
+				previous.Artifacts = []models.SubmissionArtifact{{Kind: models.ArtifactSourceArchive, FileID: &fileID}}
+			}
+		}
+
+		submission = models.Submission{
+			TeamID:        teamID,
+			HackathonID:   hackathon.ID,
+			Version:       previous.Version + 1,
+			SubmittedByID: userID,
+			Description:   previous.Description,
+			IsLate:        late,
+		}
+		if round != nil {
+			submission.StepID = &round.ID
+		}
+		if input.Description != nil {
+			submission.Description = *input.Description
+		}
+
+		keep := make(map[uint]bool, len(input.Keep))
+		for _, id := range input.Keep {
+			keep[id] = true
+		}
+		for _, artifact := range previous.Artifacts {
+			_, replacedFile := uploaded[artifact.Kind]
+			_, replacedLink := input.Links[artifact.Kind]
+			if replacedFile || replacedLink || input.Drop[artifact.Kind] {
+				continue
+			}
+			if input.Keep != nil && !keep[artifact.ID] {
+				continue
+			}
+			submission.Artifacts = append(submission.Artifacts, models.SubmissionArtifact{
+				Kind:   artifact.Kind,
+				URL:    artifact.URL,
+				FileID: artifact.FileID,
+			})
+		}
+
+		for kind, file := range uploaded {
+			fileID := file.ID
+			submission.Artifacts = append(submission.Artifacts, models.SubmissionArtifact{Kind: kind, FileID: &fileID})
+		}
+		for kind, url := range input.Links {
+			submission.Artifacts = append(submission.Artifacts, models.SubmissionArtifact{Kind: kind, URL: url})
+		}
+
+		if len(submission.Artifacts) == 0 && submission.Description == "" {
+			return errSubmissionEmpty
+		}
+
+		if err := tx.Create(&submission).Error; err != nil {
+			return err
+		}
+		if report != nil {
+			return tx.Create(toInspectionModel(uploaded[models.ArtifactSourceArchive].ID, report)).Error
+		}
+		return nil
+	})
+	if err != nil {
+		removeUploaded()
+		return submission, err
+	}
+
+	for _, session := range input.Uploads {
+		if err := hc.FileController.DiscardUpload(session); err != nil {
+			log.Printf("Не удалось удалить загрузку %s: %v", session.Token, err)
+		}
+	}
+
+	return submission, nil
+}
+
+// CreateSubmission сдаёт новую версию проекта команды
+func (hc *HackathonController) CreateSubmission(c *gin.Context) {
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	hackathon, link, late, ok := hc.submissionTeam(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка при парсинге формы: " + err.Error()})
+		return
+	}
+
+	var dto submissionDTO.Create
+	if data := c.Request.FormValue("data"); data != "" {
+		if err := json.Unmarshal([]byte(data), &dto); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка при разборе JSON данных"})
+			return
+		}
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка валидации", "details": err.Error()})
+		return
+	}
+
+	input := submissionInput{
+		Description: dto.Description,
+		Files:       map[int]*multipart.FileHeader{},
+		Uploads:     map[int]models.UploadSession{},
+		Links:       map[int]string{},
+		Keep:        dto.Keep,
+	}
+
+	for _, kind := range []int{models.ArtifactSourceArchive, models.ArtifactSlides} {
+		if header, err := c.FormFile("file_" + strconv.Itoa(kind)); err == nil {
+			input.Files[kind] = header
+		}
+	}
+
+	for _, uploadInput := range dto.Uploads {
+		_, inForm := input.Files[uploadInput.Kind]
+		if _, exists := input.Uploads[uploadInput.Kind]; exists || inForm {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Файл «" + models.ArtifactKindNames[uploadInput.Kind] + "» указан несколько раз"})
+			return
+		}
+
+		var session models.UploadSession
+		if err := hc.DB.Where("token = ? AND user_id = ?", uploadInput.ID, userID).First(&session).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Загрузка файла «" + models.ArtifactKindNames[uploadInput.Kind] + "» не найдена"})
+			return
+		}
+		if !session.Complete() || session.Expired(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Загрузка файла «" + models.ArtifactKindNames[uploadInput.Kind] + "» не завершена"})
+			return
+		}
+		input.Uploads[uploadInput.Kind] = session
+	}
+
+	for _, linkInput := range dto.Links {
+		if _, exists := input.Links[linkInput.Kind]; exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Ссылка «" + models.ArtifactKindNames[linkInput.Kind] + "» указана несколько раз"})
+			return
+		}
+		input.Links[linkInput.Kind] = linkInput.URL
+	}
+
+	submission, err := hc.saveSubmission(c, hackathon, link.TeamID, userID, input, late)
+	if err != nil {
+		if errors.Is(err, errSubmissionEmpty) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Добавьте хотя бы один файл, ссылку или описание"})
+			return
+		}
+		if errors.Is(err, errArchiveRejected) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении версии", "details": err.Error()})
+		return
+	}
+
+	if err := hc.DB.Preload("Artifacts.File").Preload("SubmittedBy").First(&submission, submission.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении версии"})
+		return
+	}
+
+	result := []submissionDTO.Get{toSubmissionDTO(submission, true)}
+	if err := applyInspections(hc.DB, hackathon.ID, result); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении проверки архива"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result[0])
+}
+
+// submissionHistory возвращает все версии сдачи команды и сроки сдачи.
+// Непустой blindCode обезличивает версии для судьи при слепой оценке
+func (hc *HackathonController) submissionHistory(c *gin.Context, hackathon models.Hackathon, teamID uint, blindCode string) {
+	var submissions []models.Submission
+	if err := hc.DB.Preload("Artifacts.File").Preload("SubmittedBy").
+		Where("team_id = ?", teamID).
+		Order("version DESC").
+		Find(&submissions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении версий", "details": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	deadline := hackathon.SubmissionDeadline()
+	graceEnd := deadline.Add(time.Duration(hackathon.SubmissionGraceMinutes) * time.Minute)
+	open, late := hackathon.SubmissionOpen(now)
+
+	history := submissionDTO.History{
+		Versions: make([]submissionDTO.Get, len(submissions)),
+		Deadline: deadline,
+		GraceEnd: graceEnd,
+		Open:     open,
+		Late:     late,
+	}
+	if !open && now.After(graceEnd) {
+		history.FrozenAt = &graceEnd
+	}
+	for i, submission := range submissions {
+		history.Versions[i] = toSubmissionDTO(submission, i == 0)
+		if blindCode != "" {
+			history.Versions[i] = blindSubmission(history.Versions[i], hackathon.ID, blindCode)
+		}
+	}
+	if err := applyInspections(hc.DB, hackathon.ID, history.Versions); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении проверки архивов", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetSubmissions возвращает историю версий команды текущего пользователя
+func (hc *HackathonController) GetSubmissions(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	var hackathon models.Hackathon
+	if err := hc.DB.First(&hackathon, hackathonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return
+	}
+
+	link, err := userTeamInHackathon(hc.DB, userID, hackathon.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Вы не состоите в команде на этом хакатоне"})
+		return
+	}
+
+	hc.submissionHistory(c, hackathon, link.TeamID, "")
+}
+
+// GetTeamSubmissions возвращает историю версий команды для организаторов и судей. Судья видит только
+// назначенные ему команды без конфликта интересов; при слепой оценке он запрашивает команду по коду
+// и получает обезличенные версии
+func (hc *HackathonController) GetTeamSubmissions(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	var hackathon models.Hackathon
+	if err := hc.DB.First(&hackathon, hackathonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return
+	}
+
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	var link models.BndUserHackathon
+	if err := hc.DB.Where("user_id = ? AND hackathon_id = ?", userID, hackathon.ID).First(&link).Error; err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "У вас нет доступа к сдачам этого хакатона"})
+		return
+	}
+
+	team, err := findHackathonTeam(hc.DB, hackathon.ID, c.Param("team_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Команда не найдена"})
+		return
+	}
+
+	if link.HackathonRole == 2 {
+		access, err := loadJudgeAccess(hc.DB, hackathon.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке назначений судьи"})
+			return
+		}
+		if reason := access.check(team.ID, userID); reason != "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": reason})
+			return
+		}
+	}
+
+	blindCode := ""
+	if blindFor(hackathon, link.HackathonRole) {
+		// По ID команды судья мог бы сопоставить её с кодом
+		if team.BlindCode != c.Param("team_id") {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Во время слепой оценки команда запрашивается по коду"})
+			return
+		}
+		blindCode = team.BlindCode
+	}
+
+	hc.submissionHistory(c, hackathon, team.ID, blindCode)
+}
@@ -0,0 +1,405 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"server/models"
+	"server/models/DTO/teamDTO"
+	"server/models/DTO/teamJoinRequestDTO"
+	"server/types"
+	"strconv"
+)
+
+var (
+	errAlreadyInTeam  = errors.New("пользователь уже состоит в команде")
+	errRequestHandled = errors.New("заявка уже рассмотрена")
+)
+
+// cleanupPendingMembership убирает заявки и приглашения пользователя, потерявшие смысл
+// после его вступления в команду хакатона: свои заявки удаляются, чужие приглашения отклоняются
+func cleanupPendingMembership(tx *gorm.DB, userID, hackathonID uint) error {
+	teamsOfHackathon := tx.Model(&models.Team{}).Select("id").Where("hackathon_id = ?", hackathonID)
+
+	if err := tx.Where("user_id = ? AND status = ? AND team_id IN (?)", userID, models.TeamJoinRequestPending, teamsOfHackathon).
+		Delete(&models.TeamJoinRequest{}).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&models.TeamInvite{}).
+		Where("user_id = ? AND status = 0 AND team_id IN (?)", userID, teamsOfHackathon).
+		Update("status", -1).Error
+}
+
+// userTeamInHackathon возвращает связь пользователя с командой хакатона
+func userTeamInHackathon(db *gorm.DB, userID, hackathonID uint) (models.BndUserTeam, error) {
+	var link models.BndUserTeam
+	err := db.Joins("JOIN teams ON bnd_user_teams.team_id = teams.id").
+		Where("bnd_user_teams.user_id = ? AND teams.hackathon_id = ? AND teams.deleted_at IS NULL", userID, hackathonID).
+		First(&link).Error
+	return link, err
+}
+
+// GetTeams возвращает команды хакатона с информацией о свободных местах
+func (hc *HackathonController) GetTeams(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор хакатона"})
+		return
+	}
+
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	var hackathon models.Hackathon
+	if err := hc.DB.First(&hackathon, hackathonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return
+	}
+
+	var teams []struct {
+		ID   uint
+		Name string
+		Size int64
+	}
+	if err := hc.DB.Model(&models.Team{}).
+		Select("teams.id, teams.name, COUNT(bnd_user_teams.user_id) AS size").
+		Joins("LEFT JOIN bnd_user_teams ON bnd_user_teams.team_id = teams.id").
+		Where("teams.hackathon_id = ?", hackathonID).
+		Group("teams.id, teams.name").
+		Order("teams.name").
+		Scan(&teams).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении команд", "details": err.Error()})
+		return
+	}
+
+	// Последние заявки текущего пользователя в команды хакатона
+	var requests []models.TeamJoinRequest
+	if err := hc.DB.Joins("JOIN teams ON team_join_requests.team_id = teams.id").
+		Where("team_join_requests.user_id = ? AND teams.hackathon_id = ?", userID, hackathonID).
+		Order("team_join_requests.created_at").
+		Find(&requests).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении заявок", "details": err.Error()})
+		return
+	}
+	requestStatus := make(map[uint]int, len(requests))
+	for _, request := range requests {
+		requestStatus[request.TeamID] = request.Status
+	}
+
+	result := make([]teamDTO.ListItem, 0, len(teams))
+	for _, team := range teams {
+		item := teamDTO.ListItem{
+			ID:           team.ID,
+			Name:         team.Name,
+			Size:         team.Size,
+			MaxSize:      hackathon.MaxTeamSize,
+			HasFreeSlots: hackathon.MaxTeamSize <= 0 || team.Size < int64(hackathon.MaxTeamSize),
+		}
+		if status, ok := requestStatus[team.ID]; ok {
+			item.RequestStatus = &status
+		}
+		result = append(result, item)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CreateJoinRequest отправляет капитану команды заявку на вступление
+func (hc *HackathonController) CreateJoinRequest(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор хакатона"})
+		return
+	}
+
+	teamID, err := strconv.ParseUint(c.Param("team_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор команды"})
+		return
+	}
+
+	var dto teamJoinRequestDTO.Create
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка валидации", "details": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	var participant models.BndUserHackathon
+	if err := hc.DB.Where("user_id = ? AND hackathon_id = ?", userID, hackathonID).First(&participant).Error; err != nil || participant.HackathonRole != 1 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Подавать заявки в команды могут только участники хакатона"})
+		return
+	}
+
+	var team models.Team
+	if err := hc.DB.Where("id = ? AND hackathon_id = ?", teamID, hackathonID).First(&team).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Команда не найдена"})
+		return
+	}
+
+	if _, err := userTeamInHackathon(hc.DB, userID, uint(hackathonID)); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Вы уже состоите в команде этого хакатона"})
+		return
+	}
+
+	var hackathon models.Hackathon
+	if err := hc.DB.First(&hackathon, hackathonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return
+	}
+
+	full, err := teamIsFull(hc.DB, hackathon, team.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке размера команды"})
+		return
+	}
+	if full {
+		c.JSON(http.StatusConflict, gin.H{"error": "В команде нет свободных мест", "max_team_size": hackathon.MaxTeamSize})
+		return
+	}
+
+	var existing models.TeamJoinRequest
+	if err := hc.DB.Where("user_id = ? AND team_id = ? AND status = ?", userID, team.ID, models.TeamJoinRequestPending).
+		First(&existing).Error; err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Заявка в эту команду уже отправлена"})
+		return
+	}
+
+	request := models.TeamJoinRequest{
+		UserID:  userID,
+		TeamID:  team.ID,
+		Message: dto.Message,
+		Status:  models.TeamJoinRequestPending,
+	}
+	if err := hc.DB.Create(&request).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании заявки", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Заявка отправлена капитану команды", "id": request.ID})
+}
+
+// GetTeamJoinRequests возвращает заявки в команду текущего пользователя; доступно капитану и заместителям
+func (hc *HackathonController) GetTeamJoinRequests(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор хакатона"})
+		return
+	}
+
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	link, err := userTeamInHackathon(hc.DB, userID, uint(hackathonID))
+	if err != nil || !models.TeamRoleCan(link.TeamRole, models.TeamActionInvite) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Заявки может просматривать только капитан или его заместитель"})
+		return
+	}
+
+	var requests []models.TeamJoinRequest
+	if err := hc.DB.Preload("User").Preload("Team").
+		Where("team_id = ? AND status = ?", link.TeamID, models.TeamJoinRequestPending).
+		Order("created_at").
+		Find(&requests).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении заявок", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, toJoinRequestDTOs(requests))
+}
+
+// GetMyJoinRequests возвращает заявки текущего пользователя в команды хакатона
+func (hc *HackathonController) GetMyJoinRequests(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор хакатона"})
+		return
+	}
+
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	var requests []models.TeamJoinRequest
+	if err := hc.DB.Joins("JOIN teams ON team_join_requests.team_id = teams.id").
+		Where("team_join_requests.user_id = ? AND teams.hackathon_id = ?", userID, hackathonID).
+		Preload("User").Preload("Team").
+		Order("team_join_requests.created_at DESC").
+		Find(&requests).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении заявок", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, toJoinRequestDTOs(requests))
+}
+
+func toJoinRequestDTOs(requests []models.TeamJoinRequest) []teamJoinRequestDTO.Get {
+	result := make([]teamJoinRequestDTO.Get, len(requests))
+	for i, request := range requests {
+		result[i] = teamJoinRequestDTO.Get{
+			Id:        request.ID,
+			UserID:    request.UserID,
+			Username:  request.User.Username,
+			TeamID:    request.TeamID,
+			TeamName:  request.Team.Name,
+			Message:   request.Message,
+			Status:    request.Status,
+			CreatedAt: request.CreatedAt,
+		}
+	}
+	return result
+}
+
+// captainJoinRequest загружает заявку в команду хакатона и проверяет, что текущий пользователь может её рассмотреть
+func (hc *HackathonController) captainJoinRequest(c *gin.Context) (models.TeamJoinRequest, uint, bool) {
+	var request models.TeamJoinRequest
+
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор хакатона"})
+		return request, 0, false
+	}
+
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	if err := hc.DB.Joins("JOIN teams ON team_join_requests.team_id = teams.id").
+		Where("team_join_requests.id = ? AND teams.hackathon_id = ?", c.Param("request_id"), hackathonID).
+		First(&request).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Заявка не найдена"})
+		return request, 0, false
+	}
+
+	var link models.BndUserTeam
+	if err := hc.DB.Where("user_id = ? AND team_id = ?", userID, request.TeamID).First(&link).Error; err != nil || !models.TeamRoleCan(link.TeamRole, models.TeamActionInvite) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Заявки может рассматривать только капитан или его заместитель"})
+		return request, 0, false
+	}
+
+	if request.Status != models.TeamJoinRequestPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Заявка уже рассмотрена"})
+		return request, 0, false
+	}
+
+	return request, uint(hackathonID), true
+}
+
+// AcceptJoinRequest принимает заявку: пользователь становится участником команды
+func (hc *HackathonController) AcceptJoinRequest(c *gin.Context) {
+	request, hackathonID, ok := hc.captainJoinRequest(c)
+	if !ok {
+		return
+	}
+
+	err := hc.DB.Transaction(func(tx *gorm.DB) error {
+		// Блокируем команду, чтобы параллельные вступления не превысили лимит
+		var team models.Team
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&team, request.TeamID).Error; err != nil {
+			return err
+		}
+
+		// Перечитываем заявку внутри транзакции: её могли отменить или удалить при очистке
+		if err := tx.First(&request, request.ID).Error; err != nil {
+			return err
+		}
+		if request.Status != models.TeamJoinRequestPending {
+			return errRequestHandled
+		}
+
+		if _, err := userTeamInHackathon(tx, request.UserID, hackathonID); err == nil {
+			return errAlreadyInTeam
+		}
+
+		var hackathon models.Hackathon
+		if err := tx.First(&hackathon, hackathonID).Error; err != nil {
+			return err
+		}
+
+		full, err := teamIsFull(tx, hackathon, team.ID)
+		if err != nil {
+			return err
+		}
+		if full {
+			return errTeamFull
+		}
+
+		if err := tx.Create(&models.BndUserTeam{
+			UserID:   request.UserID,
+			TeamID:   team.ID,
+			TeamRole: models.TeamRoleMember,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&request).Update("status", models.TeamJoinRequestAccepted).Error; err != nil {
+			return err
+		}
+
+		return cleanupPendingMembership(tx, request.UserID, hackathonID)
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, errTeamFull):
+			c.JSON(http.StatusConflict, gin.H{"error": "В команде нет свободных мест"})
+		case errors.Is(err, errAlreadyInTeam):
+			c.JSON(http.StatusConflict, gin.H{"error": "Пользователь уже состоит в команде этого хакатона"})
+		case errors.Is(err, errRequestHandled), errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusConflict, gin.H{"error": "Заявка уже рассмотрена или отменена"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при принятии заявки", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Заявка принята"})
+}
+
+// DeclineJoinRequest отклоняет заявку на вступление в команду
+func (hc *HackathonController) DeclineJoinRequest(c *gin.Context) {
+	request, _, ok := hc.captainJoinRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := hc.DB.Model(&request).Update("status", models.TeamJoinRequestDeclined).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при отклонении заявки", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Заявка отклонена"})
+}
+
+// CancelJoinRequest отзывает собственную заявку, пока она не рассмотрена
+func (hc *HackathonController) CancelJoinRequest(c *gin.Context) {
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	var request models.TeamJoinRequest
+	if err := hc.DB.First(&request, c.Param("request_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Заявка не найдена"})
+		return
+	}
+
+	if request.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "У вас нет прав на отмену этой заявки"})
+		return
+	}
+
+	if request.Status != models.TeamJoinRequestPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Заявка уже рассмотрена"})
+		return
+	}
+
+	if err := hc.DB.Delete(&request).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при отмене заявки", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Заявка отменена"})
+}
@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"server/models"
+	"server/types"
+	"strconv"
+)
+
+// teamSuccession - итог ухода капитана из команды
+type teamSuccession struct {
+	CaptainID uint // новый капитан
+	Disbanded bool // в команде никого не осталось, и она удалена
+}
+
+// passCaptaincy передаёт капитанство участнику с наибольшим стажем в команде.
+// Если участников не осталось, команда расформировывается
+func passCaptaincy(tx *gorm.DB, teamID uint) (teamSuccession, error) {
+	var successor models.BndUserTeam
+	err := tx.Where("team_id = ?", teamID).
+		Order("created_at ASC NULLS FIRST, user_id").
+		First(&successor).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if err := tx.Where("team_id = ?", teamID).Delete(&models.TeamInvite{}).Error; err != nil {
+			return teamSuccession{}, err
+		}
+		if err := tx.Where("team_id = ?", teamID).Delete(&models.TeamJoinRequest{}).Error; err != nil {
+			return teamSuccession{}, err
+		}
+		if err := tx.Delete(&models.Team{Model: gorm.Model{ID: teamID}}).Error; err != nil {
+			return teamSuccession{}, err
+		}
+		return teamSuccession{Disbanded: true}, nil
+	}
+	if err != nil {
+		return teamSuccession{}, err
+	}
+
+	if err := setTeamRole(tx, teamID, successor.UserID, models.TeamRoleCaptain); err != nil {
+		return teamSuccession{}, err
+	}
+	return teamSuccession{CaptainID: successor.UserID}, nil
+}
+
+func setTeamRole(tx *gorm.DB, teamID, userID uint, role int) error {
+	return tx.Model(&models.BndUserTeam{}).
+		Where("team_id = ? AND user_id = ?", teamID, userID).
+		Update("team_role", role).Error
+}
+
+// teamRoleChange загружает команду текущего пользователя и участника из URL.
+// Менять роли может только капитан; изменить собственную роль нельзя
+func (hc *HackathonController) teamRoleChange(c *gin.Context) (captain, member models.BndUserTeam, ok bool) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор хакатона"})
+		return captain, member, false
+	}
+
+	memberID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор пользователя"})
+		return captain, member, false
+	}
+
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	captain, err = userTeamInHackathon(hc.DB, userID, uint(hackathonID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Вы не состоите в команде этого хакатона"})
+		return captain, member, false
+	}
+
+	if !models.TeamRoleCan(captain.TeamRole, models.TeamActionManageRoles) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Управлять ролями в команде может только капитан"})
+		return captain, member, false
+	}
+
+	if uint(memberID) == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Нельзя изменить собственную роль"})
+		return captain, member, false
+	}
+
+	if err := hc.DB.Where("user_id = ? AND team_id = ?", memberID, captain.TeamID).First(&member).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Указанный пользователь не является участником вашей команды"})
+		return captain, member, false
+	}
+
+	return captain, member, true
+}
+
+// TransferCaptaincy передаёт капитанство другому участнику команды;
+// бывший капитан становится заместителем
+func (hc *HackathonController) TransferCaptaincy(c *gin.Context) {
+	captain, member, ok := hc.teamRoleChange(c)
+	if !ok {
+		return
+	}
+
+	err := hc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := setTeamRole(tx, captain.TeamID, captain.UserID, models.TeamRoleCoCaptain); err != nil {
+			return err
+		}
+		return setTeamRole(tx, member.TeamID, member.UserID, models.TeamRoleCaptain)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при передаче капитанства", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Капитанство передано", "captain_id": member.UserID})
+}
+
+// PromoteCoCaptain назначает участника заместителем капитана
+func (hc *HackathonController) PromoteCoCaptain(c *gin.Context) {
+	_, member, ok := hc.teamRoleChange(c)
+	if !ok {
+		return
+	}
+
+	if member.TeamRole != models.TeamRoleMember {
+		c.JSON(http.StatusConflict, gin.H{"error": "Заместителем можно назначить только участника без роли", "team_role": member.TeamRole})
+		return
+	}
+
+	if err := setTeamRole(hc.DB, member.TeamID, member.UserID, models.TeamRoleCoCaptain); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при назначении заместителя", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Участник назначен заместителем капитана", "team_role": models.TeamRoleCoCaptain})
+}
+
+// DemoteCoCaptain снимает с участника роль заместителя капитана
+func (hc *HackathonController) DemoteCoCaptain(c *gin.Context) {
+	_, member, ok := hc.teamRoleChange(c)
+	if !ok {
+		return
+	}
+
+	if member.TeamRole != models.TeamRoleCoCaptain {
+		c.JSON(http.StatusConflict, gin.H{"error": "Пользователь не является заместителем капитана"})
+		return
+	}
+
+	if err := setTeamRole(hc.DB, member.TeamID, member.UserID, models.TeamRoleMember); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при снятии заместителя", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Участник больше не является заместителем капитана", "team_role": models.TeamRoleMember})
+}
+
+// GetTeamPermissions возвращает матрицу прав ролей в команде
+func (hc *HackathonController) GetTeamPermissions(c *gin.Context) {
+	result := make([]gin.H, 0, len(models.TeamRoleNames))
+	for _, role := range []int{models.TeamRoleCaptain, models.TeamRoleCoCaptain, models.TeamRoleMember} {
+		result = append(result, gin.H{
+			"team_role": role,
+			"name":      models.TeamRoleNames[role],
+			"actions":   models.TeamPermissions[role],
+		})
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,382 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"server/models"
+	"server/models/DTO/uploadDTO"
+	"server/staging"
+	"server/types"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Возобновляемые загрузки реализуют ядро протокола tus 1.0.0 и расширения creation, expiration,
+// checksum и termination: https://tus.io/protocols/resumable-upload
+const (
+	tusVersion    = "1.0.0"
+	tusExtensions = "creation,expiration,checksum,termination"
+	// Код ответа tus при несовпадении контрольной суммы
+	statusChecksumMismatch = 460
+	// Ограничения на незавершённые загрузки одного пользователя: число и суммарный объявленный размер
+	maxActiveUploads     = 20
+	maxActiveUploadBytes = 4 * maxFileSize
+)
+
+var errUploadChecksum = errors.New("контрольная сумма файла не совпадает, загрузите его заново")
+
+// Части одной загрузки принимаются по очереди: параллельный запрос получает отказ.
+// Загрузка отмечена здесь только пока её обрабатывает запрос, поэтому записи не накапливаются
+var uploadLocks sync.Map
+
+// lockUpload занимает загрузку. Возвращает false, если её уже обрабатывает другой запрос
+func lockUpload(token string) bool {
+	_, busy := uploadLocks.LoadOrStore(token, struct{}{})
+	return !busy
+}
+
+func unlockUpload(token string) {
+	uploadLocks.Delete(token)
+}
+
+type UploadController struct {
+	DB             *gorm.DB
+	FileController *FileController
+}
+
+func NewUploadController(db *gorm.DB, fileController *FileController) *UploadController {
+	return &UploadController{
+		DB:             db,
+		FileController: fileController,
+	}
+}
+
+// uploadLocation - адрес загрузки, который клиент использует в следующих запросах
+func uploadLocation(token string) string {
+	base := os.Getenv("UPLOAD_PUBLIC_URL")
+	if base == "" {
+		base = "/api/upload"
+	}
+	return strings.TrimRight(base, "/") + "/" + token
+}
+
+func toUploadDTO(session models.UploadSession) uploadDTO.Get {
+	return uploadDTO.Get{
+		ID:        session.Token,
+		Name:      session.Name,
+		Size:      session.Size,
+		Offset:    session.Offset,
+		Complete:  session.Complete(),
+		SHA256:    session.SHA256,
+		ExpiresAt: session.ExpiresAt,
+	}
+}
+
+// TusHeaders добавляет заголовки протокола к ответам и отклоняет запросы несовместимой версии
+func (uc *UploadController) TusHeaders(c *gin.Context) {
+	c.Header("Tus-Resumable", tusVersion)
+	if c.Request.Method == http.MethodOptions {
+		return
+	}
+	if version := c.GetHeader("Tus-Resumable"); version != "" && version != tusVersion {
+		c.Header("Tus-Version", tusVersion)
+		c.AbortWithStatusJSON(http.StatusPreconditionFailed, gin.H{"error": "Неподдерживаемая версия протокола tus"})
+	}
+}
+
+// GetUploadOptions сообщает возможности сервера
+func (uc *UploadController) GetUploadOptions(c *gin.Context) {
+	c.Header("Tus-Version", tusVersion)
+	c.Header("Tus-Extension", tusExtensions)
+	c.Header("Tus-Max-Size", strconv.FormatInt(maxFileSize, 10))
+	c.Header("Tus-Checksum-Algorithm", strings.Join(staging.Algorithms, ","))
+	c.Status(http.StatusNoContent)
+}
+
+// parseUploadMetadata разбирает заголовок Upload-Metadata: пары "ключ значение-в-base64" через запятую
+func parseUploadMetadata(header string) (map[string]string, error) {
+	metadata := map[string]string{}
+	for _, pair := range strings.Split(header, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, encoded, _ := strings.Cut(pair, " ")
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("значение %q должно быть в base64", key)
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
+
+// CreateUpload создаёт загрузку. Размер передаётся в Upload-Length, имя файла - в метаданных filename,
+// SHA-256 всего файла в hex можно передать в метаданных checksum: тогда он проверяется после приёма данных
+func (uc *UploadController) CreateUpload(c *gin.Context) {
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	size, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || size <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Укажите размер файла в заголовке Upload-Length"})
+		return
+	}
+	if size > maxFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Размер файла превышает допустимый предел"})
+		return
+	}
+
+	metadata, err := parseUploadMetadata(c.GetHeader("Upload-Metadata"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка при разборе Upload-Metadata: " + err.Error()})
+		return
+	}
+	name := filepath.Base(metadata["filename"])
+	if metadata["filename"] == "" || len(name) > 255 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Укажите имя файла в метаданных filename (до 255 символов)"})
+		return
+	}
+	checksum := strings.ToLower(metadata["checksum"])
+	if _, err := hex.DecodeString(checksum); err != nil || (checksum != "" && len(checksum) != 64) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Контрольная сумма checksum должна быть SHA-256 в hex"})
+		return
+	}
+
+	var active struct {
+		Count int64
+		Size  int64
+	}
+	if err := uc.DB.Model(&models.UploadSession{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(size), 0) AS size").
+		Where("user_id = ? AND expires_at > ?", userID, time.Now()).
+		Scan(&active).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке незавершённых загрузок"})
+		return
+	}
+	if active.Count >= maxActiveUploads || active.Size+size > maxActiveUploadBytes {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Слишком много незавершённых загрузок: завершите или удалите часть из них"})
+		return
+	}
+
+	session := models.UploadSession{
+		Token:     uuid.New().String(),
+		UserID:    userID,
+		Name:      name,
+		Size:      size,
+		Checksum:  checksum,
+		ExpiresAt: time.Now().Add(models.UploadSessionLifetime),
+	}
+	if err := uc.DB.Create(&session).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании загрузки"})
+		return
+	}
+
+	c.Header("Location", uploadLocation(session.Token))
+	c.Header("Upload-Expires", session.ExpiresAt.UTC().Format(http.TimeFormat))
+	c.JSON(http.StatusCreated, toUploadDTO(session))
+}
+
+// findUpload находит действующую загрузку текущего пользователя и при ошибке отвечает клиенту
+func (uc *UploadController) findUpload(c *gin.Context) (models.UploadSession, bool) {
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	var session models.UploadSession
+	if err := uc.DB.Where("token = ? AND user_id = ?", c.Param("upload_id"), userID).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Загрузка не найдена"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении загрузки"})
+		}
+		return session, false
+	}
+	if session.Expired(time.Now()) {
+		c.JSON(http.StatusGone, gin.H{"error": "Срок загрузки истёк, начните её заново"})
+		return session, false
+	}
+	return session, true
+}
+
+func setUploadHeaders(c *gin.Context, session models.UploadSession) {
+	c.Header("Upload-Offset", strconv.FormatInt(session.Offset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(session.Size, 10))
+	c.Header("Upload-Expires", session.ExpiresAt.UTC().Format(http.TimeFormat))
+	c.Header("Cache-Control", "no-store")
+}
+
+// HeadUpload сообщает, сколько данных уже получено: с этого места клиент продолжает загрузку
+func (uc *UploadController) HeadUpload(c *gin.Context) {
+	session, ok := uc.findUpload(c)
+	if !ok {
+		return
+	}
+	setUploadHeaders(c, session)
+	c.Status(http.StatusOK)
+}
+
+// GetUpload возвращает состояние загрузки
+func (uc *UploadController) GetUpload(c *gin.Context) {
+	session, ok := uc.findUpload(c)
+	if !ok {
+		return
+	}
+	setUploadHeaders(c, session)
+	c.JSON(http.StatusOK, toUploadDTO(session))
+}
+
+// PatchUpload принимает очередную часть файла. Смещение в Upload-Offset должно совпадать с числом
+// уже полученных байт. Необязательный заголовок Upload-Checksum проверяет часть целиком
+func (uc *UploadController) PatchUpload(c *gin.Context) {
+	if c.ContentType() != "application/offset+octet-stream" {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Часть файла передаётся с типом application/offset+octet-stream"})
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Укажите смещение части в заголовке Upload-Offset"})
+		return
+	}
+	var digest *staging.Digest
+	if header := c.GetHeader("Upload-Checksum"); header != "" {
+		if digest, err = staging.ParseChecksum(header); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка в Upload-Checksum: " + err.Error()})
+			return
+		}
+	}
+
+	session, ok := uc.findUpload(c)
+	if !ok {
+		return
+	}
+	if !lockUpload(session.Token) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Загрузка уже принимает данные в другом запросе"})
+		return
+	}
+	defer unlockUpload(session.Token)
+
+	// Предыдущий запрос мог изменить загрузку, пока эта ещё не была занята
+	if session, ok = uc.findUpload(c); !ok {
+		return
+	}
+	if offset != session.Offset {
+		setUploadHeaders(c, session)
+		c.JSON(http.StatusConflict, gin.H{"error": "Смещение не совпадает с числом полученных байт"})
+		return
+	}
+
+	written, writeErr := uc.FileController.Staging.Write(session.Token, offset, c.Request.Body, session.Size-offset, digest)
+	if errors.Is(writeErr, staging.ErrLost) {
+		uc.discard(session)
+		c.JSON(http.StatusGone, gin.H{"error": "Данные загрузки утеряны, начните её заново"})
+		return
+	}
+
+	session.Offset += written
+	session.ExpiresAt = time.Now().Add(models.UploadSessionLifetime)
+	var checksumErr error
+	if writeErr == nil && session.Offset == session.Size && session.SHA256 == "" {
+		checksumErr = uc.verifyUpload(&session)
+	}
+	if err := uc.DB.Model(&session).Select("offset", "sha256", "expires_at").Updates(&session).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении загрузки"})
+		return
+	}
+	setUploadHeaders(c, session)
+
+	switch {
+	case errors.Is(writeErr, staging.ErrChecksum):
+		c.JSON(statusChecksumMismatch, gin.H{"error": "Контрольная сумма части не совпадает, отправьте её заново"})
+	case errors.Is(writeErr, staging.ErrTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Данные выходят за объявленный размер файла"})
+	case writeErr != nil:
+		// Обычно это обрыв соединения: полученное сохранено, клиент продолжит с Upload-Offset
+		log.Printf("Загрузка %s прервана на %d байте: %v", session.Token, session.Offset, writeErr)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Часть получена не полностью"})
+	case errors.Is(checksumErr, errUploadChecksum):
+		c.JSON(statusChecksumMismatch, gin.H{"error": checksumErr.Error()})
+	case checksumErr != nil:
+		log.Printf("Ошибка при проверке загрузки %s: %v", session.Token, checksumErr)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке файла"})
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// verifyUpload вычисляет SHA-256 полученного файла и сверяет с переданным при создании.
+// При несовпадении данные отбрасываются: загрузку придётся повторить с начала
+func (uc *UploadController) verifyUpload(session *models.UploadSession) error {
+	sum, err := uc.FileController.Staging.SHA256(session.Token)
+	if err != nil {
+		return fmt.Errorf("ошибка при проверке файла: %w", err)
+	}
+	if session.Checksum != "" && sum != session.Checksum {
+		if err := uc.FileController.Staging.Remove(session.Token); err != nil {
+			log.Printf("Не удалось удалить данные загрузки %s: %v", session.Token, err)
+		}
+		session.Offset = 0
+		return errUploadChecksum
+	}
+	session.SHA256 = sum
+	return nil
+}
+
+// DeleteUpload прерывает загрузку и удаляет полученные данные
+func (uc *UploadController) DeleteUpload(c *gin.Context) {
+	session, ok := uc.findUpload(c)
+	if !ok {
+		return
+	}
+	if !lockUpload(session.Token) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Загрузка принимает данные в другом запросе"})
+		return
+	}
+	defer unlockUpload(session.Token)
+
+	if err := uc.discard(session); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении загрузки"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (uc *UploadController) discard(session models.UploadSession) error {
+	err := uc.FileController.DiscardUpload(session)
+	if err != nil {
+		log.Printf("Не удалось удалить загрузку %s: %v", session.Token, err)
+	}
+	return err
+}
+
+// CleanupUploads раз в interval удаляет загрузки с истёкшим сроком вместе с их данными
+func CleanupUploads(db *gorm.DB, interval time.Duration) {
+	uc := NewUploadController(db, NewFileController(db))
+	for {
+		uc.removeExpiredUploads(time.Now())
+		time.Sleep(interval)
+	}
+}
+
+func (uc *UploadController) removeExpiredUploads(now time.Time) {
+	var sessions []models.UploadSession
+	if err := uc.DB.Where("expires_at < ?", now).Find(&sessions).Error; err != nil {
+		log.Printf("Ошибка при поиске просроченных загрузок: %v", err)
+		return
+	}
+	for _, session := range sessions {
+		if !lockUpload(session.Token) {
+			continue
+		}
+		uc.discard(session)
+		unlockUpload(session.Token)
+	}
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 	"gorm.io/gorm"
 	"net/http"
 	"server/models"
@@ -59,3 +60,68 @@ func (tc *UserController) GetOptions(c *gin.Context) {
 	// Return the results
 	c.JSON(http.StatusOK, options)
 }
+
+// GetTechnologies возвращает технологии, которыми владеет текущий пользователь
+func (tc *UserController) GetTechnologies(c *gin.Context) {
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	var user models.User
+	if err := tc.DB.Preload("Technologies", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name")
+	}).First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Пользователь не найден"})
+		return
+	}
+
+	result := make([]technologyDTO.GetShort, len(user.Technologies))
+	for i, technology := range user.Technologies {
+		result[i] = technologyDTO.GetShort{ID: technology.ID, Name: technology.Name}
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// UpdateTechnologies заменяет список технологий текущего пользователя
+func (tc *UserController) UpdateTechnologies(c *gin.Context) {
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	var dto userDTO.TechnologiesUpdate
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка валидации", "details": err.Error()})
+		return
+	}
+
+	var technologies []models.Technology
+	if len(dto.Technologies) > 0 {
+		if err := tc.DB.Where("id IN ?", dto.Technologies).Find(&technologies).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении технологий"})
+			return
+		}
+		if len(technologies) != len(uniqueIDs(dto.Technologies)) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Некоторые технологии не найдены"})
+			return
+		}
+	}
+
+	user := models.User{Model: gorm.Model{ID: userID}}
+	if err := tc.DB.Model(&user).Association("Technologies").Replace(technologies); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении технологий", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Технологии обновлены"})
+}
+
+func uniqueIDs(ids []uint) map[uint]struct{} {
+	set := make(map[uint]struct{}, len(ids))
+	for _, id := range ids {
+		set[id] = struct{}{}
+	}
+	return set
+}
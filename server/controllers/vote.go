@@ -0,0 +1,255 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"server/models"
+	"server/models/DTO/voteDTO"
+	"server/types"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// voteTotal - итог голосования за команду
+type voteTotal struct {
+	TeamID uint
+	Points int
+	Voters int
+}
+
+// loadVoteTotals суммирует голоса за команды хакатона
+func loadVoteTotals(db *gorm.DB, hackathonID uint) (map[uint]voteTotal, error) {
+	var rows []voteTotal
+	if err := db.Model(&models.Vote{}).
+		Select("team_id, SUM(points) AS points, COUNT(*) AS voters").
+		Where("hackathon_id = ?", hackathonID).
+		Group("team_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	totals := make(map[uint]voteTotal, len(rows))
+	for _, row := range rows {
+		totals[row.TeamID] = row
+	}
+	return totals, nil
+}
+
+// voteEligibility проверяет, может ли пользователь голосовать, и возвращает причину отказа.
+// Голосуют только участники хакатона, чей аккаунт создан до начала работы над проектами
+func voteEligibility(db *gorm.DB, hackathon models.Hackathon, userID uint) (string, error) {
+	var participant models.BndUserHackathon
+	err := db.Where("user_id = ? AND hackathon_id = ?", userID, hackathon.ID).First(&participant).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && participant.HackathonRole != 1) {
+		return "Голосовать могут только участники хакатона", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var user models.User
+	if err := db.Select("id, created_at").First(&user, userID).Error; err != nil {
+		return "", err
+	}
+	if !hackathon.WorkDateFrom.IsZero() && user.CreatedAt.After(hackathon.WorkDateFrom) {
+		return "Аккаунты, зарегистрированные после начала хакатона, не могут голосовать", nil
+	}
+	return "", nil
+}
+
+// GetVotes возвращает настройки голосования, голоса пользователя и итоги после окончания голосования
+func (hc *HackathonController) GetVotes(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	var hackathon models.Hackathon
+	if err := hc.DB.First(&hackathon, hackathonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return
+	}
+
+	if hackathon.VotingMode == models.VotingOff {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Голосование на хакатоне не проводится"})
+		return
+	}
+
+	now := time.Now()
+	result := voteDTO.Get{
+		Mode:   hackathon.VotingMode,
+		Budget: hackathon.VoteBudget,
+		From:   hackathon.VotingFrom,
+		To:     hackathon.VotingTo,
+		Open:   hackathon.VotingOpen(now),
+		Closed: hackathon.VotingClosed(now),
+		Votes:  []voteDTO.Item{},
+	}
+
+	reason, err := voteEligibility(hc.DB, hackathon, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке права голоса", "details": err.Error()})
+		return
+	}
+	result.CanVote = reason == "" && result.Open
+	result.Reason = reason
+
+	var votes []models.Vote
+	if err := hc.DB.Preload("Team").Where("hackathon_id = ? AND user_id = ?", hackathon.ID, userID).Find(&votes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении голосов", "details": err.Error()})
+		return
+	}
+
+	spent := 0
+	for _, vote := range votes {
+		spent += vote.Points
+		result.Votes = append(result.Votes, voteDTO.Item{TeamID: vote.TeamID, TeamName: vote.Team.Name, Points: vote.Points})
+	}
+	if hackathon.VotingMode == models.VotingBudget {
+		result.Remaining = hackathon.VoteBudget - spent
+	} else if len(votes) == 0 {
+		result.Remaining = 1
+	}
+
+	// Итоги скрыты, пока голосование не закончилось, чтобы не влиять на голосующих
+	if result.Closed {
+		totals, err := loadVoteTotals(hc.DB, hackathon.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при подсчёте голосов", "details": err.Error()})
+			return
+		}
+
+		var teams []models.Team
+		if err := hc.DB.Where("hackathon_id = ?", hackathon.ID).Find(&teams).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении команд", "details": err.Error()})
+			return
+		}
+
+		result.Totals = make([]voteDTO.Total, 0, len(teams))
+		for _, team := range teams {
+			total := totals[team.ID]
+			result.Totals = append(result.Totals, voteDTO.Total{TeamID: team.ID, TeamName: team.Name, Points: total.Points, Voters: total.Voters})
+		}
+		sort.SliceStable(result.Totals, func(i, j int) bool {
+			return result.Totals[i].Points > result.Totals[j].Points
+		})
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// SetVotes заменяет голоса пользователя. Голосовать за собственную команду нельзя
+func (hc *HackathonController) SetVotes(c *gin.Context) {
+	hackathonID, err := strconv.ParseUint(c.Param("hackathon_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID хакатона"})
+		return
+	}
+
+	var dto voteDTO.Set
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+	if err := validator.New().Struct(dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ошибка валидации: " + err.Error()})
+		return
+	}
+
+	userID := c.MustGet("user_claims").(*types.Claims).UserID
+
+	var hackathon models.Hackathon
+	if err := hc.DB.First(&hackathon, hackathonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+		return
+	}
+
+	if !hackathon.VotingOpen(time.Now()) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Голосование сейчас не проводится"})
+		return
+	}
+
+	reason, err := voteEligibility(hc.DB, hackathon, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке права голоса", "details": err.Error()})
+		return
+	}
+	if reason != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": reason})
+		return
+	}
+
+	teamIDs := make([]uint, 0, len(dto.Votes))
+	spent := 0
+	for _, vote := range dto.Votes {
+		teamIDs = append(teamIDs, vote.TeamID)
+		spent += vote.Points
+	}
+	if len(uniqueIDs(teamIDs)) != len(teamIDs) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "За каждый проект можно проголосовать только один раз"})
+		return
+	}
+
+	switch hackathon.VotingMode {
+	case models.VotingSingle:
+		if len(dto.Votes) > 1 || spent > len(dto.Votes) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Можно отдать только один голос за один проект"})
+			return
+		}
+	case models.VotingBudget:
+		if spent > hackathon.VoteBudget {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Превышен бюджет баллов", "budget": hackathon.VoteBudget, "spent": spent})
+			return
+		}
+	}
+
+	if link, err := userTeamInHackathon(hc.DB, userID, hackathon.ID); err == nil {
+		if _, own := uniqueIDs(teamIDs)[link.TeamID]; own {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Нельзя голосовать за собственную команду"})
+			return
+		}
+	}
+
+	if len(teamIDs) > 0 {
+		var count int64
+		if err := hc.DB.Model(&models.Team{}).Where("id IN ? AND hackathon_id = ?", teamIDs, hackathon.ID).Count(&count).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке команд", "details": err.Error()})
+			return
+		}
+		if int(count) != len(teamIDs) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Не все команды относятся к хакатону"})
+			return
+		}
+	}
+
+	err = hc.DB.Transaction(func(tx *gorm.DB) error {
+		// Блокируем пользователя, чтобы параллельные запросы не превысили бюджет
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.User{}, userID).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("hackathon_id = ? AND user_id = ?", hackathon.ID, userID).Delete(&models.Vote{}).Error; err != nil {
+			return err
+		}
+		for _, input := range dto.Votes {
+			vote := models.Vote{HackathonID: hackathon.ID, UserID: userID, TeamID: input.TeamID, Points: input.Points}
+			if err := tx.Create(&vote).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении голосов", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Голоса сохранены", "spent": spent})
+}
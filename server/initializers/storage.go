@@ -0,0 +1,42 @@
+package initializers
+
+import (
+	"context"
+	"log"
+	"os"
+	"server/staging"
+	"server/storage"
+	"time"
+)
+
+var Storage storage.Storage
+
+// Staging - данные незавершённых загрузок. Хранятся на диске сервера при любом хранилище:
+// части дописываются в файл, а хранилища объектов дописывать не умеют
+var Staging *staging.Dir
+
+func ConnectToStorage() {
+	var err error
+	Storage, err = storage.FromEnv()
+	if err != nil {
+		log.Fatal("Failed to configure file storage: ", err)
+	}
+
+	if s3, ok := Storage.(*storage.S3); ok {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := s3.EnsureBucket(ctx); err != nil {
+			log.Fatal("Failed to prepare S3 bucket: ", err)
+		}
+	}
+
+	stagingPath := os.Getenv("UPLOAD_STAGING_PATH")
+	if stagingPath == "" {
+		stagingPath = "uploads/.staging"
+	}
+	Staging, err = staging.New(stagingPath)
+	if err != nil {
+		log.Fatal("Failed to prepare upload staging directory: ", err)
+	}
+}
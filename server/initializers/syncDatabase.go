@@ -10,10 +10,27 @@ func SyncDatabase() {
 		return
 	}
 
+	// Хакатоны, созданные до появления статуса публикации, уже были открыты всем
+	hadHackathonStatus := DB.Migrator().HasColumn(&models.Hackathon{}, "Status")
+	// Критерии, созданные до появления весов, учитываются с весом 1
+	hadCriteriaWeight := DB.Migrator().HasColumn(&models.Criteria{}, "Weight")
+
+	// Повторные оценки одного судьи, сохранённые до появления уникального индекса, помешают его созданию:
+	// оставляем только последнюю из них
+	if DB.Migrator().HasColumn(&models.Score{}, "UserID") && !DB.Migrator().HasIndex(&models.Score{}, "idx_score_judge") {
+		if err := DB.Exec(`UPDATE scores SET deleted_at = NOW()
+			WHERE deleted_at IS NULL AND id NOT IN (
+				SELECT MAX(id) FROM scores WHERE deleted_at IS NULL GROUP BY team_id, criteria_id, user_id
+			)`).Error; err != nil {
+			return
+		}
+	}
+
 	// Миграция в правильном порядке
 	modelsOrder := []interface{}{
 		&models.ChatMessage{},
 		&models.Chat{},
+		&models.Blob{},
 		&models.File{},
 		&models.Hackathon{},
 		&models.Team{},
@@ -27,6 +44,30 @@ func SyncDatabase() {
 		&models.TeamInvite{},
 		&models.MentorInvite{},
 		&models.BndUserTeam{},
+		&models.HackathonWaitlist{},
+		&models.FormQuestion{},
+		&models.Application{},
+		&models.ApplicationAnswer{},
+		&models.TeamJoinRequest{},
+		&models.Submission{},
+		&models.SubmissionArtifact{},
+		&models.JudgeAssignment{},
+		&models.JudgeConflict{},
+		&models.TieBreaker{},
+		&models.TieDecision{},
+		&models.AwardTrack{},
+		&models.AwardTrackEntry{},
+		&models.AwardTrackPick{},
+		&models.ResultSnapshot{},
+		&models.Vote{},
+		&models.RoundResult{},
+		&models.CertificateTemplate{},
+		&models.Certificate{},
+		&models.GalleryEntry{},
+		&models.ArchiveInspection{},
+		&models.SimilarityReport{},
+		&models.SimilarityPair{},
+		&models.UploadSession{},
 	}
 
 	for _, model := range modelsOrder {
@@ -35,6 +76,18 @@ func SyncDatabase() {
 		}
 	}
 
+	if !hadHackathonStatus {
+		if err := DB.Model(&models.Hackathon{}).Where("status = ?", models.HackathonStatusDraft).Update("status", models.HackathonStatusPublished).Error; err != nil {
+			return
+		}
+	}
+
+	if !hadCriteriaWeight {
+		if err := DB.Model(&models.Criteria{}).Where("weight IS NULL").Update("weight", 1).Error; err != nil {
+			return
+		}
+	}
+
 	// Включаем ограничения обратно
 	if err := DB.Exec("SET CONSTRAINTS ALL IMMEDIATE").Error; err != nil {
 		return
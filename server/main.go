@@ -3,19 +3,25 @@ package main
 import (
 	"log"
 	"os"
+	"server/controllers"
 	"server/initializers"
 	"server/routers"
+	"time"
 )
 
 func init() {
 	initializers.ConnectToDb()
 	initializers.ConnectToCache()
+	initializers.ConnectToStorage()
 	initializers.SyncDatabase()
 }
 
 func main() {
 	router := routers.Router()
 
+	// Брошенные возобновляемые загрузки удаляются в фоне
+	go controllers.CleanupUploads(initializers.DB, time.Hour)
+
 	port := os.Getenv("SERVER_PORT")
 
 	log.Printf("Server starting on port %s", port)
@@ -0,0 +1,60 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"server/models"
+	"strconv"
+	"time"
+)
+
+// HackathonPhase пропускает запрос, только если хакатон находится на одном из открытых этапов phases
+func HackathonPhase(db *gorm.DB, phases ...int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hackathonIDStr := c.Param("hackathon_id")
+		if hackathonIDStr == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Отсутствует идентификатор хакатона"})
+			c.Abort()
+			return
+		}
+
+		hackathonID, err := strconv.ParseUint(hackathonIDStr, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор хакатона"})
+			c.Abort()
+			return
+		}
+
+		var hackathon models.Hackathon
+		if err := db.Preload("Steps").First(&hackathon, hackathonID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Хакатон не найден"})
+			c.Abort()
+			return
+		}
+
+		state := hackathon.State(time.Now())
+		if !state.Allows(phases...) {
+			PhaseConflict(c, state, phases)
+			c.Abort()
+			return
+		}
+
+		c.Set("hackathon_state", state)
+		c.Next()
+	}
+}
+
+// PhaseConflict отвечает единообразной ошибкой, когда действие недоступно на текущем этапе хакатона
+func PhaseConflict(c *gin.Context, state models.HackathonState, allowed []int) {
+	allowedNames := make([]string, 0, len(allowed))
+	for _, phase := range allowed {
+		allowedNames = append(allowedNames, models.HackathonPhaseNames[phase])
+	}
+
+	c.JSON(http.StatusConflict, gin.H{
+		"error":   "Действие недоступно на текущем этапе хакатона",
+		"state":   state,
+		"allowed": allowedNames,
+	})
+}
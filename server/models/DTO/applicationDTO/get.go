@@ -0,0 +1,34 @@
+package applicationDTO
+
+import (
+	"server/models/DTO/fileDTO"
+	"server/models/DTO/technologyDTO"
+	"time"
+)
+
+type AnswerGet struct {
+	QuestionID   uint                     `json:"questionId"`
+	Label        string                   `json:"label"`
+	Text         string                   `json:"text,omitempty"`
+	Choices      []string                 `json:"choices,omitempty"`
+	Technologies []technologyDTO.GetShort `json:"technologies,omitempty"`
+	Files        []fileDTO.GetShort       `json:"files,omitempty"`
+}
+
+type Get struct {
+	ID            uint        `json:"id"`
+	UserID        uint        `json:"userId"`
+	Username      string      `json:"username"`
+	Email         string      `json:"email"`
+	Status        int         `json:"status"`
+	ReviewComment string      `json:"reviewComment,omitempty"`
+	CreatedAt     time.Time   `json:"createdAt"`
+	Answers       []AnswerGet `json:"answers"`
+}
+
+type ListResponse struct {
+	List   []Get `json:"list"`
+	Total  int64 `json:"total"`
+	Limit  int   `json:"limit"`
+	Offset int   `json:"offset"`
+}
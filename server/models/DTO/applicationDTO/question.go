@@ -0,0 +1,38 @@
+package applicationDTO
+
+import "server/models"
+
+type QuestionCreate struct {
+	Label    string   `json:"label" validate:"required,max=1000"`
+	Type     int      `json:"type" validate:"min=0,max=3"`
+	Required bool     `json:"required"`
+	Multiple bool     `json:"multiple"`
+	Options  []string `json:"options" validate:"dive,required,max=255"`
+}
+
+func (dto *QuestionCreate) ToModel(hackathonID uint, position int) *models.FormQuestion {
+	return &models.FormQuestion{
+		Label:       dto.Label,
+		Type:        dto.Type,
+		Required:    dto.Required,
+		Multiple:    dto.Multiple,
+		Options:     dto.Options,
+		Position:    position,
+		HackathonID: hackathonID,
+	}
+}
+
+type QuestionGet struct {
+	ID       uint     `json:"id"`
+	Label    string   `json:"label"`
+	Type     int      `json:"type"`
+	Required bool     `json:"required"`
+	Multiple bool     `json:"multiple"`
+	Options  []string `json:"options"`
+	Position int      `json:"position"`
+}
+
+// FormUpdate - новая анкета хакатона, полностью заменяющая прежнюю
+type FormUpdate struct {
+	Questions []QuestionCreate `json:"questions" validate:"dive"`
+}
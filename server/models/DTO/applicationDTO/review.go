@@ -0,0 +1,14 @@
+package applicationDTO
+
+// Review - массовое решение организатора по заявкам
+type Review struct {
+	IDs     []uint `json:"ids" validate:"required,min=1,dive,min=1"`
+	Status  int    `json:"status" validate:"oneof=-1 1"`
+	Comment string `json:"comment" validate:"max=2000"`
+}
+
+type Filter struct {
+	Status *int `json:"status"`
+	Limit  int  `json:"limit"`
+	Offset int  `json:"offset"`
+}
@@ -0,0 +1,14 @@
+package applicationDTO
+
+type AnswerInput struct {
+	QuestionID   uint     `json:"question_id" validate:"required"`
+	Text         string   `json:"text" validate:"max=10000"`
+	Choices      []string `json:"choices"`
+	Technologies []uint   `json:"technologies" validate:"dive,min=1"`
+}
+
+// Submit - ответы на анкету, передаются в поле data multipart-формы.
+// Файлы передаются в полях file_<question_id>
+type Submit struct {
+	Answers []AnswerInput `json:"answers" validate:"dive"`
+}
@@ -6,4 +6,5 @@ type Get struct {
 	PlaceTo      int     `json:"placeTo"`
 	MoneyAmount  float64 `json:"moneyAmount"`
 	Additionally string  `json:"additionally"`
+	TrackID      *uint   `json:"trackId,omitempty"`
 }
@@ -0,0 +1,20 @@
+package awardTrackDTO
+
+import "server/models/DTO/awardDTO"
+
+// Create - номинация хакатона. Для номинации по критериям нужен хотя бы один критерий хакатона,
+// для номинации организатора и номинации зрительских симпатий критерии не указываются.
+// При изменении номинации критерии и призы заменяются целиком
+type Create struct {
+	Name        string            `json:"name" validate:"required,max=100"`
+	Description string            `json:"description" validate:"max=2000"`
+	Kind        int               `json:"kind" validate:"oneof=0 1 2"`
+	OptIn       bool              `json:"optIn"`
+	Criteria    []uint            `json:"criteria" validate:"dive,min=1"`
+	Awards      []awardDTO.Create `json:"awards" validate:"dive"`
+}
+
+// PicksSet - победители номинации, которую решает организатор, в порядке мест
+type PicksSet struct {
+	Teams []uint `json:"teams" validate:"dive,min=1"`
+}
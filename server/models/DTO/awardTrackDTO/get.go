@@ -0,0 +1,28 @@
+package awardTrackDTO
+
+import "server/models/DTO/awardDTO"
+
+type Criterion struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+type Pick struct {
+	TeamID   uint   `json:"teamId"`
+	TeamName string `json:"teamName"`
+	Place    int    `json:"place"`
+}
+
+type Get struct {
+	ID          uint           `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Kind        int            `json:"kind"`
+	OptIn       bool           `json:"optIn"`
+	Criteria    []Criterion    `json:"criteria"`
+	Awards      []awardDTO.Get `json:"awards"`
+	Entries     int            `json:"entries"`
+	// Команда текущего пользователя подала заявку на номинацию
+	Entered bool   `json:"entered"`
+	Picks   []Pick `json:"picks,omitempty"`
+}
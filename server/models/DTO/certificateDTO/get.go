@@ -0,0 +1,51 @@
+package certificateDTO
+
+import (
+	"server/models/DTO/fileDTO"
+	"time"
+)
+
+type TemplateGet struct {
+	PageWidth  float64           `json:"pageWidth"`
+	PageHeight float64           `json:"pageHeight"`
+	Background *fileDTO.GetShort `json:"background,omitempty"`
+	Font       fileDTO.GetShort  `json:"font"`
+	Fields     []Field           `json:"fields"`
+	UpdatedAt  time.Time         `json:"updatedAt"`
+}
+
+type Get struct {
+	Code      string     `json:"code"`
+	Kind      int        `json:"kind"`
+	KindName  string     `json:"kindName"`
+	UserID    uint       `json:"userId"`
+	Username  string     `json:"username"`
+	TeamName  string     `json:"teamName,omitempty"`
+	Place     int        `json:"place,omitempty"`
+	TrackName string     `json:"trackName,omitempty"`
+	IssuedAt  time.Time  `json:"issuedAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+// IssueResult - итог выдачи: сколько сертификатов создано сейчас, сколько отозвано у команд,
+// лишившихся награды, и сколько всего действующих
+type IssueResult struct {
+	Issued  int   `json:"issued"`
+	Revoked int64 `json:"revoked"`
+	Total   int64 `json:"total"`
+}
+
+// Verify - публичная проверка подлинности сертификата
+type Verify struct {
+	Valid     bool       `json:"valid"`
+	Code      string     `json:"code"`
+	Holder    string     `json:"holder"`
+	Hackathon string     `json:"hackathon"`
+	Kind      int        `json:"kind"`
+	KindName  string     `json:"kindName"`
+	TeamName  string     `json:"teamName,omitempty"`
+	Place     int        `json:"place,omitempty"`
+	TrackName string     `json:"trackName,omitempty"`
+	IssuedAt  time.Time  `json:"issuedAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
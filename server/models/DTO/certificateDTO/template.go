@@ -0,0 +1,37 @@
+package certificateDTO
+
+import "server/models"
+
+// Field - строка сертификата. Text может содержать подстановки {name}, {hackathon}, {kind},
+// {team}, {place}, {track}, {date}, {code} и {verify_url}
+type Field struct {
+	Text  string  `json:"text" validate:"required,max=500"`
+	X     float64 `json:"x" validate:"min=0"`
+	Y     float64 `json:"y" validate:"min=0"`
+	Size  float64 `json:"size" validate:"gt=0,max=300"`
+	Align string  `json:"align" validate:"omitempty,oneof=left center right"`
+	Color string  `json:"color" validate:"omitempty,hexcolor,len=7"`
+	Kinds []int   `json:"kinds" validate:"dive,min=0,max=3"`
+}
+
+// TemplateSet - шаблон сертификатов. Передаётся полем data вместе с файлами font (TrueType)
+// и background (изображение). Без новых файлов остаются загруженные ранее;
+// RemoveBackground убирает фон. Размер страницы по умолчанию - A4 в альбомной ориентации
+type TemplateSet struct {
+	PageWidth        float64 `json:"pageWidth" validate:"omitempty,gt=0,max=3000"`
+	PageHeight       float64 `json:"pageHeight" validate:"omitempty,gt=0,max=3000"`
+	RemoveBackground bool    `json:"removeBackground"`
+	Fields           []Field `json:"fields" validate:"required,min=1,max=50,dive"`
+}
+
+func (dto *Field) ToModel() models.CertificateField {
+	return models.CertificateField{
+		Text:  dto.Text,
+		X:     dto.X,
+		Y:     dto.Y,
+		Size:  dto.Size,
+		Align: dto.Align,
+		Color: dto.Color,
+		Kinds: dto.Kinds,
+	}
+}
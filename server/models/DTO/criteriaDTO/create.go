@@ -3,16 +3,49 @@ package criteriaDTO
 import "server/models"
 
 type Create struct {
-	Name     string `json:"name" validate:"required,max=255"`
-	MaxScore uint   `json:"max_score" validate:"min=0"`
-	MinScore uint   `json:"min_score" validate:"min=0"`
+	// ID существующего критерия при редактировании хакатона; без ID создаётся новый критерий
+	ID            uint     `json:"id,omitempty"`
+	Name          string   `json:"name" validate:"required,max=255"`
+	MaxScore      uint     `json:"max_score" validate:"min=0"`
+	MinScore      uint     `json:"min_score" validate:"min=0"`
+	Weight        *float64 `json:"weight,omitempty" validate:"omitempty,min=0"`
+	Normalization *int     `json:"normalization,omitempty" validate:"omitempty,min=0,max=2"`
 }
 
 func (dto *Create) ToModel(hackathonID uint) *models.Criteria {
+	// Вес по умолчанию - 1, чтобы старые клиенты получали прежнее поведение
+	weight := 1.0
+	if dto.Weight != nil {
+		weight = *dto.Weight
+	}
+	normalization := models.CriteriaNormalizationNone
+	if dto.Normalization != nil {
+		normalization = *dto.Normalization
+	}
+
 	return &models.Criteria{
-		Name:        dto.Name,
-		MaxScore:    dto.MaxScore,
-		MinScore:    dto.MinScore,
-		HackathonID: hackathonID,
+		Name:          dto.Name,
+		MaxScore:      dto.MaxScore,
+		MinScore:      dto.MinScore,
+		Weight:        weight,
+		Normalization: normalization,
+		HackathonID:   hackathonID,
+	}
+}
+
+// Updates возвращает изменяемые поля существующего критерия. Вес и нормализация, которые клиент
+// не передал, остаются прежними
+func (dto *Create) Updates() map[string]interface{} {
+	updates := map[string]interface{}{
+		"name":      dto.Name,
+		"max_score": dto.MaxScore,
+		"min_score": dto.MinScore,
+	}
+	if dto.Weight != nil {
+		updates["weight"] = *dto.Weight
+	}
+	if dto.Normalization != nil {
+		updates["normalization"] = *dto.Normalization
 	}
+	return updates
 }
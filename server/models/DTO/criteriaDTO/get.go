@@ -1,8 +1,10 @@
 package criteriaDTO
 
 type Get struct {
-	ID       uint   `json:"id"`
-	Name     string `json:"name"`
-	MaxScore uint   `json:"maxScore"`
-	MinScore uint   `json:"minScore"`
+	ID            uint    `json:"id"`
+	Name          string  `json:"name"`
+	MaxScore      uint    `json:"maxScore"`
+	MinScore      uint    `json:"minScore"`
+	Weight        float64 `json:"weight"`
+	Normalization int     `json:"normalization"`
 }
@@ -0,0 +1,50 @@
+package galleryDTO
+
+type Screenshot struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type Award struct {
+	TrackName string `json:"trackName,omitempty"`
+	Place     int    `json:"place"`
+}
+
+// Get - карточка проекта для посетителей галереи
+type Get struct {
+	TeamID        uint         `json:"teamId"`
+	TeamName      string       `json:"teamName"`
+	Members       []string     `json:"members"`
+	Description   string       `json:"description"`
+	Screenshots   []Screenshot `json:"screenshots"`
+	RepositoryURL string       `json:"repositoryUrl,omitempty"`
+	DemoURL       string       `json:"demoUrl,omitempty"`
+	Place         int          `json:"place,omitempty"`
+	Awards        []Award      `json:"awards"`
+	DownloadURL   string       `json:"downloadUrl,omitempty"`
+}
+
+type ListResponse struct {
+	List   []Get `json:"list"`
+	Total  int64 `json:"total"`
+	Limit  int   `json:"limit"`
+	Offset int   `json:"offset"`
+}
+
+type Member struct {
+	UserID   uint   `json:"userId"`
+	Username string `json:"username"`
+	Consent  bool   `json:"consent"`
+}
+
+// Entry - настройки карточки для команды и организаторов
+type Entry struct {
+	Get
+	Published    bool     `json:"published"`
+	Hidden       bool     `json:"hidden"`
+	HiddenReason string   `json:"hiddenReason,omitempty"`
+	Team         []Member `json:"team"`
+	// Галерея включена и результаты опубликованы
+	GalleryOpen bool `json:"galleryOpen"`
+}
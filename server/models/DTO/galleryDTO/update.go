@@ -0,0 +1,29 @@
+package galleryDTO
+
+// EntryUpdate - карточка команды в галерее, передаётся в поле data multipart-формы.
+// Новые скриншоты передаются в полях screenshot. KeepScreenshots - загруженные ранее скриншоты,
+// которые остаются; если поле не передано, остаются все. Пустые описание и ссылки
+// заменяются данными итоговой версии сдачи
+type EntryUpdate struct {
+	Published       bool   `json:"published"`
+	Description     string `json:"description" validate:"max=5000"`
+	RepositoryURL   string `json:"repositoryUrl" validate:"omitempty,url,max=1000"`
+	DemoURL         string `json:"demoUrl" validate:"omitempty,url,max=1000"`
+	KeepScreenshots []uint `json:"keepScreenshots"`
+}
+
+// Consent - согласие участника показывать своё имя в галерее
+type Consent struct {
+	Consent bool `json:"consent"`
+}
+
+// Settings - включение галереи хакатона организатором
+type Settings struct {
+	Enabled bool `json:"enabled"`
+}
+
+// Hide - скрытие проекта из галереи организатором
+type Hide struct {
+	Hidden bool   `json:"hidden"`
+	Reason string `json:"reason" validate:"max=500"`
+}
@@ -21,6 +21,26 @@ type CreateDTO struct {
 
 	OrganizationID uint `json:"organization_id" validate:"required"`
 
+	ScoreAggregation int `json:"score_aggregation" validate:"min=0,max=2"`
+
+	MinTeamSize     int `json:"min_team_size" validate:"min=0"`
+	MaxTeamSize     int `json:"max_team_size" validate:"min=0"`
+	MaxParticipants int `json:"max_participants" validate:"min=0"`
+
+	SubmissionGraceMinutes int `json:"submission_grace_minutes" validate:"min=0,max=10080"`
+
+	ArchiveRequiredFiles       []string `json:"archive_required_files"`
+	ArchiveForbiddenExtensions []string `json:"archive_forbidden_extensions"`
+	ArchiveMaxSizeMB           int      `json:"archive_max_size_mb" validate:"min=0"`
+
+	BlindReview bool `json:"blind_review"`
+
+	VotingMode   int        `json:"voting_mode" validate:"oneof=0 1 2"`
+	VoteBudget   int        `json:"vote_budget" validate:"min=0,max=1000"`
+	VotingFrom   *time.Time `json:"voting_from"`
+	VotingTo     *time.Time `json:"voting_to"`
+	VotingWeight float64    `json:"voting_weight" validate:"min=0"`
+
 	Technologies []uint               `json:"technologies" validate:"dive,min=1"`
 	Criteria     []criteriaDTO.Create `json:"criteria" validate:"required,dive,required"`
 	Steps        []stepDTO.Create     `json:"steps" validate:"required,dive,required"`
@@ -41,6 +61,26 @@ func (dto *CreateDTO) ToModel() *models.Hackathon {
 		EvalDateTo:   dto.EvalDateTo,
 
 		OrganizationID: dto.OrganizationID,
+
+		ScoreAggregation: dto.ScoreAggregation,
+
+		MinTeamSize:     dto.MinTeamSize,
+		MaxTeamSize:     dto.MaxTeamSize,
+		MaxParticipants: dto.MaxParticipants,
+
+		SubmissionGraceMinutes: dto.SubmissionGraceMinutes,
+
+		ArchiveRequiredFiles:       dto.ArchiveRequiredFiles,
+		ArchiveForbiddenExtensions: dto.ArchiveForbiddenExtensions,
+		ArchiveMaxSizeMB:           dto.ArchiveMaxSizeMB,
+
+		BlindReview: dto.BlindReview,
+
+		VotingMode:   dto.VotingMode,
+		VoteBudget:   dto.VoteBudget,
+		VotingFrom:   dto.VotingFrom,
+		VotingTo:     dto.VotingTo,
+		VotingWeight: dto.VotingWeight,
 	}
 	return hackathon
 }
@@ -25,11 +25,33 @@ type FullBaseInfo struct {
 	Status int `json:"status"`
 
 	LogoId      uint    `json:"logoId,omitempty"`
+	LogoURL     string  `json:"logoUrl,omitempty"`
 	TotalAward  float64 `json:"totalAward"`
 	MinTeamSize int     `json:"minTeamSize"`
 	MaxTeamSize int     `json:"maxTeamSize"`
 	UserCount   int     `json:"usersCount"`
 
+	MaxParticipants int `json:"maxParticipants"`
+
+	SubmissionGraceMinutes int `json:"submissionGraceMinutes"`
+
+	ArchiveRequiredFiles       []string `json:"archiveRequiredFiles"`
+	ArchiveForbiddenExtensions []string `json:"archiveForbiddenExtensions"`
+	ArchiveMaxSizeMB           int      `json:"archiveMaxSizeMb"`
+
+	BlindReview   bool `json:"blindReview"`
+	BlindRevealed bool `json:"blindRevealed"`
+
+	VotingMode   int        `json:"votingMode"`
+	VoteBudget   int        `json:"voteBudget"`
+	VotingFrom   *time.Time `json:"votingFrom,omitempty"`
+	VotingTo     *time.Time `json:"votingTo,omitempty"`
+	VotingWeight float64    `json:"votingWeight"`
+
+	GalleryEnabled bool `json:"galleryEnabled"`
+
+	ScoreAggregation int `json:"scoreAggregation"`
+
 	Files        []fileDTO.GetShort       `json:"files"`
 	Steps        []hackathonStepDTO.Get   `json:"steps"`
 	Awards       []awardDTO.Get           `json:"awards"`
@@ -0,0 +1,10 @@
+package hackathonDTO
+
+import "time"
+
+// PhaseOverride - продление этапа хакатона организатором.
+// Если этап не указан, продлевается текущий
+type PhaseOverride struct {
+	Phase *int      `json:"phase,omitempty" validate:"omitempty,min=1,max=3"`
+	Until time.Time `json:"until" validate:"required"`
+}
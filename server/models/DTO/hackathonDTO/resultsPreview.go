@@ -0,0 +1,14 @@
+package hackathonDTO
+
+// ResultsPreview - формула подсчёта результатов, которую организатор хочет примерить без сохранения
+type ResultsPreview struct {
+	Aggregation  *int                     `json:"aggregation,omitempty" validate:"omitempty,min=0,max=2"`
+	VotingWeight *float64                 `json:"votingWeight,omitempty" validate:"omitempty,min=0"`
+	Criteria     []CriteriaFormulaPreview `json:"criteria" validate:"dive"`
+}
+
+type CriteriaFormulaPreview struct {
+	ID            uint     `json:"id" validate:"required"`
+	Weight        *float64 `json:"weight,omitempty" validate:"omitempty,min=0"`
+	Normalization *int     `json:"normalization,omitempty" validate:"omitempty,min=0,max=2"`
+}
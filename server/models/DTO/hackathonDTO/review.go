@@ -0,0 +1,33 @@
+package hackathonDTO
+
+import "time"
+
+// ReviewReject - отклонение хакатона администратором с указанием причины
+type ReviewReject struct {
+	Reason string `json:"reason" validate:"required,max=2000"`
+}
+
+type ReviewFilter struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// ReviewInfo - хакатон в очереди на проверку
+type ReviewInfo struct {
+	ID                 uint       `json:"id"`
+	Name               string     `json:"name"`
+	Description        string     `json:"description"`
+	OrganizationID     uint       `json:"organizationId"`
+	OrganizationName   string     `json:"organizationName"`
+	OrganizationStatus int        `json:"organizationStatus"`
+	RegDateFrom        time.Time  `json:"regDateFrom"`
+	EvalDateTo         time.Time  `json:"evalDateTo"`
+	SubmittedAt        *time.Time `json:"submittedAt"`
+}
+
+type ReviewListResponse struct {
+	List   []ReviewInfo `json:"list"`
+	Total  int64        `json:"total"`
+	Limit  int          `json:"limit"`
+	Offset int          `json:"offset"`
+}
@@ -16,7 +16,10 @@ type ShortInfo struct {
 	EvalDateFrom time.Time `json:"evalDateFrom"`
 	EvalDateTo   time.Time `json:"evalDateTo"`
 
+	Status int `json:"status"`
+
 	LogoId       uint     `json:"logoId,omitempty"`
+	LogoURL      string   `json:"logoUrl,omitempty"`
 	Technologies []string `json:"technologies"`
 	TotalAward   float64  `json:"totalAward"`
 	MinTeamSize  int      `json:"minTeamSize"`
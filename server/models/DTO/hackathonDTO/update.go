@@ -18,9 +18,23 @@ type Update struct {
 	EvalDateFrom          *time.Time           `json:"eval_date_from,omitempty"`
 	EvalDateTo            *time.Time           `json:"eval_date_to,omitempty"`
 	OrganizationID        *uint                `json:"organization_id,omitempty"`
+	ScoreAggregation      *int                 `json:"score_aggregation,omitempty"`
+	MinTeamSize           *int                 `json:"min_team_size,omitempty"`
+	MaxTeamSize           *int                 `json:"max_team_size,omitempty"`
+	MaxParticipants       *int                 `json:"max_participants,omitempty"`
+	SubmissionGrace       *int                 `json:"submission_grace_minutes,omitempty"`
+	ArchiveRequiredFiles  *[]string            `json:"archive_required_files,omitempty"`
+	ArchiveForbiddenExts  *[]string            `json:"archive_forbidden_extensions,omitempty"`
+	ArchiveMaxSizeMB      *int                 `json:"archive_max_size_mb,omitempty"`
+	BlindReview           *bool                `json:"blind_review,omitempty"`
+	VotingMode            *int                 `json:"voting_mode,omitempty"`
+	VoteBudget            *int                 `json:"vote_budget,omitempty"`
+	VotingFrom            *time.Time           `json:"voting_from,omitempty"`
+	VotingTo              *time.Time           `json:"voting_to,omitempty"`
+	VotingWeight          *float64             `json:"voting_weight,omitempty"`
 	Steps                 []stepDTO.Create     `json:"steps,omitempty"`
 	Awards                []awardDTO.Create    `json:"awards,omitempty"`
-	Criteria              []criteriaDTO.Create `json:"criteria,omitempty"`
+	Criteria              []criteriaDTO.Create `json:"criteria,omitempty" validate:"dive"`
 	Technologies          []uint               `json:"technologies,omitempty"`
 	Mentors               []uint               `json:"mentors,omitempty"`
 	MentorInvitesToDelete []uint               `json:"mentor_invites_to_delete,omitempty"`
@@ -65,5 +79,61 @@ func (dto *Update) ToModel(existingHackathon models.Hackathon) models.Hackathon
 		existingHackathon.OrganizationID = *dto.OrganizationID
 	}
 
+	if dto.ScoreAggregation != nil {
+		existingHackathon.ScoreAggregation = *dto.ScoreAggregation
+	}
+
+	if dto.MinTeamSize != nil {
+		existingHackathon.MinTeamSize = *dto.MinTeamSize
+	}
+
+	if dto.MaxTeamSize != nil {
+		existingHackathon.MaxTeamSize = *dto.MaxTeamSize
+	}
+
+	if dto.MaxParticipants != nil {
+		existingHackathon.MaxParticipants = *dto.MaxParticipants
+	}
+
+	if dto.SubmissionGrace != nil {
+		existingHackathon.SubmissionGraceMinutes = *dto.SubmissionGrace
+	}
+
+	if dto.ArchiveRequiredFiles != nil {
+		existingHackathon.ArchiveRequiredFiles = *dto.ArchiveRequiredFiles
+	}
+
+	if dto.ArchiveForbiddenExts != nil {
+		existingHackathon.ArchiveForbiddenExtensions = *dto.ArchiveForbiddenExts
+	}
+
+	if dto.ArchiveMaxSizeMB != nil {
+		existingHackathon.ArchiveMaxSizeMB = *dto.ArchiveMaxSizeMB
+	}
+
+	if dto.BlindReview != nil {
+		existingHackathon.BlindReview = *dto.BlindReview
+	}
+
+	if dto.VotingMode != nil {
+		existingHackathon.VotingMode = *dto.VotingMode
+	}
+
+	if dto.VoteBudget != nil {
+		existingHackathon.VoteBudget = *dto.VoteBudget
+	}
+
+	if dto.VotingFrom != nil {
+		existingHackathon.VotingFrom = dto.VotingFrom
+	}
+
+	if dto.VotingTo != nil {
+		existingHackathon.VotingTo = dto.VotingTo
+	}
+
+	if dto.VotingWeight != nil {
+		existingHackathon.VotingWeight = *dto.VotingWeight
+	}
+
 	return existingHackathon
 }
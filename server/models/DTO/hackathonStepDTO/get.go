@@ -1,6 +1,7 @@
 package hackathonStepDTO
 
 import (
+	"server/models/DTO/criteriaDTO"
 	"time"
 )
 
@@ -10,4 +11,10 @@ type Get struct {
 	Description string    `json:"description"`
 	StartDate   time.Time `json:"startDate"`
 	EndDate     time.Time `json:"endDate"`
+
+	IsRound     bool              `json:"isRound"`
+	CutoffKind  int               `json:"cutoffKind,omitempty"`
+	CutoffValue float64           `json:"cutoffValue,omitempty"`
+	ClosedAt    *time.Time        `json:"closedAt,omitempty"`
+	Criteria    []criteriaDTO.Get `json:"criteria,omitempty"`
 }
@@ -0,0 +1,44 @@
+package judgeDTO
+
+// AssignmentSet - судьи, назначаемые на команду вручную; заменяет прежний состав
+type AssignmentSet struct {
+	Judges []uint `json:"judges" validate:"dive,min=1"`
+}
+
+// AutoAssign - автоматическое распределение судей.
+// Replace = true удаляет прежние автоматические назначения, ручные сохраняются
+type AutoAssign struct {
+	JudgesPerTeam int  `json:"judgesPerTeam" validate:"min=1,max=20"`
+	Replace       bool `json:"replace"`
+}
+
+type AssignedJudge struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Auto     bool   `json:"auto"`
+	Scored   bool   `json:"scored"`
+}
+
+type TeamAssignments struct {
+	TeamID   uint            `json:"teamId"`
+	TeamName string          `json:"teamName"`
+	Required int             `json:"required"`
+	Judges   []AssignedJudge `json:"judges"`
+}
+
+// JudgeLoad - нагрузка судьи: назначенные и уже оценённые команды
+type JudgeLoad struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Assigned int    `json:"assigned"`
+	Scored   int    `json:"scored"`
+}
+
+type AssignmentsResponse struct {
+	JudgesPerTeam int               `json:"judgesPerTeam"`
+	Teams         []TeamAssignments `json:"teams"`
+	Judges        []JudgeLoad       `json:"judges"`
+	Conflicts     []Conflict        `json:"conflicts"`
+	// Команды, которым не удалось подобрать нужное число судей без конфликтов
+	Understaffed []uint `json:"understaffed"`
+}
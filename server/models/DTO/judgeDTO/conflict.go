@@ -0,0 +1,18 @@
+package judgeDTO
+
+type ConflictCreate struct {
+	UserID uint   `json:"userId" validate:"required"`
+	TeamID uint   `json:"teamId" validate:"required"`
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// Conflict - конфликт интересов; Auto - обнаружен автоматически и не может быть удалён
+type Conflict struct {
+	ID       uint   `json:"id,omitempty"`
+	UserID   uint   `json:"userId"`
+	Username string `json:"username"`
+	TeamID   uint   `json:"teamId"`
+	TeamName string `json:"teamName"`
+	Reason   string `json:"reason"`
+	Auto     bool   `json:"auto"`
+}
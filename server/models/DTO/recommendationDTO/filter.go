@@ -0,0 +1,6 @@
+package recommendationDTO
+
+type Filter struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
@@ -0,0 +1,32 @@
+package recommendationDTO
+
+import "server/models/DTO/technologyDTO"
+
+// Режимы подбора
+const (
+	ModeTeams        = "teams"        // свободному участнику подбираются команды
+	ModeParticipants = "participants" // капитану подбираются свободные участники
+)
+
+// Item - рекомендованная команда или участник.
+// Covers - технологии хакатона, которые кандидат добавляет к вашим,
+// Brings - технологии, которых не хватает кандидату и которые есть у вас
+type Item struct {
+	ID          uint                     `json:"id"`
+	Name        string                   `json:"name"`
+	Score       int                      `json:"score"`
+	Covers      []technologyDTO.GetShort `json:"covers"`
+	Brings      []technologyDTO.GetShort `json:"brings"`
+	Explanation string                   `json:"explanation"`
+	Size        int64                    `json:"size,omitempty"`
+	MaxSize     int                      `json:"maxSize,omitempty"`
+}
+
+type ListResponse struct {
+	Mode    string                   `json:"mode"`
+	Missing []technologyDTO.GetShort `json:"missing"`
+	List    []Item                   `json:"list"`
+	Total   int                      `json:"total"`
+	Limit   int                      `json:"limit"`
+	Offset  int                      `json:"offset"`
+}
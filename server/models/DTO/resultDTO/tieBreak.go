@@ -0,0 +1,40 @@
+package resultDTO
+
+type TieBreakerInput struct {
+	Kind       int   `json:"kind" validate:"oneof=0 1 2"`
+	CriteriaID *uint `json:"criteriaId,omitempty"`
+}
+
+// TieBreakersSet - правила разрешения ничьих в порядке применения; заменяет прежний список
+type TieBreakersSet struct {
+	Rules []TieBreakerInput `json:"rules" validate:"max=10,dive"`
+}
+
+// TieDecisionsSet - порядок команд, которым организатор разрешает ничьи: первая команда выше
+type TieDecisionsSet struct {
+	Teams []uint `json:"teams" validate:"dive,min=1"`
+}
+
+type TieBreaker struct {
+	Kind       int    `json:"kind"`
+	Name       string `json:"name"`
+	CriteriaID *uint  `json:"criteriaId,omitempty"`
+}
+
+type TieDecision struct {
+	TeamID   uint   `json:"teamId"`
+	TeamName string `json:"teamName"`
+	Rank     int    `json:"rank"`
+}
+
+// Tie - команды, делящие место, на которое приходятся разные награды; Track - название номинации
+type Tie struct {
+	Track string   `json:"track,omitempty"`
+	Place int      `json:"place"`
+	Teams []string `json:"teams"`
+}
+
+type TieSettings struct {
+	Rules     []TieBreaker  `json:"rules"`
+	Decisions []TieDecision `json:"decisions"`
+}
@@ -0,0 +1,59 @@
+package similarityDTO
+
+import "time"
+
+type Team struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// Pair - пара команд с похожим кодом. Score - наибольшая из долей кода одной команды, найденного у другой
+type Pair struct {
+	ID          uint    `json:"id"`
+	TeamA       Team    `json:"teamA"`
+	TeamB       Team    `json:"teamB"`
+	Score       float64 `json:"score"`
+	ScoreA      float64 `json:"scoreA"`
+	ScoreB      float64 `json:"scoreB"`
+	Matches     int     `json:"matches"`
+	RegionCount int     `json:"regionCount"`
+	URL         string  `json:"url"`
+}
+
+// Region - совпадающие строки файлов команд A и B
+type Region struct {
+	FileA   string `json:"fileA"`
+	StartA  int    `json:"startA"`
+	EndA    int    `json:"endA"`
+	FileB   string `json:"fileB"`
+	StartB  int    `json:"startB"`
+	EndB    int    `json:"endB"`
+	Matches int    `json:"matches"`
+}
+
+type PairDetail struct {
+	Pair
+	Regions []Region `json:"regions"`
+}
+
+// Report - состояние проверки и найденные пары по убыванию сходства
+type Report struct {
+	Status       string     `json:"status"`
+	Error        string     `json:"error,omitempty"`
+	StartedAt    time.Time  `json:"startedAt"`
+	FinishedAt   *time.Time `json:"finishedAt,omitempty"`
+	Teams        int        `json:"teams"`
+	SkippedTeams []Team     `json:"skippedTeams"`
+	// Сколько фрагментов признано шаблонным кодом и с какого числа команд
+	Boilerplate      int    `json:"boilerplate"`
+	BoilerplateTeams int    `json:"boilerplateTeams"`
+	Pairs            []Pair `json:"pairs"`
+}
+
+// Top - самая похожая на команду другая команда, для списка проектов на оценке
+type Top struct {
+	Score  float64 `json:"score"`
+	TeamID uint    `json:"teamId"`
+	Team   string  `json:"team"`
+	URL    string  `json:"url"`
+}
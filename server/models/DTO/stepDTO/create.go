@@ -2,6 +2,7 @@ package stepDTO
 
 import (
 	"server/models"
+	"server/models/DTO/criteriaDTO"
 	"time"
 )
 
@@ -10,14 +11,30 @@ type Create struct {
 	Description string    `json:"description" validate:"max=2000"`
 	StartDate   time.Time `json:"start_date" validate:"required"`
 	EndDate     time.Time `json:"end_date" validate:"required,gtefield=StartDate"`
+
+	// Отборочный тур: свои критерии оценки и правило прохода дальше
+	IsRound     bool                 `json:"is_round"`
+	CutoffKind  int                  `json:"cutoff_kind" validate:"min=0,max=1"`
+	CutoffValue float64              `json:"cutoff_value" validate:"min=0"`
+	Criteria    []criteriaDTO.Create `json:"criteria,omitempty" validate:"required_if=IsRound true,dive"`
 }
 
 func (dto *Create) ToModel(hackathonID uint) *models.HackathonStep {
-	return &models.HackathonStep{
+	step := &models.HackathonStep{
 		Name:        dto.Name,
 		Description: dto.Description,
 		StartDate:   dto.StartDate,
 		EndDate:     dto.EndDate,
 		HackathonID: hackathonID,
 	}
+
+	if dto.IsRound {
+		step.IsRound = true
+		step.CutoffKind = dto.CutoffKind
+		step.CutoffValue = dto.CutoffValue
+		for _, criterion := range dto.Criteria {
+			step.Criteria = append(step.Criteria, *criterion.ToModel(hackathonID))
+		}
+	}
+	return step
 }
@@ -0,0 +1,25 @@
+package submissionDTO
+
+type LinkInput struct {
+	Kind int    `json:"kind" validate:"oneof=2 3"`
+	URL  string `json:"url" validate:"required,url,max=1000"`
+}
+
+// UploadInput - файл, загруженный заранее возобновляемой загрузкой (/upload)
+type UploadInput struct {
+	Kind int    `json:"kind" validate:"oneof=0 1"`
+	ID   string `json:"id" validate:"required,uuid"`
+}
+
+// Create - новая версия сдачи, передаётся в поле data multipart-формы.
+// Файлы передаются в полях file_<kind> (file_0 - архив с кодом, file_1 - презентация)
+// или в Uploads, если большой файл загружен частями.
+// Keep - артефакты предыдущей версии, которые переносятся без изменений;
+// если поле не передано, переносятся все артефакты, не заменённые в этой версии.
+// Description = nil оставляет описание предыдущей версии
+type Create struct {
+	Description *string       `json:"description" validate:"omitempty,max=5000"`
+	Links       []LinkInput   `json:"links" validate:"dive"`
+	Uploads     []UploadInput `json:"uploads" validate:"dive"`
+	Keep        []uint        `json:"keep"`
+}
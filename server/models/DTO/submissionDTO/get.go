@@ -0,0 +1,62 @@
+package submissionDTO
+
+import (
+	"server/models/DTO/fileDTO"
+	"time"
+)
+
+type Artifact struct {
+	ID   uint              `json:"id"`
+	Kind int               `json:"kind"`
+	Name string            `json:"name"`
+	URL  string            `json:"url,omitempty"`
+	File *fileDTO.GetShort `json:"file,omitempty"`
+
+	// Обезличенная ссылка на скачивание при слепой оценке
+	DownloadURL string `json:"downloadUrl,omitempty"`
+
+	Inspection *Inspection `json:"inspection,omitempty"`
+}
+
+// Inspection - итог проверки архива при загрузке; список файлов отдаётся по URL
+type Inspection struct {
+	Format    string `json:"format"`
+	Files     int    `json:"files"`
+	Size      int64  `json:"size"`
+	Truncated bool   `json:"truncated"`
+	URL       string `json:"url"`
+}
+
+// Tree - файлы архива для просмотра без скачивания
+type Tree struct {
+	Inspection
+	Entries []TreeEntry `json:"entries"`
+}
+
+type TreeEntry struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	Dir  bool   `json:"dir,omitempty"`
+}
+
+type Get struct {
+	ID          uint       `json:"id"`
+	TeamID      uint       `json:"teamId"`
+	Version     int        `json:"version"`
+	Description string     `json:"description"`
+	IsLate      bool       `json:"isLate"`
+	IsFinal     bool       `json:"isFinal"`
+	SubmittedBy string     `json:"submittedBy"`
+	SubmittedAt time.Time  `json:"submittedAt"`
+	Artifacts   []Artifact `json:"artifacts"`
+}
+
+// History - история версий сдачи команды, последняя версия - первая в списке
+type History struct {
+	Versions []Get      `json:"versions"`
+	Deadline time.Time  `json:"deadline"`
+	GraceEnd time.Time  `json:"graceEnd"`
+	Open     bool       `json:"open"`
+	Late     bool       `json:"late"`
+	FrozenAt *time.Time `json:"frozenAt,omitempty"`
+}
@@ -0,0 +1,11 @@
+package teamDTO
+
+// ListItem - команда в списке команд хакатона для поиска свободных мест
+type ListItem struct {
+	ID            uint   `json:"id"`
+	Name          string `json:"name"`
+	Size          int64  `json:"size"`
+	MaxSize       int    `json:"maxSize"`
+	HasFreeSlots  bool   `json:"hasFreeSlots"`
+	RequestStatus *int   `json:"requestStatus"`
+}
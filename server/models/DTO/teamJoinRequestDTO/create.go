@@ -0,0 +1,5 @@
+package teamJoinRequestDTO
+
+type Create struct {
+	Message string `json:"message" validate:"max=1000"`
+}
@@ -0,0 +1,14 @@
+package teamJoinRequestDTO
+
+import "time"
+
+type Get struct {
+	Id        uint      `json:"id"`
+	UserID    uint      `json:"userId"`
+	Username  string    `json:"username"`
+	TeamID    uint      `json:"teamId"`
+	TeamName  string    `json:"teamName"`
+	Message   string    `json:"message"`
+	Status    int       `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+}
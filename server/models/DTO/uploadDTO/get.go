@@ -0,0 +1,14 @@
+package uploadDTO
+
+import "time"
+
+// Get - состояние возобновляемой загрузки
+type Get struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	Offset    int64     `json:"offset"`
+	Complete  bool      `json:"complete"`
+	SHA256    string    `json:"sha256,omitempty"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
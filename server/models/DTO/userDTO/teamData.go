@@ -4,4 +4,5 @@ type TeamData struct {
 	Name         *string           `json:"name"`
 	Participants []TeamParticipant `json:"participants"`
 	TeamRole     int               `json:"teamRole"`
+	Eligible     bool              `json:"eligible"`
 }
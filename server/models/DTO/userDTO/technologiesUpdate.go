@@ -0,0 +1,6 @@
+package userDTO
+
+// TechnologiesUpdate - полный список технологий, которыми владеет пользователь
+type TechnologiesUpdate struct {
+	Technologies []uint `json:"technologies" validate:"max=50"`
+}
@@ -0,0 +1,31 @@
+package voteDTO
+
+import "time"
+
+type Item struct {
+	TeamID   uint   `json:"teamId"`
+	TeamName string `json:"teamName"`
+	Points   int    `json:"points"`
+}
+
+type Total struct {
+	TeamID   uint   `json:"teamId"`
+	TeamName string `json:"teamName"`
+	Points   int    `json:"points"`
+	Voters   int    `json:"voters"`
+}
+
+// Get - голосование глазами пользователя. Итоги (Totals) видны только после окончания голосования
+type Get struct {
+	Mode      int        `json:"mode"`
+	Budget    int        `json:"budget"`
+	From      *time.Time `json:"from,omitempty"`
+	To        *time.Time `json:"to,omitempty"`
+	Open      bool       `json:"open"`
+	Closed    bool       `json:"closed"`
+	CanVote   bool       `json:"canVote"`
+	Reason    string     `json:"reason,omitempty"`
+	Votes     []Item     `json:"votes"`
+	Remaining int        `json:"remaining"`
+	Totals    []Total    `json:"totals,omitempty"`
+}
@@ -0,0 +1,12 @@
+package voteDTO
+
+type Input struct {
+	TeamID uint `json:"teamId" validate:"required"`
+	Points int  `json:"points" validate:"min=1"`
+}
+
+// Set - голоса пользователя, заменяющие прежние; пустой список отзывает все голоса.
+// В режиме одного голоса допускается один проект с одним баллом
+type Set struct {
+	Votes []Input `json:"votes" validate:"max=100,dive"`
+}
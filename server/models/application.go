@@ -0,0 +1,41 @@
+package models
+
+import "gorm.io/gorm"
+
+// Статусы заявки на участие
+const (
+	ApplicationRejected = -1
+	ApplicationPending  = 0
+	ApplicationAccepted = 1
+)
+
+// Application - заявка пользователя на участие в хакатоне с ответами на анкету
+type Application struct {
+	gorm.Model
+
+	UserID uint `gorm:"not null;uniqueIndex:idx_application_user_hackathon" json:"user_id"`
+	User   User `gorm:"foreignKey:UserID" json:"-"`
+
+	HackathonID uint      `gorm:"not null;uniqueIndex:idx_application_user_hackathon" json:"hackathon_id"`
+	Hackathon   Hackathon `gorm:"foreignKey:HackathonID" json:"-"`
+
+	Status        int    `gorm:"not null;default:0" json:"status"`
+	ReviewComment string `gorm:"size:2000" json:"review_comment,omitempty"`
+
+	Answers []ApplicationAnswer `gorm:"foreignKey:ApplicationID" json:"answers,omitempty"`
+}
+
+// ApplicationAnswer - ответ на один вопрос анкеты
+type ApplicationAnswer struct {
+	gorm.Model
+
+	ApplicationID uint `gorm:"index;not null" json:"application_id"`
+
+	QuestionID uint         `gorm:"not null" json:"question_id"`
+	Question   FormQuestion `gorm:"foreignKey:QuestionID" json:"-"`
+
+	Text         string        `gorm:"size:10000" json:"text,omitempty"`
+	Choices      []string      `gorm:"serializer:json" json:"choices,omitempty"`
+	Technologies []*Technology `gorm:"many2many:application_answer_technologies;" json:"technologies,omitempty"`
+	Files        []*File       `gorm:"polymorphic:Owner;polymorphicValue:application_answer" json:"files,omitempty"`
+}
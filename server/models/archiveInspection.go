@@ -0,0 +1,58 @@
+package models
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"path"
+	"strings"
+)
+
+// ArchiveEntry - файл или каталог в отчёте о проверке архива
+type ArchiveEntry struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	Dir  bool   `json:"dir,omitempty"`
+}
+
+// ArchiveInspection - отчёт о проверке архива с проектом при загрузке.
+// Отчёт относится к файлу, поэтому перенесённый в новую версию архив повторно не проверяется
+type ArchiveInspection struct {
+	gorm.Model
+
+	FileID uint `gorm:"not null;uniqueIndex" json:"file_id"`
+	File   File `gorm:"foreignKey:FileID" json:"-"`
+
+	Format string `gorm:"size:20;not null" json:"format"`
+	Files  int    `json:"files"`
+	// Распакованный размер, байт
+	Size int64 `json:"size"`
+	// В отчёт попали не все записи архива
+	Truncated bool           `gorm:"default:false" json:"truncated"`
+	Entries   []ArchiveEntry `gorm:"serializer:json;type:text" json:"entries"`
+}
+
+// Наибольший распакованный размер архива, который может задать организатор, МБ
+const ArchiveMaxSizeLimitMB = 20 << 10
+
+// ValidateArchiveRules проверяет правила проверки архивов
+func (h *Hackathon) ValidateArchiveRules() error {
+	if h.ArchiveMaxSizeMB < 0 || h.ArchiveMaxSizeMB > ArchiveMaxSizeLimitMB {
+		return errors.New("распакованный размер архива должен быть от 0 до 20 ГБ")
+	}
+	if len(h.ArchiveRequiredFiles) > 20 || len(h.ArchiveForbiddenExtensions) > 50 {
+		return errors.New("слишком много правил проверки архивов")
+	}
+	for _, pattern := range h.ArchiveRequiredFiles {
+		pattern = strings.TrimSpace(pattern)
+		if _, err := path.Match(pattern, ""); pattern == "" || len(pattern) > 100 || err != nil {
+			return errors.New("неверный шаблон обязательного файла: " + pattern)
+		}
+	}
+	for _, ext := range h.ArchiveForbiddenExtensions {
+		ext = strings.TrimPrefix(strings.TrimSpace(ext), ".")
+		if ext == "" || len(ext) > 20 || strings.ContainsAny(ext, "/\\*?") {
+			return errors.New("неверное запрещённое расширение: " + ext)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package models
+
+import "gorm.io/gorm"
+
+// Способы определения победителей номинации
+const (
+	AwardTrackCriteria = 0 // рейтинг по выбранным критериям
+	AwardTrackManual   = 1 // победителей выбирает организатор
+	AwardTrackAudience = 2 // рейтинг по голосованию зрителей
+)
+
+// AwardTrack - номинация со своими призами, например от спонсора.
+// Номинация считается параллельно общему рейтингу, и одна команда может победить в нескольких
+type AwardTrack struct {
+	gorm.Model
+
+	HackathonID uint      `gorm:"not null;index" json:"hackathon_id"`
+	Hackathon   Hackathon `gorm:"foreignKey:HackathonID" json:"-"`
+	Name        string    `gorm:"size:100;not null" json:"name"`
+	Description string    `gorm:"size:2000" json:"description"`
+	Kind        int       `gorm:"default:0" json:"kind"`
+
+	// В номинации участвуют только команды, подавшие заявку
+	OptIn bool `gorm:"default:false" json:"opt_in"`
+
+	Criteria []Criteria `gorm:"many2many:award_track_criteria;" json:"criteria,omitempty"`
+	Awards   []Award    `gorm:"foreignKey:TrackID" json:"awards,omitempty"`
+}
+
+// AwardTrackEntry - заявка команды на участие в номинации
+type AwardTrackEntry struct {
+	gorm.Model
+
+	TrackID uint `gorm:"not null;uniqueIndex:idx_award_track_entry" json:"track_id"`
+	TeamID  uint `gorm:"not null;uniqueIndex:idx_award_track_entry" json:"team_id"`
+	Team    Team `gorm:"foreignKey:TeamID" json:"-"`
+}
+
+// AwardTrackPick - место команды в номинации, которую решает организатор
+type AwardTrackPick struct {
+	gorm.Model
+
+	TrackID uint `gorm:"not null;uniqueIndex:idx_award_track_pick" json:"track_id"`
+	TeamID  uint `gorm:"not null;uniqueIndex:idx_award_track_pick" json:"team_id"`
+	Team    Team `gorm:"foreignKey:TeamID" json:"-"`
+	Place   int  `gorm:"not null" json:"place"`
+}
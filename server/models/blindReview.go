@@ -0,0 +1,26 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// BlindActive - включена слепая оценка и личности команд ещё скрыты от судей:
+// организатор не раскрыл их вручную и этап оценки не закончился
+func (h *Hackathon) BlindActive(now time.Time) bool {
+	if !h.BlindReview || h.BlindRevealed {
+		return false
+	}
+	return h.State(now).Phase < HackathonPhaseResults
+}
+
+// NewBlindCode генерирует обезличенный код команды вида P-1A2B3C4D
+func NewBlindCode() (string, error) {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "P-" + strings.ToUpper(hex.EncodeToString(buf)), nil
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Объект без ссылок удаляется не сразу: загрузка того же содержимого могла уже на него сослаться
+const BlobCollectDelay = time.Hour
+
+// Blob - содержимое файла в хранилище. Одинаковые файлы хранятся один раз: записи File
+// с тем же SHA-256 ссылаются на общий объект, а RefCount считает такие записи
+type Blob struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	SHA256 string `gorm:"size:64;not null;uniqueIndex"`
+	// Ключ объекта в хранилище. У файлов, загруженных до появления контрольных сумм, это прежнее случайное имя
+	StoredName string `gorm:"size:255;not null;uniqueIndex"`
+	Size       int64  `gorm:"not null"`
+	RefCount   int    `gorm:"not null;default:0"`
+}
+
+// BlobKey - ключ нового объекта в хранилище по его контрольной сумме
+func BlobKey(sum string) string {
+	return "sha256/" + sum[:2] + "/" + sum
+}
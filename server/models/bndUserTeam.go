@@ -1,10 +1,18 @@
 package models
 
+import "time"
+
 type BndUserTeam struct {
 	UserID   uint `gorm:"primaryKey" json:"user_id"`
 	TeamID   uint `gorm:"primaryKey" json:"team_id"`
 	TeamRole int  `gorm:"not null" json:"team_role"`
 
+	// Момент вступления в команду; по нему выбирается преемник капитана
+	CreatedAt time.Time `json:"created_at"`
+
+	// Участник согласен, чтобы его имя показывалось в публичной галерее проектов
+	GalleryConsent bool `gorm:"default:false" json:"gallery_consent"`
+
 	User User `gorm:"foreignKey:UserID" json:"user"`
 	Team Team `gorm:"foreignKey:TeamID" json:"team"`
 }
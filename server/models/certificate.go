@@ -0,0 +1,91 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// Виды сертификатов
+const (
+	CertificateParticipation = 0 // участие в хакатоне
+	CertificateWinner        = 1 // призовое место в общем рейтинге или номинации
+	CertificateMentor        = 2 // ментор
+	CertificateJudge         = 3 // судья, оценивавший проекты
+)
+
+var CertificateKindNames = map[int]string{
+	CertificateParticipation: "участник",
+	CertificateWinner:        "победитель",
+	CertificateMentor:        "ментор",
+	CertificateJudge:         "судья",
+}
+
+// CertificateTemplate - шаблон сертификатов хакатона: фон, шрифт и строки текста с подстановками.
+// Fields хранит []CertificateField в JSON
+type CertificateTemplate struct {
+	gorm.Model
+
+	HackathonID  uint    `gorm:"not null;uniqueIndex" json:"hackathon_id"`
+	BackgroundID *uint   `json:"background_id,omitempty"`
+	Background   *File   `gorm:"foreignKey:BackgroundID" json:"-"`
+	FontID       uint    `gorm:"not null" json:"font_id"`
+	Font         *File   `gorm:"foreignKey:FontID" json:"-"`
+	PageWidth    float64 `gorm:"not null" json:"page_width"`
+	PageHeight   float64 `gorm:"not null" json:"page_height"`
+	Fields       string  `gorm:"type:text;not null" json:"-"`
+}
+
+// CertificateField - строка сертификата. Координаты в пунктах от левого верхнего угла страницы,
+// Y - базовая линия строки. Kinds ограничивает строку видами сертификатов, пустой - для всех
+type CertificateField struct {
+	Text  string  `json:"text"`
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+	Size  float64 `json:"size"`
+	Align string  `json:"align"`
+	Color string  `json:"color"`
+	Kinds []int   `json:"kinds,omitempty"`
+}
+
+// Certificate - выданный сертификат. Subject различает сертификаты одного пользователя:
+// вид сертификата и, для победителей, награду
+type Certificate struct {
+	gorm.Model
+
+	HackathonID uint      `gorm:"not null;uniqueIndex:idx_certificate" json:"hackathon_id"`
+	Hackathon   Hackathon `gorm:"foreignKey:HackathonID" json:"-"`
+	UserID      uint      `gorm:"not null;uniqueIndex:idx_certificate" json:"user_id"`
+	User        User      `gorm:"foreignKey:UserID" json:"-"`
+	Subject     string    `gorm:"size:50;not null;uniqueIndex:idx_certificate" json:"subject"`
+	Kind        int       `gorm:"not null" json:"kind"`
+
+	TeamID    *uint  `json:"team_id,omitempty"`
+	TeamName  string `gorm:"size:50" json:"team_name,omitempty"`
+	Place     int    `json:"place,omitempty"`
+	TrackName string `gorm:"size:100" json:"track_name,omitempty"`
+	AwardID   *uint  `json:"award_id,omitempty"`
+
+	// Сертификат победителя отзывается, если после исправления результатов команда лишилась награды
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+
+	// Код проверки подлинности, публичный
+	Code string `gorm:"size:32;not null;uniqueIndex" json:"code"`
+}
+
+// NewCertificateCode генерирует код проверки вида ABCD-EFGH-IJKL-MNOP
+func NewCertificateCode() (string, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	raw := base32.StdEncoding.EncodeToString(buf)
+
+	groups := make([]string, 0, 4)
+	for i := 0; i < len(raw); i += 4 {
+		groups = append(groups, raw[i:i+4])
+	}
+	return strings.Join(groups, "-"), nil
+}
@@ -2,12 +2,26 @@ package models
 
 import "gorm.io/gorm"
 
+// Способы нормализации оценок по критерию
+const (
+	CriteriaNormalizationNone   = 0 // исходные баллы
+	CriteriaNormalizationMinMax = 1 // приведение к диапазону 0..1 по min/max критерия
+	CriteriaNormalizationZScore = 2 // z-оценка относительно остальных оценок того же судьи
+)
+
 type Criteria struct {
 	gorm.Model
 	Name     string `gorm:"size:1000;not null" json:"name"`
 	MaxScore uint   `gorm:"default:10" json:"max_score"`
 	MinScore uint   `gorm:"default:0" json:"min_score"`
 
+	// Без значения по умолчанию в gorm: иначе явно заданный нулевой вес сохранился бы как 1
+	Weight        float64 `json:"weight"`
+	Normalization int     `gorm:"default:0" json:"normalization"`
+
+	// Критерий отборочного тура; критерии без этапа относятся к финальной оценке
+	StepID *uint `gorm:"index" json:"step_id,omitempty"`
+
 	HackathonID uint      `gorm:"not null" json:"-"`
 	Hackathon   Hackathon `gorm:"foreignKey:HackathonID" json:"-"`
 }
@@ -8,6 +8,7 @@ type File struct {
 	StoredName   string `gorm:"size:255;not null"`
 	URL          string `gorm:"size:512;not null"`
 	Size         int64  `gorm:"not null"`
+	SHA256       string `gorm:"size:64;index"` // пусто у файлов, загруженных до появления контрольных сумм
 	Type         string `gorm:"size:100;not null"`
 	UploadedByID uint   `gorm:"not null"`
 	UploadedBy   *User  `gorm:"foreignKey:UploadedByID"`
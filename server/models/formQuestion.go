@@ -0,0 +1,26 @@
+package models
+
+import "gorm.io/gorm"
+
+// Типы вопросов анкеты участника
+const (
+	FormQuestionText       = 0 // свободный ответ
+	FormQuestionChoice     = 1 // выбор из вариантов
+	FormQuestionFile       = 2 // загрузка файла
+	FormQuestionTechnology = 3 // выбор технологий из справочника
+)
+
+// FormQuestion - вопрос анкеты, которую заполняет участник при записи на хакатон
+type FormQuestion struct {
+	gorm.Model
+
+	Label    string   `gorm:"size:1000;not null" json:"label"`
+	Type     int      `gorm:"not null;default:0" json:"type"`
+	Required bool     `gorm:"default:false" json:"required"`
+	Multiple bool     `gorm:"default:false" json:"multiple"`
+	Options  []string `gorm:"serializer:json" json:"options,omitempty"`
+	Position int      `gorm:"default:0" json:"position"`
+
+	HackathonID uint      `gorm:"index;not null" json:"hackathon_id"`
+	Hackathon   Hackathon `gorm:"foreignKey:HackathonID" json:"-"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "gorm.io/gorm"
+
+// GalleryEntry - карточка проекта команды в публичной галерее хакатона.
+// Пустые описание и ссылки берутся из итоговой версии сдачи
+type GalleryEntry struct {
+	gorm.Model
+
+	HackathonID uint `gorm:"not null;index" json:"hackathon_id"`
+	TeamID      uint `gorm:"not null;uniqueIndex" json:"team_id"`
+	Team        Team `gorm:"foreignKey:TeamID" json:"-"`
+
+	// Команда согласна показывать проект в галерее
+	Published bool `gorm:"default:false" json:"published"`
+	// Организатор скрыл проект из галереи
+	Hidden       bool   `gorm:"default:false" json:"hidden"`
+	HiddenReason string `gorm:"size:500" json:"hidden_reason,omitempty"`
+
+	Description   string `gorm:"size:5000" json:"description"`
+	RepositoryURL string `gorm:"size:1000" json:"repository_url,omitempty"`
+	DemoURL       string `gorm:"size:1000" json:"demo_url,omitempty"`
+
+	Screenshots []File `gorm:"polymorphic:Owner;polymorphicValue:gallery_screenshot" json:"screenshots,omitempty"`
+}
+
+// Visible - карточка показывается посетителям галереи
+func (e *GalleryEntry) Visible() bool {
+	return e.Published && !e.Hidden
+}
+
+// GalleryOpen - галерея хакатона включена организатором и доступна после публикации результатов
+func (h *Hackathon) GalleryOpen() bool {
+	return h.GalleryEnabled && h.ResultsPublished()
+}
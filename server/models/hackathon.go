@@ -5,6 +5,16 @@ import (
 	"time"
 )
 
+// Статусы публикации хакатона
+const (
+	HackathonStatusCancelled     = -1 // отменён организатором
+	HackathonStatusDraft         = 0  // черновик, виден только организаторам
+	HackathonStatusPublished     = 1  // одобрен администратором и виден всем
+	HackathonStatusPendingReview = 3  // ожидает проверки администратором
+)
+
+// Значение 2 в клиенте означает архивный хакатон и не используется для других статусов
+
 type Hackathon struct {
 	gorm.Model
 
@@ -18,6 +28,52 @@ type Hackathon struct {
 	EvalDateFrom time.Time `json:"eval_date_from,omitempty"`
 	EvalDateTo   time.Time `json:"eval_date_to,omitempty"`
 
+	ScoreAggregation int `gorm:"default:0" json:"score_aggregation"`
+
+	// Ограничения на размер команд и число участников; 0 - без ограничения
+	MinTeamSize     int `gorm:"default:0" json:"min_team_size"`
+	MaxTeamSize     int `gorm:"default:0" json:"max_team_size"`
+	MaxParticipants int `gorm:"default:0" json:"max_participants"`
+
+	// Льготный период после окончания работы, в который сдача принимается с пометкой об опоздании
+	SubmissionGraceMinutes int `gorm:"default:0" json:"submission_grace_minutes"`
+
+	// Правила проверки архивов с проектами: обязательные файлы (шаблоны вроде README*),
+	// запрещённые расширения и наибольший распакованный размер в МБ (0 - по умолчанию)
+	ArchiveRequiredFiles       []string `gorm:"serializer:json" json:"archive_required_files,omitempty"`
+	ArchiveForbiddenExtensions []string `gorm:"serializer:json" json:"archive_forbidden_extensions,omitempty"`
+	ArchiveMaxSizeMB           int      `gorm:"default:0" json:"archive_max_size_mb"`
+
+	// Сколько судей оценивает каждую команду при распределении; 0 - распределение не задано
+	JudgesPerTeam int `gorm:"default:0" json:"judges_per_team"`
+
+	// Слепая оценка: судьи видят команды под кодами до конца оценки или до раскрытия организатором
+	BlindReview   bool `gorm:"default:false" json:"blind_review"`
+	BlindRevealed bool `gorm:"default:false" json:"blind_revealed"`
+
+	// Зрительское голосование участников за чужие проекты; VotingWeight - вес доли голосов
+	// в итоговом балле общего рейтинга, 0 - голоса учитываются только в номинации
+	VotingMode   int        `gorm:"default:0" json:"voting_mode"`
+	VoteBudget   int        `gorm:"default:0" json:"vote_budget"`
+	VotingFrom   *time.Time `json:"voting_from,omitempty"`
+	VotingTo     *time.Time `json:"voting_to,omitempty"`
+	VotingWeight float64    `gorm:"default:0" json:"voting_weight"`
+
+	// Момент публикации результатов; до публикации участники результатов не видят
+	ResultsPublishedAt *time.Time `json:"results_published_at,omitempty"`
+
+	// Публичная галерея проектов, в которой команды показывают свои работы
+	GalleryEnabled bool `gorm:"default:false" json:"gallery_enabled"`
+
+	Status        int        `gorm:"default:0;index" json:"status"`
+	ReviewComment string     `gorm:"size:2000" json:"review_comment,omitempty"`
+	SubmittedAt   *time.Time `json:"submitted_at,omitempty"`
+
+	// Ручное продление или досрочное закрытие этапа организатором
+	PhaseOverride      *int       `json:"phase_override,omitempty"`
+	PhaseOverrideOpen  bool       `gorm:"default:false" json:"phase_override_open"`
+	PhaseOverrideUntil *time.Time `json:"phase_override_until,omitempty"`
+
 	OrganizationID uint          `gorm:"not null" json:"organization_id"`
 	Organization   *Organization `gorm:"foreignKey:OrganizationID" json:"organization,omitempty"`
 
@@ -0,0 +1,145 @@
+package models
+
+import "time"
+
+// Этапы жизненного цикла хакатона
+const (
+	HackathonPhaseDraft        = 0 // хакатон создан, регистрация ещё не началась
+	HackathonPhaseRegistration = 1 // регистрация участников
+	HackathonPhaseWork         = 2 // работа над проектами
+	HackathonPhaseEvaluation   = 3 // оценка проектов
+	HackathonPhaseResults      = 4 // подведение итогов
+	HackathonPhaseArchived     = 5 // хакатон завершён
+)
+
+// ResultsPeriod - сколько длится этап итогов, если после оценки нет других этапов
+const ResultsPeriod = 30 * 24 * time.Hour
+
+var HackathonPhaseNames = map[int]string{
+	HackathonPhaseDraft:        "черновик",
+	HackathonPhaseRegistration: "регистрация",
+	HackathonPhaseWork:         "работа над проектами",
+	HackathonPhaseEvaluation:   "оценка проектов",
+	HackathonPhaseResults:      "подведение итогов",
+	HackathonPhaseArchived:     "архив",
+}
+
+// HackathonState - текущий этап хакатона.
+// Open показывает, доступны ли действия этапа; Until - когда состояние изменится
+type HackathonState struct {
+	Phase    int        `json:"phase"`
+	Name     string     `json:"name"`
+	Open     bool       `json:"open"`
+	Until    *time.Time `json:"until,omitempty"`
+	Override bool       `json:"override"`
+}
+
+// Allows проверяет, что хакатон находится на одном из этапов и этот этап открыт
+func (s HackathonState) Allows(phases ...int) bool {
+	if !s.Open {
+		return false
+	}
+	for _, phase := range phases {
+		if s.Phase == phase {
+			return true
+		}
+	}
+	return false
+}
+
+// phaseWindow - период этапа, заданный датами хакатона
+type phaseWindow struct {
+	Phase int
+	From  time.Time
+	To    time.Time
+}
+
+func (h *Hackathon) phaseWindows() []phaseWindow {
+	return []phaseWindow{
+		{HackathonPhaseRegistration, h.RegDateFrom, h.RegDateTo},
+		{HackathonPhaseWork, h.WorkDateFrom, h.WorkDateTo},
+		{HackathonPhaseEvaluation, h.EvalDateFrom, h.EvalDateTo},
+	}
+}
+
+// PhaseWindow возвращает даты этапа; ok = false для этапов без собственного периода
+func (h *Hackathon) PhaseWindow(phase int) (from, to time.Time, ok bool) {
+	for _, window := range h.phaseWindows() {
+		if window.Phase == phase {
+			return window.From, window.To, true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// ResultsUntil возвращает момент перехода хакатона в архив: окончание последнего
+// шага программы, если он позже оценки, иначе конец оценки плюс ResultsPeriod.
+// Шаги учитываются, только если они загружены вместе с хакатоном
+func (h *Hackathon) ResultsUntil() time.Time {
+	until := h.EvalDateTo.Add(ResultsPeriod)
+
+	var lastStep time.Time
+	for _, step := range h.Steps {
+		if step != nil && step.EndDate.After(lastStep) {
+			lastStep = step.EndDate
+		}
+	}
+	if lastStep.After(h.EvalDateTo) {
+		until = lastStep
+	}
+
+	return until
+}
+
+// State вычисляет этап хакатона на момент now.
+// Этап начинается с даты начала своего периода и длится до начала следующего;
+// после даты окончания периода этап считается закрытым.
+// Ручное изменение организатором действует до PhaseOverrideUntil
+func (h *Hackathon) State(now time.Time) HackathonState {
+	// Неопубликованный хакатон остаётся черновиком, отменённый сразу уходит в архив
+	switch h.Status {
+	case HackathonStatusCancelled:
+		return HackathonState{Phase: HackathonPhaseArchived, Name: HackathonPhaseNames[HackathonPhaseArchived]}
+	case HackathonStatusDraft, HackathonStatusPendingReview:
+		return HackathonState{Phase: HackathonPhaseDraft, Name: HackathonPhaseNames[HackathonPhaseDraft]}
+	}
+
+	if h.PhaseOverride != nil && h.PhaseOverrideUntil != nil && now.Before(*h.PhaseOverrideUntil) {
+		until := *h.PhaseOverrideUntil
+		return HackathonState{
+			Phase:    *h.PhaseOverride,
+			Name:     HackathonPhaseNames[*h.PhaseOverride],
+			Open:     h.PhaseOverrideOpen,
+			Until:    &until,
+			Override: true,
+		}
+	}
+
+	windows := h.phaseWindows()
+
+	phase, open, until := HackathonPhaseDraft, false, h.RegDateFrom
+	for i, window := range windows {
+		if window.From.IsZero() || now.Before(window.From) {
+			break
+		}
+
+		phase, open, until = window.Phase, now.Before(window.To), window.To
+		if !open && i+1 < len(windows) {
+			until = windows[i+1].From
+		}
+	}
+
+	if phase == HackathonPhaseEvaluation && !open {
+		phase, until = HackathonPhaseResults, h.ResultsUntil()
+		open = now.Before(until)
+		if !open {
+			phase = HackathonPhaseArchived
+		}
+	}
+
+	state := HackathonState{Phase: phase, Name: HackathonPhaseNames[phase], Open: open}
+	if phase != HackathonPhaseArchived && !until.IsZero() {
+		state.Until = &until
+	}
+	return state
+}
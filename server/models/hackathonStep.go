@@ -15,6 +15,13 @@ type HackathonStep struct {
 	StartDate   time.Time `json:"start_date"`
 	EndDate     time.Time `json:"end_date"`
 
+	// Этап может быть отборочным туром со своими критериями и правилом прохода дальше
+	IsRound     bool       `gorm:"default:false" json:"is_round"`
+	CutoffKind  int        `gorm:"default:0" json:"cutoff_kind"`
+	CutoffValue float64    `gorm:"default:0" json:"cutoff_value"`
+	ClosedAt    *time.Time `json:"closed_at,omitempty"`
+	Criteria    []Criteria `gorm:"foreignKey:StepID" json:"criteria,omitempty"`
+
 	HackathonID uint      `json:"hackathon_id"`
 	Hackathon   Hackathon `gorm:"foreignKey:HackathonID" json:"-"`
 }
@@ -32,6 +39,10 @@ func (s *HackathonStep) Validate() error {
 		return errors.New("дата окончания этапа должна быть после даты начала")
 	}
 
+	if s.IsRound {
+		return s.validateCutoff()
+	}
+
 	return nil
 }
 
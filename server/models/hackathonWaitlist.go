@@ -0,0 +1,15 @@
+package models
+
+import "gorm.io/gorm"
+
+// HackathonWaitlist - очередь ожидания на участие, когда достигнут лимит участников.
+// Порядок очереди определяется временем записи
+type HackathonWaitlist struct {
+	gorm.Model
+
+	UserID uint `gorm:"not null;uniqueIndex:idx_waitlist_user_hackathon" json:"user_id"`
+	User   User `gorm:"foreignKey:UserID" json:"-"`
+
+	HackathonID uint      `gorm:"not null;uniqueIndex:idx_waitlist_user_hackathon" json:"hackathon_id"`
+	Hackathon   Hackathon `gorm:"foreignKey:HackathonID" json:"-"`
+}
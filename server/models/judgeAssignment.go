@@ -0,0 +1,30 @@
+package models
+
+import "gorm.io/gorm"
+
+// JudgeAssignment - назначение судьи на оценку команды
+type JudgeAssignment struct {
+	gorm.Model
+
+	HackathonID uint `gorm:"not null;index" json:"hackathon_id"`
+	TeamID      uint `gorm:"not null;uniqueIndex:idx_judge_assignment" json:"team_id"`
+	Team        Team `gorm:"foreignKey:TeamID" json:"-"`
+	UserID      uint `gorm:"not null;uniqueIndex:idx_judge_assignment" json:"user_id"`
+	User        User `gorm:"foreignKey:UserID" json:"-"`
+
+	// Назначение сделано автоматическим распределением
+	Auto bool `gorm:"default:false" json:"auto"`
+}
+
+// JudgeConflict - конфликт интересов, заявленный организатором:
+// судья не может оценивать команду (например, был её ментором)
+type JudgeConflict struct {
+	gorm.Model
+
+	HackathonID uint   `gorm:"not null;index" json:"hackathon_id"`
+	TeamID      uint   `gorm:"not null;uniqueIndex:idx_judge_conflict" json:"team_id"`
+	Team        Team   `gorm:"foreignKey:TeamID" json:"-"`
+	UserID      uint   `gorm:"not null;uniqueIndex:idx_judge_conflict" json:"user_id"`
+	User        User   `gorm:"foreignKey:UserID" json:"-"`
+	Reason      string `gorm:"size:500" json:"reason"`
+}
@@ -2,6 +2,13 @@ package models
 
 import "gorm.io/gorm"
 
+// Статусы проверки организации администратором
+const (
+	OrganizationStatusRejected = -1
+	OrganizationStatusPending  = 0
+	OrganizationStatusApproved = 1
+)
+
 type Organization struct {
 	gorm.Model
 	LegalName    string `gorm:"unique;not null" json:"legalName"`
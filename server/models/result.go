@@ -0,0 +1,58 @@
+package models
+
+import "gorm.io/gorm"
+
+// Способы разрешения ничьих при равном итоговом балле
+const (
+	TieBreakCriterion  = 0 // больший балл по выбранному критерию
+	TieBreakSubmission = 1 // более ранняя сдача итоговой версии проекта
+	TieBreakOrganizer  = 2 // порядок, заданный организатором
+)
+
+var TieBreakNames = map[int]string{
+	TieBreakCriterion:  "балл по критерию",
+	TieBreakSubmission: "время сдачи",
+	TieBreakOrganizer:  "решение организатора",
+}
+
+// TieBreaker - правило разрешения ничьих; правила применяются по возрастанию Position
+type TieBreaker struct {
+	gorm.Model
+
+	HackathonID uint      `gorm:"not null;index" json:"hackathon_id"`
+	Position    int       `gorm:"not null" json:"position"`
+	Kind        int       `gorm:"not null" json:"kind"`
+	CriteriaID  *uint     `json:"criteria_id,omitempty"`
+	Criteria    *Criteria `gorm:"foreignKey:CriteriaID" json:"-"`
+}
+
+// TieDecision - место команды в порядке, которым организатор разрешает ничьи; меньший Rank выше
+type TieDecision struct {
+	gorm.Model
+
+	HackathonID uint `gorm:"not null;index" json:"hackathon_id"`
+	TeamID      uint `gorm:"not null;uniqueIndex" json:"team_id"`
+	Team        Team `gorm:"foreignKey:TeamID" json:"-"`
+	Rank        int  `gorm:"not null" json:"rank"`
+}
+
+// ResultSnapshot - строка опубликованной таблицы результатов общего рейтинга или номинации (TrackID).
+// Details хранит полную строку результата в JSON, чтобы таблица не менялась после публикации
+type ResultSnapshot struct {
+	gorm.Model
+
+	HackathonID uint    `gorm:"not null;index" json:"hackathon_id"`
+	TrackID     *uint   `gorm:"index" json:"track_id,omitempty"`
+	TeamID      uint    `gorm:"not null" json:"team_id"`
+	TeamName    string  `gorm:"size:50" json:"team_name"`
+	Position    int     `gorm:"not null" json:"position"`
+	Place       int     `gorm:"not null" json:"place"`
+	Score       float64 `json:"score"`
+	AwardID     *uint   `json:"award_id,omitempty"`
+	Details     string  `gorm:"type:text" json:"-"`
+}
+
+// ResultsPublished - результаты хакатона опубликованы и зафиксированы
+func (h *Hackathon) ResultsPublished() bool {
+	return h.ResultsPublishedAt != nil
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"math"
+	"time"
+)
+
+// Правила прохода в следующий тур
+const (
+	RoundCutoffTop   = 0 // проходят первые N мест
+	RoundCutoffScore = 1 // проходят команды с баллом не ниже порога
+)
+
+// RoundResult - итог команды в закрытом отборочном туре
+type RoundResult struct {
+	gorm.Model
+
+	HackathonID uint    `gorm:"not null;index" json:"hackathon_id"`
+	StepID      uint    `gorm:"not null;uniqueIndex:idx_round_result" json:"step_id"`
+	TeamID      uint    `gorm:"not null;uniqueIndex:idx_round_result" json:"team_id"`
+	Team        Team    `gorm:"foreignKey:TeamID" json:"-"`
+	Place       int     `gorm:"not null" json:"place"`
+	Score       float64 `json:"score"`
+	Advanced    bool    `gorm:"default:false" json:"advanced"`
+}
+
+func (s *HackathonStep) validateCutoff() error {
+	switch s.CutoffKind {
+	case RoundCutoffTop:
+		if s.CutoffValue < 1 || s.CutoffValue != math.Trunc(s.CutoffValue) {
+			return errors.New("число команд, проходящих дальше, должно быть целым и не меньше 1")
+		}
+	case RoundCutoffScore:
+		if s.CutoffValue < 0 {
+			return errors.New("проходной балл не может быть отрицательным")
+		}
+	default:
+		return errors.New("неизвестное правило прохода в следующий тур")
+	}
+	return nil
+}
+
+// RoundOpen проверяет, идёт ли отборочный тур в момент now
+func (s *HackathonStep) RoundOpen(now time.Time) bool {
+	return s.IsRound && s.ClosedAt == nil && !now.Before(s.StartDate) && !now.After(s.EndDate)
+}
+
+// Advances проверяет, проходит ли дальше команда с местом place и баллом score
+func (s *HackathonStep) Advances(place int, score float64) bool {
+	if s.CutoffKind == RoundCutoffScore {
+		return score >= s.CutoffValue
+	}
+	return place <= int(s.CutoffValue)
+}
@@ -2,13 +2,23 @@ package models
 
 import "gorm.io/gorm"
 
+// Способы агрегации оценок нескольких судей
+const (
+	ScoreAggregationMean        = 0 // среднее арифметическое
+	ScoreAggregationMedian      = 1 // медиана
+	ScoreAggregationTrimmedMean = 2 // усечённое среднее (без крайних оценок)
+)
+
 type Score struct {
 	gorm.Model
-	TeamID     uint    `gorm:"not null" json:"team_id"`
-	CriteriaID uint    `gorm:"not null" json:"criteria_id"`
+	// Судья ставит по критерию не больше одной действующей оценки команде
+	TeamID     uint    `gorm:"not null;index;uniqueIndex:idx_score_judge,where:deleted_at IS NULL" json:"team_id"`
+	CriteriaID uint    `gorm:"not null;uniqueIndex:idx_score_judge,where:deleted_at IS NULL" json:"criteria_id"`
+	UserID     uint    `gorm:"index;uniqueIndex:idx_score_judge,where:deleted_at IS NULL" json:"user_id"`
 	Score      float64 `gorm:"not null" json:"score"`
 	Comment    string  `gorm:"type:text" json:"comment"`
 
 	Team     Team     `gorm:"foreignKey:TeamID"`
 	Criteria Criteria `gorm:"foreignKey:CriteriaID"`
+	User     User     `gorm:"foreignKey:UserID" json:"-"`
 }
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Состояния проверки на сходство
+const (
+	SimilarityRunning = "running"
+	SimilarityDone    = "done"
+	SimilarityFailed  = "failed"
+)
+
+// Проверка, которая идёт дольше, считается прерванной (например, перезапуском сервера)
+const SimilarityTimeout = time.Hour
+
+// SimilarityReport - последняя проверка итоговых сдач хакатона на сходство.
+// Повторная проверка заменяет результаты предыдущей
+type SimilarityReport struct {
+	gorm.Model
+
+	HackathonID uint `gorm:"not null;uniqueIndex" json:"hackathon_id"`
+
+	Status     string     `gorm:"size:20;not null" json:"status"`
+	Error      string     `gorm:"size:1000" json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	StartedBy  uint       `json:"started_by"`
+
+	// Сколько команд сравнивалось
+	Teams int `json:"teams"`
+	// Команды без архива с исходным кодом или с нечитаемым архивом
+	SkippedTeams []uint `gorm:"serializer:json;type:text" json:"skipped_teams"`
+	// Сколько фрагментов признано шаблонным кодом и с какого числа команд
+	Boilerplate     int `json:"boilerplate"`
+	BoilerplateDocs int `json:"boilerplate_docs"`
+}
+
+// Running - проверка ещё идёт
+func (r *SimilarityReport) Running(now time.Time) bool {
+	return r.Status == SimilarityRunning && now.Sub(r.StartedAt) < SimilarityTimeout
+}
+
+// SimilarityRegion - совпадающие строки файлов двух проектов
+type SimilarityRegion struct {
+	FileA   string `json:"file_a"`
+	StartA  int    `json:"start_a"`
+	EndA    int    `json:"end_a"`
+	FileB   string `json:"file_b"`
+	StartB  int    `json:"start_b"`
+	EndB    int    `json:"end_b"`
+	Matches int    `json:"matches"`
+}
+
+// SimilarityPair - пара команд с похожим кодом; TeamAID меньше TeamBID.
+// ScoreA - доля кода команды A, найденного у B, ScoreB - наоборот
+type SimilarityPair struct {
+	gorm.Model
+
+	HackathonID uint `gorm:"not null;index" json:"hackathon_id"`
+	TeamAID     uint `gorm:"not null;index" json:"team_a_id"`
+	TeamA       Team `gorm:"foreignKey:TeamAID" json:"-"`
+	TeamBID     uint `gorm:"not null;index" json:"team_b_id"`
+	TeamB       Team `gorm:"foreignKey:TeamBID" json:"-"`
+
+	Score   float64            `gorm:"not null" json:"score"`
+	ScoreA  float64            `json:"score_a"`
+	ScoreB  float64            `json:"score_b"`
+	Matches int                `json:"matches"`
+	Regions []SimilarityRegion `gorm:"serializer:json;type:text" json:"regions"`
+}
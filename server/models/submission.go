@@ -0,0 +1,111 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Типы артефактов сдачи проекта
+const (
+	ArtifactSourceArchive = 0 // архив с исходным кодом
+	ArtifactSlides        = 1 // презентация
+	ArtifactDemoVideo     = 2 // ссылка на демо-видео
+	ArtifactRepository    = 3 // ссылка на репозиторий
+)
+
+var ArtifactKindNames = map[int]string{
+	ArtifactSourceArchive: "исходный код",
+	ArtifactSlides:        "презентация",
+	ArtifactDemoVideo:     "демо-видео",
+	ArtifactRepository:    "репозиторий",
+}
+
+// ArtifactIsLink - артефакт задаётся ссылкой, а не файлом
+func ArtifactIsLink(kind int) bool {
+	return kind == ArtifactDemoVideo || kind == ArtifactRepository
+}
+
+// Submission - версия сдачи проекта команды. Версии не изменяются:
+// каждая сдача создаёт новую, последняя версия считается итоговой
+type Submission struct {
+	gorm.Model
+
+	TeamID      uint `gorm:"not null;uniqueIndex:idx_submission_team_version" json:"team_id"`
+	Team        Team `gorm:"foreignKey:TeamID" json:"-"`
+	HackathonID uint `gorm:"not null;index" json:"hackathon_id"`
+	Version     int  `gorm:"not null;uniqueIndex:idx_submission_team_version" json:"version"`
+
+	SubmittedByID uint  `gorm:"not null" json:"submitted_by_id"`
+	SubmittedBy   *User `gorm:"foreignKey:SubmittedByID" json:"-"`
+
+	Description string `gorm:"size:5000" json:"description"`
+	// Сдано после окончания работы над проектами, в льготный период
+	IsLate bool `gorm:"default:false" json:"is_late"`
+	// Отборочный тур, во время которого сдана версия
+	StepID *uint `gorm:"index" json:"step_id,omitempty"`
+
+	Artifacts []SubmissionArtifact `gorm:"foreignKey:SubmissionID" json:"artifacts,omitempty"`
+}
+
+// SubmissionArtifact - файл или ссылка в составе версии. Неизменённые артефакты
+// переносятся в следующую версию и ссылаются на тот же файл
+type SubmissionArtifact struct {
+	gorm.Model
+
+	SubmissionID uint   `gorm:"not null;index" json:"submission_id"`
+	Kind         int    `gorm:"not null" json:"kind"`
+	URL          string `gorm:"size:1000" json:"url,omitempty"`
+	FileID       *uint  `json:"file_id,omitempty"`
+	File         *File  `gorm:"foreignKey:FileID" json:"file,omitempty"`
+}
+
+// PrimaryFile возвращает основной файл версии: архив с кодом, иначе первый файл
+func (s *Submission) PrimaryFile() *File {
+	var first *File
+	for i := range s.Artifacts {
+		artifact := &s.Artifacts[i]
+		if artifact.File == nil {
+			continue
+		}
+		if artifact.Kind == ArtifactSourceArchive {
+			return artifact.File
+		}
+		if first == nil {
+			first = artifact.File
+		}
+	}
+	return first
+}
+
+// SubmissionDeadline - срок сдачи: окончание работы над проектами
+// или продление этого этапа организатором
+func (h *Hackathon) SubmissionDeadline() time.Time {
+	deadline := h.WorkDateTo
+	if h.PhaseOverride != nil && *h.PhaseOverride == HackathonPhaseWork && h.PhaseOverrideOpen &&
+		h.PhaseOverrideUntil != nil && h.PhaseOverrideUntil.After(deadline) {
+		deadline = *h.PhaseOverrideUntil
+	}
+	return deadline
+}
+
+// SubmissionOpen проверяет, можно ли сдать новую версию в момент now.
+// После срока сдачи действует льготный период: версия принимается, но помечается опоздавшей.
+// По его окончании итоговая версия фиксируется
+func (h *Hackathon) SubmissionOpen(now time.Time) (open, late bool) {
+	state := h.State(now)
+	deadline := h.SubmissionDeadline()
+	if state.Allows(HackathonPhaseWork) {
+		return true, now.After(deadline)
+	}
+
+	// Ручное закрытие или продление другого этапа отменяет льготный период
+	if state.Override || h.Status != HackathonStatusPublished || h.WorkDateFrom.IsZero() || now.Before(h.WorkDateFrom) {
+		return false, false
+	}
+
+	grace := time.Duration(h.SubmissionGraceMinutes) * time.Minute
+	if now.After(deadline) && !now.After(deadline.Add(grace)) {
+		return true, true
+	}
+	return false, false
+}
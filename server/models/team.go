@@ -7,6 +7,9 @@ type Team struct {
 
 	Name string `gorm:"size:50;not null" json:"name"`
 
+	// Код команды, под которым её видят судьи при слепой оценке
+	BlindCode string `gorm:"size:16;index" json:"-"`
+
 	Project *File         `gorm:"polymorphic:Owner;polymorphicValue:team" json:"team_project,omitempty"`
 	Users   []BndUserTeam `gorm:"foreignKey:TeamID" json:"users,omitempty"`
 
@@ -15,6 +18,18 @@ type Team struct {
 	Scores      []Score   `gorm:"foreignKey:TeamID" json:"scores,omitempty"`
 }
 
+func (t *Team) BeforeCreate(tx *gorm.DB) error {
+	if t.BlindCode != "" {
+		return nil
+	}
+	code, err := NewBlindCode()
+	if err != nil {
+		return err
+	}
+	t.BlindCode = code
+	return nil
+}
+
 func (t *Team) AfterCreate(tx *gorm.DB) error {
 	// Create team chat (type 3)
 	teamChat := Chat{
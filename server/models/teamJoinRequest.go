@@ -0,0 +1,24 @@
+package models
+
+import "gorm.io/gorm"
+
+// Статусы заявки на вступление в команду
+const (
+	TeamJoinRequestDeclined = -1
+	TeamJoinRequestPending  = 0
+	TeamJoinRequestAccepted = 1
+)
+
+// TeamJoinRequest - заявка участника на вступление в команду, встречная к TeamInvite
+type TeamJoinRequest struct {
+	gorm.Model
+
+	User   User `gorm:"foreignKey:UserID" json:"user"`
+	UserID uint `gorm:"not null;index" json:"user_id"`
+
+	TeamID uint `gorm:"not null;index" json:"team_id"`
+	Team   Team `gorm:"foreignKey:TeamID" json:"team"`
+
+	Message string `gorm:"size:1000" json:"message"`
+	Status  int    `gorm:"not null;default:0" json:"status"`
+}
@@ -0,0 +1,53 @@
+package models
+
+// Роли в команде
+const (
+	TeamRoleMember    = 1 // участник
+	TeamRoleCaptain   = 2 // капитан, в команде он один
+	TeamRoleCoCaptain = 3 // заместитель капитана
+)
+
+// Действия в команде, доступ к которым зависит от роли
+const (
+	TeamActionInvite      = "invite"       // приглашение участников и рассмотрение заявок
+	TeamActionKick        = "kick"         // исключение участников
+	TeamActionRename      = "rename"       // изменение названия команды
+	TeamActionUpload      = "upload"       // загрузка проекта и заявки на номинации
+	TeamActionManageRoles = "manage_roles" // передача капитанства, назначение заместителей
+	TeamActionDisband     = "disband"      // расформирование команды
+)
+
+var TeamRoleNames = map[int]string{
+	TeamRoleMember:    "участник",
+	TeamRoleCaptain:   "капитан",
+	TeamRoleCoCaptain: "заместитель капитана",
+}
+
+// TeamPermissions - какие действия доступны каждой роли
+var TeamPermissions = map[int][]string{
+	TeamRoleCaptain: {
+		TeamActionInvite, TeamActionKick, TeamActionRename,
+		TeamActionUpload, TeamActionManageRoles, TeamActionDisband,
+	},
+	TeamRoleCoCaptain: {TeamActionInvite, TeamActionKick, TeamActionRename, TeamActionUpload},
+	TeamRoleMember:    {},
+}
+
+// TeamRoleCan проверяет, доступно ли действие роли
+func TeamRoleCan(role int, action string) bool {
+	for _, allowed := range TeamPermissions[role] {
+		if allowed == action {
+			return true
+		}
+	}
+	return false
+}
+
+// TeamRoleCanKick проверяет, может ли роль исключить участника с ролью target:
+// капитана исключить нельзя, заместителя может исключить только капитан
+func TeamRoleCanKick(role, target int) bool {
+	if !TeamRoleCan(role, TeamActionKick) || target == TeamRoleCaptain {
+		return false
+	}
+	return target != TeamRoleCoCaptain || role == TeamRoleCaptain
+}
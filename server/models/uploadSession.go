@@ -0,0 +1,35 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Сколько хранится загрузка, в которую не поступают данные. Каждая принятая часть продлевает срок
+const UploadSessionLifetime = 24 * time.Hour
+
+// UploadSession - возобновляемая загрузка большого файла частями по протоколу tus.
+// Данные копятся на диске сервера и переносятся в хранилище, когда загрузку используют
+type UploadSession struct {
+	gorm.Model
+
+	Token  string `gorm:"size:36;not null;uniqueIndex" json:"id"`
+	UserID uint   `gorm:"not null;index" json:"user_id"`
+	Name   string `gorm:"size:255;not null" json:"name"`
+	Size   int64  `gorm:"not null" json:"size"`
+	Offset int64  `gorm:"not null;default:0" json:"offset"`
+	// Ожидаемый SHA-256 всего файла, если клиент передал его при создании загрузки
+	Checksum string `gorm:"size:64" json:"checksum,omitempty"`
+	// SHA-256 полученных данных, вычисляется после приёма последней части
+	SHA256    string    `gorm:"size:64" json:"sha256,omitempty"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+}
+
+// Complete - все данные получены и проверены
+func (s UploadSession) Complete() bool {
+	return s.Offset == s.Size && s.SHA256 != ""
+}
+
+func (s UploadSession) Expired(now time.Time) bool {
+	return now.After(s.ExpiresAt)
+}
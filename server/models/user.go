@@ -17,6 +17,8 @@ type User struct {
 	Messages []ChatMessage `gorm:"foreignKey:UserID" json:"-"`
 
 	Organizations []Organization `gorm:"foreignKey:OwnerID" json:"organizations,omitempty"`
+
+	Technologies []Technology `gorm:"many2many:user_technologies;" json:"technologies,omitempty"`
 }
 
 func GetUserByID(db *gorm.DB, id uint) (User, error) {
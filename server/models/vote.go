@@ -0,0 +1,60 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Режимы зрительского голосования
+const (
+	VotingOff    = 0 // голосование не проводится
+	VotingSingle = 1 // один голос за один проект
+	VotingBudget = 2 // бюджет баллов, распределяемый между проектами
+)
+
+// Vote - голос участника за проект чужой команды
+type Vote struct {
+	gorm.Model
+
+	HackathonID uint `gorm:"not null;index" json:"hackathon_id"`
+	UserID      uint `gorm:"not null;uniqueIndex:idx_vote" json:"user_id"`
+	User        User `gorm:"foreignKey:UserID" json:"-"`
+	TeamID      uint `gorm:"not null;uniqueIndex:idx_vote" json:"team_id"`
+	Team        Team `gorm:"foreignKey:TeamID" json:"-"`
+	Points      int  `gorm:"not null;default:1" json:"points"`
+}
+
+// VotingOpen - голосование включено и сейчас идёт
+func (h *Hackathon) VotingOpen(now time.Time) bool {
+	if h.VotingMode == VotingOff || h.VotingFrom == nil || h.VotingTo == nil {
+		return false
+	}
+	return !now.Before(*h.VotingFrom) && now.Before(*h.VotingTo)
+}
+
+// VotingClosed - голосование закончилось, и его итоги можно показывать
+func (h *Hackathon) VotingClosed(now time.Time) bool {
+	if h.VotingMode == VotingOff || h.VotingTo == nil {
+		return false
+	}
+	return !now.Before(*h.VotingTo)
+}
+
+// ValidateVoting проверяет настройки голосования
+func (h *Hackathon) ValidateVoting() error {
+	if h.VotingMode == VotingOff {
+		return nil
+	}
+	if h.VotingFrom == nil || h.VotingTo == nil || !h.VotingFrom.Before(*h.VotingTo) {
+		return errors.New("для голосования нужно указать период, и его начало должно быть раньше окончания")
+	}
+	if h.VotingMode == VotingBudget && h.VoteBudget < 1 {
+		return errors.New("бюджет баллов для голосования должен быть положительным")
+	}
+	if h.VotingWeight < 0 {
+		return errors.New("вес голосования не может быть отрицательным")
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+// Package pdf формирует одностраничные PDF-документы: фоновое изображение и строки текста
+// встроенным шрифтом TrueType. Этого достаточно для сертификатов и подобных бланков
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+// Выравнивание строки относительно точки привязки
+const (
+	AlignLeft   = "left"
+	AlignCenter = "center"
+	AlignRight  = "right"
+)
+
+// Размеры страницы A4 в пунктах
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+// Document - одна страница документа. Координаты в пунктах от левого нижнего угла
+type Document struct {
+	Width, Height float64
+
+	font       *Font
+	background *Image
+	content    bytes.Buffer
+	used       map[uint16]rune
+}
+
+func New(width, height float64) *Document {
+	return &Document{Width: width, Height: height, used: make(map[uint16]rune)}
+}
+
+// SetFont задаёт шрифт всех строк документа
+func (d *Document) SetFont(font *Font) {
+	d.font = font
+}
+
+// SetBackground растягивает изображение на всю страницу
+func (d *Document) SetBackground(img *Image) {
+	d.background = img
+}
+
+// Text выводит строку кеглем size цветом rgb (компоненты 0..1).
+// y - положение базовой линии, align задаёт, к какому краю строки относится x
+func (d *Document) Text(x, y, size float64, rgb [3]float64, align, text string) error {
+	if d.font == nil {
+		return fmt.Errorf("шрифт документа не задан")
+	}
+
+	switch align {
+	case AlignCenter:
+		x -= d.font.Width(text, size) / 2
+	case AlignRight:
+		x -= d.font.Width(text, size)
+	}
+
+	var glyphs strings.Builder
+	for _, r := range text {
+		glyph := d.font.glyph(r)
+		if _, ok := d.used[glyph]; !ok {
+			d.used[glyph] = r
+		}
+		fmt.Fprintf(&glyphs, "%04X", glyph)
+	}
+
+	fmt.Fprintf(&d.content, "BT /F1 %s Tf %s %s %s rg %s %s Td <%s> Tj ET\n",
+		number(size), number(rgb[0]), number(rgb[1]), number(rgb[2]), number(x), number(y), glyphs.String())
+	return nil
+}
+
+// WriteTo записывает документ в формате PDF 1.4
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if d.font == nil {
+		return 0, fmt.Errorf("шрифт документа не задан")
+	}
+
+	out := &pdfWriter{}
+	out.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	const (
+		catalogID = iota + 1
+		pagesID
+		pageID
+		contentID
+		fontID
+		cidFontID
+		descriptorID
+		fontFileID
+		toUnicodeID
+		imageID
+	)
+
+	var page bytes.Buffer
+	if d.background != nil {
+		fmt.Fprintf(&page, "q %s 0 0 %s 0 0 cm /Im1 Do Q\n", number(d.Width), number(d.Height))
+	}
+	page.Write(d.content.Bytes())
+
+	resources := fmt.Sprintf("/Font << /F1 %d 0 R >>", fontID)
+	if d.background != nil {
+		resources += fmt.Sprintf(" /XObject << /Im1 %d 0 R >>", imageID)
+	}
+
+	out.object(catalogID, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesID))
+	out.object(pagesID, fmt.Sprintf("<< /Type /Pages /Kids [%d 0 R] /Count 1 >>", pageID))
+	out.object(pageID, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << %s >> /Contents %d 0 R >>",
+		pagesID, number(d.Width), number(d.Height), resources, contentID))
+	out.stream(contentID, "", compress(page.Bytes()), "FlateDecode")
+
+	font := d.font
+	out.object(fontID, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /Embedded /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		cidFontID, toUnicodeID))
+	out.object(cidFontID, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /Embedded /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /CIDToGIDMap /Identity /W [%s] >>",
+		descriptorID, d.widths()))
+	out.object(descriptorID, fmt.Sprintf("<< /Type /FontDescriptor /FontName /Embedded /Flags 32 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		font.scale(font.bbox[0]), font.scale(font.bbox[1]), font.scale(font.bbox[2]), font.scale(font.bbox[3]),
+		font.scale(font.ascent), font.scale(font.descent), font.scale(font.ascent), fontFileID))
+	out.stream(fontFileID, fmt.Sprintf("/Length1 %d", len(font.data)), compress(font.data), "FlateDecode")
+	out.stream(toUnicodeID, "", compress(d.toUnicode()), "FlateDecode")
+
+	if img := d.background; img != nil {
+		out.stream(imageID, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /%s /BitsPerComponent 8",
+			img.Width, img.Height, img.colorSpace), img.data, img.filter)
+	}
+
+	out.finish(catalogID)
+	return io.Copy(w, &out.buf)
+}
+
+// sortedGlyphs возвращает использованные глифы по возрастанию номера
+func (d *Document) sortedGlyphs() []uint16 {
+	glyphs := make([]uint16, 0, len(d.used))
+	for glyph := range d.used {
+		glyphs = append(glyphs, glyph)
+	}
+	sort.Slice(glyphs, func(i, j int) bool { return glyphs[i] < glyphs[j] })
+	return glyphs
+}
+
+// widths формирует массив ширин /W для использованных глифов
+func (d *Document) widths() string {
+	var w strings.Builder
+	for _, glyph := range d.sortedGlyphs() {
+		fmt.Fprintf(&w, "%d [%d] ", glyph, d.font.scale(d.font.advance(glyph)))
+	}
+	return strings.TrimSpace(w.String())
+}
+
+// toUnicode формирует CMap, по которому программы просмотра копируют и ищут текст
+func (d *Document) toUnicode() []byte {
+	var cmap bytes.Buffer
+	cmap.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+
+	glyphs := d.sortedGlyphs()
+	for start := 0; start < len(glyphs); start += 100 {
+		end := min(start+100, len(glyphs))
+		fmt.Fprintf(&cmap, "%d beginbfchar\n", end-start)
+		for _, glyph := range glyphs[start:end] {
+			fmt.Fprintf(&cmap, "<%04X> <", glyph)
+			for _, unit := range utf16.Encode([]rune{d.used[glyph]}) {
+				fmt.Fprintf(&cmap, "%04X", unit)
+			}
+			cmap.WriteString(">\n")
+		}
+		cmap.WriteString("endbfchar\n")
+	}
+
+	cmap.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return cmap.Bytes()
+}
+
+// pdfWriter собирает объекты документа и таблицу их смещений
+type pdfWriter struct {
+	buf     bytes.Buffer
+	offsets map[int]int
+}
+
+func (w *pdfWriter) WriteString(s string) {
+	w.buf.WriteString(s)
+}
+
+func (w *pdfWriter) object(id int, body string) {
+	w.begin(id)
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", id, body)
+}
+
+func (w *pdfWriter) begin(id int) {
+	if w.offsets == nil {
+		w.offsets = make(map[int]int)
+	}
+	w.offsets[id] = w.buf.Len()
+}
+
+// stream записывает поток, данные которого уже закодированы фильтром filter
+func (w *pdfWriter) stream(id int, dict string, data []byte, filter string) {
+	w.begin(id)
+	if filter != "" {
+		dict += " /Filter /" + filter
+	}
+	fmt.Fprintf(&w.buf, "%d 0 obj\n<< %s /Length %d >>\nstream\n", id, strings.TrimSpace(dict), len(data))
+	w.buf.Write(data)
+	w.buf.WriteString("\nendstream\nendobj\n")
+}
+
+func (w *pdfWriter) finish(rootID int) {
+	xref := w.buf.Len()
+	count := len(w.offsets) + 1
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", count)
+	for id := 1; id < count; id++ {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", w.offsets[id])
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", count, rootID, xref)
+}
+
+func compress(data []byte) []byte {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+	return buf.Bytes()
+}
+
+// number записывает число без лишних нулей, как принято в PDF
+func number(v float64) string {
+	s := fmt.Sprintf("%.3f", v)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
@@ -0,0 +1,74 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+)
+
+var ErrImageFormat = errors.New("поддерживаются изображения JPEG, PNG и GIF")
+
+// Image - растровое изображение, подготовленное к встраиванию в PDF
+type Image struct {
+	Width, Height int
+
+	data       []byte
+	filter     string
+	colorSpace string
+}
+
+// LoadImage готовит изображение. JPEG встраивается без перекодирования,
+// остальные форматы переводятся в RGB на белом фоне и сжимаются
+func LoadImage(data []byte) (*Image, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrImageFormat
+	}
+
+	if format == "jpeg" {
+		colorSpace := "DeviceRGB"
+		switch config.ColorModel {
+		case color.GrayModel:
+			colorSpace = "DeviceGray"
+		case color.CMYKModel:
+			colorSpace = "DeviceCMYK"
+		}
+		// Проверяем, что файл читается целиком, а не только заголовок
+		if _, err := jpeg.Decode(bytes.NewReader(data)); err != nil {
+			return nil, ErrImageFormat
+		}
+		return &Image{Width: config.Width, Height: config.Height, data: data, filter: "DCTDecode", colorSpace: colorSpace}, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrImageFormat
+	}
+	bounds := img.Bounds()
+
+	var raw bytes.Buffer
+	zw := zlib.NewWriter(&raw)
+	row := make([]byte, 0, bounds.Dx()*3)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		row = row[:0]
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			// Прозрачные пиксели смешиваются с белым фоном
+			white := 0xFFFF - a
+			row = append(row, byte((r+white)>>8), byte((g+white)>>8), byte((b+white)>>8))
+		}
+		if _, err := zw.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return &Image{Width: bounds.Dx(), Height: bounds.Dy(), data: raw.Bytes(), filter: "FlateDecode", colorSpace: "DeviceRGB"}, nil
+}
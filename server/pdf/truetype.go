@@ -0,0 +1,198 @@
+package pdf
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var (
+	ErrFontFormat      = errors.New("поддерживаются только шрифты TrueType (.ttf)")
+	ErrFontIncomplete  = errors.New("в шрифте нет обязательных таблиц")
+	ErrFontNoCharacter = errors.New("в шрифте нет таблицы символов Unicode")
+)
+
+// Font - шрифт TrueType, встраиваемый в документ целиком.
+// Из файла читаются только таблицы, нужные для поиска глифов и ширины текста
+type Font struct {
+	data []byte
+
+	unitsPerEm  int
+	bbox        [4]int
+	ascent      int
+	descent     int
+	numHMetrics int
+	hmtx        []byte
+
+	cmap       []byte
+	cmapFormat int
+}
+
+// LoadTrueType разбирает файл шрифта TrueType
+func LoadTrueType(data []byte) (*Font, error) {
+	if len(data) < 12 {
+		return nil, ErrFontFormat
+	}
+	switch binary.BigEndian.Uint32(data) {
+	case 0x00010000, 0x74727565: // 1.0 и 'true'
+	default:
+		return nil, ErrFontFormat
+	}
+
+	tables := make(map[string][]byte)
+	numTables := be16(data, 4)
+	for i := 0; i < numTables; i++ {
+		record := 12 + 16*i
+		if record+16 > len(data) {
+			return nil, ErrFontFormat
+		}
+		offset := int(binary.BigEndian.Uint32(data[record+8:]))
+		length := int(binary.BigEndian.Uint32(data[record+12:]))
+		if offset < 0 || length < 0 || offset+length > len(data) {
+			return nil, ErrFontFormat
+		}
+		tables[string(data[record:record+4])] = data[offset : offset+length]
+	}
+
+	head, hhea, hmtx, cmap := tables["head"], tables["hhea"], tables["hmtx"], tables["cmap"]
+	if len(head) < 54 || len(hhea) < 36 || hmtx == nil || cmap == nil {
+		return nil, ErrFontIncomplete
+	}
+
+	font := &Font{
+		data:        data,
+		unitsPerEm:  be16(head, 18),
+		ascent:      int(int16(be16(hhea, 4))),
+		descent:     int(int16(be16(hhea, 6))),
+		numHMetrics: be16(hhea, 34),
+		hmtx:        hmtx,
+	}
+	for i := range font.bbox {
+		font.bbox[i] = int(int16(be16(head, 36+2*i)))
+	}
+	if font.unitsPerEm == 0 || font.numHMetrics == 0 {
+		return nil, ErrFontIncomplete
+	}
+
+	if err := font.selectCmap(cmap); err != nil {
+		return nil, err
+	}
+	return font, nil
+}
+
+// selectCmap выбирает таблицу символов Unicode: полную (формат 12), иначе базовую (формат 4)
+func (f *Font) selectCmap(cmap []byte) error {
+	var basic []byte
+	numTables := be16(cmap, 2)
+	for i := 0; i < numTables; i++ {
+		record := 4 + 8*i
+		platform, encoding := be16(cmap, record), be16(cmap, record+2)
+		offset := int(be32(cmap, record+4))
+		if offset <= 0 || offset >= len(cmap) {
+			continue
+		}
+		sub := cmap[offset:]
+		unicode := platform == 0 || (platform == 3 && (encoding == 1 || encoding == 10))
+		if !unicode {
+			continue
+		}
+		switch be16(sub, 0) {
+		case 12:
+			f.cmap, f.cmapFormat = sub, 12
+			return nil
+		case 4:
+			if basic == nil {
+				basic = sub
+			}
+		}
+	}
+	if basic == nil {
+		return ErrFontNoCharacter
+	}
+	f.cmap, f.cmapFormat = basic, 4
+	return nil
+}
+
+// glyph возвращает номер глифа символа r; 0 - глиф отсутствующего символа
+func (f *Font) glyph(r rune) uint16 {
+	if f.cmapFormat == 12 {
+		groups := int(be32(f.cmap, 12))
+		for i := 0; i < groups; i++ {
+			group := 16 + 12*i
+			start, end := rune(be32(f.cmap, group)), rune(be32(f.cmap, group+4))
+			if r >= start && r <= end {
+				return uint16(be32(f.cmap, group+8) + uint32(r-start))
+			}
+		}
+		return 0
+	}
+
+	if r > 0xFFFF {
+		return 0
+	}
+	c := int(r)
+	segments := be16(f.cmap, 6) / 2
+	endCodes := 14
+	startCodes := endCodes + 2*segments + 2
+	deltas := startCodes + 2*segments
+	rangeOffsets := deltas + 2*segments
+	for i := 0; i < segments; i++ {
+		end := be16(f.cmap, endCodes+2*i)
+		if c > end {
+			continue
+		}
+		start := be16(f.cmap, startCodes+2*i)
+		if c < start {
+			return 0
+		}
+		delta := be16(f.cmap, deltas+2*i)
+		rangeOffset := be16(f.cmap, rangeOffsets+2*i)
+		if rangeOffset == 0 {
+			return uint16(c + delta)
+		}
+		glyph := be16(f.cmap, rangeOffsets+2*i+rangeOffset+2*(c-start))
+		if glyph == 0 {
+			return 0
+		}
+		return uint16(glyph + delta)
+	}
+	return 0
+}
+
+// advance возвращает ширину глифа в единицах шрифта
+func (f *Font) advance(glyph uint16) int {
+	index := int(glyph)
+	if index >= f.numHMetrics {
+		index = f.numHMetrics - 1
+	}
+	return be16(f.hmtx, 4*index)
+}
+
+// scale переводит единицы шрифта в тысячные доли кегля, принятые в PDF
+func (f *Font) scale(units int) int {
+	return units * 1000 / f.unitsPerEm
+}
+
+// Width возвращает ширину строки s при кегле size в пунктах
+func (f *Font) Width(s string, size float64) float64 {
+	total := 0
+	for _, r := range s {
+		total += f.advance(f.glyph(r))
+	}
+	return float64(total) * size / float64(f.unitsPerEm)
+}
+
+// be16 и be32 читают числа big-endian; за пределами данных возвращают 0,
+// чтобы повреждённый шрифт не приводил к панике
+func be16(b []byte, offset int) int {
+	if offset < 0 || offset+2 > len(b) {
+		return 0
+	}
+	return int(binary.BigEndian.Uint16(b[offset:]))
+}
+
+func be32(b []byte, offset int) uint32 {
+	if offset < 0 || offset+4 > len(b) {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b[offset:])
+}
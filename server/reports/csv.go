@@ -0,0 +1,47 @@
+package reports
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// utf8BOM нужен Excel, чтобы открыть CSV с кириллицей без выбора кодировки
+const utf8BOM = "\ufeff"
+
+type csvWriter struct {
+	w       io.Writer
+	csv     *csv.Writer
+	started bool
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: w, csv: csv.NewWriter(w)}
+}
+
+func (w *csvWriter) Sheet(_ string, header []string) error {
+	if w.started {
+		return ErrSingleSheet
+	}
+	w.started = true
+	if _, err := io.WriteString(w.w, utf8BOM); err != nil {
+		return err
+	}
+	safe := make([]string, len(header))
+	for i, name := range header {
+		safe[i] = SafeText(name)
+	}
+	return w.csv.Write(safe)
+}
+
+func (w *csvWriter) Row(values ...any) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = formatValue(value)
+	}
+	return w.csv.Write(record)
+}
+
+func (w *csvWriter) Close() error {
+	w.csv.Flush()
+	return w.csv.Error()
+}
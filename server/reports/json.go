@@ -0,0 +1,100 @@
+package reports
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// jsonWriter пишет объект, где каждой таблице соответствует массив строк-объектов
+type jsonWriter struct {
+	w      *bufio.Writer
+	header []string
+	sheets int
+	rows   int
+}
+
+func newJSONWriter(w io.Writer) *jsonWriter {
+	return &jsonWriter{w: bufio.NewWriter(w)}
+}
+
+func (w *jsonWriter) Sheet(name string, header []string) error {
+	prefix := "{"
+	if w.sheets > 0 {
+		prefix = "],"
+	}
+	w.sheets++
+	w.rows = 0
+	w.header = header
+
+	key, err := json.Marshal(name)
+	if err != nil {
+		return err
+	}
+	if _, err := w.w.WriteString(prefix); err != nil {
+		return err
+	}
+	if _, err := w.w.Write(key); err != nil {
+		return err
+	}
+	_, err = w.w.WriteString(":[")
+	return err
+}
+
+func (w *jsonWriter) Row(values ...any) error {
+	if w.rows > 0 {
+		if err := w.w.WriteByte(','); err != nil {
+			return err
+		}
+	}
+	w.rows++
+
+	// Поля пишутся вручную, чтобы сохранить порядок колонок
+	if err := w.w.WriteByte('{'); err != nil {
+		return err
+	}
+	for i, value := range values {
+		if i >= len(w.header) {
+			break
+		}
+		if i > 0 {
+			if err := w.w.WriteByte(','); err != nil {
+				return err
+			}
+		}
+		key, err := json.Marshal(w.header[i])
+		if err != nil {
+			return err
+		}
+		if t, ok := value.(*time.Time); ok && t == nil {
+			value = nil
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if _, err := w.w.Write(key); err != nil {
+			return err
+		}
+		if err := w.w.WriteByte(':'); err != nil {
+			return err
+		}
+		if _, err := w.w.Write(data); err != nil {
+			return err
+		}
+	}
+	// Заполненный буфер сразу уходит клиенту, отчёт целиком в памяти не держится
+	return w.w.WriteByte('}')
+}
+
+func (w *jsonWriter) Close() error {
+	closing := "]}"
+	if w.sheets == 0 {
+		closing = "{}"
+	}
+	if _, err := w.w.WriteString(closing); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
@@ -0,0 +1,103 @@
+// Package reports записывает табличные отчёты в CSV, XLSX и JSON.
+// Строки пишутся сразу в выходной поток, поэтому большие отчёты не собираются в памяти
+package reports
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Форматы отчётов
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+	FormatJSON = "json"
+)
+
+var ErrUnknownFormat = errors.New("неизвестный формат отчёта")
+
+// ErrSingleSheet - формат не поддерживает несколько таблиц в одном файле
+var ErrSingleSheet = errors.New("формат поддерживает только одну таблицу")
+
+// Writer записывает таблицы отчёта. Строки относятся к последней начатой таблице
+type Writer interface {
+	// Sheet начинает новую таблицу с названием name и заголовками колонок header
+	Sheet(name string, header []string) error
+	// Row записывает строку текущей таблицы. Поддерживаются строки, числа, bool, время и nil
+	Row(values ...any) error
+	// Close дописывает служебные части файла; поток w не закрывается
+	Close() error
+}
+
+// NewWriter создаёт запись отчёта в формате format
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatXLSX:
+		return newXLSXWriter(w), nil
+	case FormatJSON:
+		return newJSONWriter(w), nil
+	}
+	return nil, ErrUnknownFormat
+}
+
+// MultiSheet проверяет, можно ли записать в формате несколько таблиц
+func MultiSheet(format string) bool {
+	return format != FormatCSV
+}
+
+// ContentType возвращает MIME-тип файла отчёта
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "application/json; charset=utf-8"
+	}
+}
+
+// formatValue переводит значение ячейки в строку для текстовых форматов
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return SafeText(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// SafeText защищает ячейку от выполнения как формулы: названия команд, имена и комментарии вводят
+// пользователи, а таблица открывается в Excel или LibreOffice. Опасное начало экранируется апострофом
+func SafeText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// numeric проверяет, что значение записывается в таблицу как число
+func numeric(value any) bool {
+	switch value.(type) {
+	case int, int64, uint, uint64, float64:
+		return true
+	}
+	return false
+}
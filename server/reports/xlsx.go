@@ -0,0 +1,206 @@
+package reports
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// xlsxWriter пишет минимальную книгу Office Open XML. Листы записываются в архив по мере
+// поступления строк, а книга и описание частей - при закрытии, когда известны все листы
+type xlsxWriter struct {
+	zip    *zip.Writer
+	sheet  *bufio.Writer
+	names  []string
+	rowNum int
+	err    error
+}
+
+const (
+	xlsxHeader    = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+	xlsxNamespace = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+	xlsxRelations = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+)
+
+// Символы, запрещённые в названии листа Excel
+var sheetNameReplacer = strings.NewReplacer("[", "(", "]", ")", ":", " ", "*", " ", "?", " ", "/", "-", "\\", "-")
+
+func newXLSXWriter(w io.Writer) *xlsxWriter {
+	return &xlsxWriter{zip: zip.NewWriter(w)}
+}
+
+func (w *xlsxWriter) Sheet(name string, header []string) error {
+	if err := w.endSheet(); err != nil {
+		return err
+	}
+
+	part, err := w.zip.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", len(w.names)+1))
+	if err != nil {
+		return err
+	}
+	w.names = append(w.names, w.sheetName(name))
+	w.sheet = bufio.NewWriter(part)
+	w.rowNum = 0
+	w.write(xlsxHeader + `<worksheet xmlns="` + xlsxNamespace + `"><sheetData>`)
+
+	values := make([]any, len(header))
+	for i, title := range header {
+		values[i] = title
+	}
+	return w.Row(values...)
+}
+
+func (w *xlsxWriter) Row(values ...any) error {
+	if w.sheet == nil {
+		return fmt.Errorf("строка отчёта записана до начала таблицы")
+	}
+
+	w.rowNum++
+	row := strconv.Itoa(w.rowNum)
+	w.write(`<row r="` + row + `">`)
+	for i, value := range values {
+		ref := columnName(i) + row
+		switch {
+		case value == nil:
+			continue
+		case numeric(value):
+			w.write(`<c r="` + ref + `"><v>` + formatValue(value) + `</v></c>`)
+		case isBool(value):
+			flag := "0"
+			if value.(bool) {
+				flag = "1"
+			}
+			w.write(`<c r="` + ref + `" t="b"><v>` + flag + `</v></c>`)
+		default:
+			text := formatValue(value)
+			if text == "" {
+				continue
+			}
+			w.write(`<c r="` + ref + `" t="inlineStr"><is><t xml:space="preserve">`)
+			w.escape(text)
+			w.write(`</t></is></c>`)
+		}
+	}
+	w.write(`</row>`)
+	return w.err
+}
+
+func (w *xlsxWriter) Close() error {
+	// Книга без листов не открывается, поэтому пустой отчёт получает пустой лист
+	if len(w.names) == 0 {
+		if err := w.Sheet("Отчёт", nil); err != nil {
+			return err
+		}
+	}
+	if err := w.endSheet(); err != nil {
+		return err
+	}
+
+	var workbook, relations, overrides strings.Builder
+	for i, name := range w.names {
+		id := strconv.Itoa(i + 1)
+		workbook.WriteString(`<sheet name="`)
+		xml.EscapeText(&workbook, []byte(name))
+		workbook.WriteString(`" sheetId="` + id + `" r:id="rId` + id + `"/>`)
+		relations.WriteString(`<Relationship Id="rId` + id + `" Type="` + xlsxRelations + `/worksheet" Target="worksheets/sheet` + id + `.xml"/>`)
+		overrides.WriteString(`<Override PartName="/xl/worksheets/sheet` + id + `.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`)
+	}
+
+	parts := []struct{ name, content string }{
+		{"xl/workbook.xml", `<workbook xmlns="` + xlsxNamespace + `" xmlns:r="` + xlsxRelations + `"><sheets>` + workbook.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` + relations.String() + `</Relationships>`},
+		{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="` + xlsxRelations + `/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+		{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			overrides.String() + `</Types>`},
+	}
+	for _, part := range parts {
+		file, err := w.zip.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(file, xlsxHeader+part.content); err != nil {
+			return err
+		}
+	}
+	return w.zip.Close()
+}
+
+// endSheet закрывает текущий лист; следующий лист начинается новой частью архива
+func (w *xlsxWriter) endSheet() error {
+	if w.sheet == nil {
+		return w.err
+	}
+	w.write(`</sheetData></worksheet>`)
+	if w.err == nil {
+		w.err = w.sheet.Flush()
+	}
+	w.sheet = nil
+	return w.err
+}
+
+func (w *xlsxWriter) write(s string) {
+	if w.err == nil {
+		_, w.err = w.sheet.WriteString(s)
+	}
+}
+
+func (w *xlsxWriter) escape(s string) {
+	if w.err == nil {
+		w.err = xml.EscapeText(w.sheet, []byte(s))
+	}
+}
+
+// sheetName приводит название к ограничениям Excel: до 31 символа, без служебных символов,
+// уникальное в пределах книги
+func (w *xlsxWriter) sheetName(name string) string {
+	name = strings.TrimSpace(sheetNameReplacer.Replace(name))
+	if name == "" {
+		name = "Лист"
+	}
+
+	candidate := truncateRunes(name, 31)
+	for n := 2; w.hasSheet(candidate); n++ {
+		suffix := " " + strconv.Itoa(n)
+		candidate = truncateRunes(name, 31-len(suffix)) + suffix
+	}
+	return candidate
+}
+
+func (w *xlsxWriter) hasSheet(name string) bool {
+	for _, existing := range w.names {
+		if strings.EqualFold(existing, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func truncateRunes(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	return string([]rune(s)[:limit])
+}
+
+// columnName переводит номер колонки с нуля в буквенное обозначение: 0 - A, 26 - AA
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+func isBool(value any) bool {
+	_, ok := value.(bool)
+	return ok
+}
@@ -0,0 +1,30 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"server/controllers"
+	"server/middlewares"
+	"server/models"
+)
+
+func ApplicationRouter(router *gin.Engine, db *gorm.DB) {
+	applicationController := controllers.NewApplicationController(db, controllers.NewFileController(db))
+
+	protected := router.Group("/hackathon")
+	protected.Use(middlewares.Auth())
+	{
+		protected.GET("/:hackathon_id/form", applicationController.GetForm)
+		protected.GET("/:hackathon_id/application", applicationController.GetMy)
+		protected.POST("/join/:hackathon_id", middlewares.HackathonPhase(db, models.HackathonPhaseRegistration), applicationController.Submit)
+	}
+
+	protected = router.Group("/hackathon")
+	protected.Use(middlewares.Auth(), middlewares.HackathonRoleGreater(db, 3))
+	{
+		protected.PUT("/:hackathon_id/form", applicationController.UpdateForm)
+		protected.POST("/:hackathon_id/applications", applicationController.GetAll)
+		protected.POST("/:hackathon_id/applications/review", applicationController.Review)
+		protected.GET("/:hackathon_id/applications/export", applicationController.Export)
+	}
+}
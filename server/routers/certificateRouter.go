@@ -0,0 +1,31 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"server/controllers"
+	"server/middlewares"
+)
+
+func CertificateRouter(router *gin.Engine, db *gorm.DB) {
+	certificateController := controllers.NewCertificateController(db, controllers.NewFileController(db))
+
+	// Проверка подлинности доступна без авторизации по коду с сертификата
+	router.GET("/certificate/verify/:code", certificateController.VerifyCertificate)
+
+	protected := router.Group("/hackathon")
+	protected.Use(middlewares.Auth(), middlewares.HackathonParticipant(db))
+	{
+		protected.GET("/:hackathon_id/certificates/my", certificateController.GetMyCertificates)
+		protected.GET("/:hackathon_id/certificates/:code/pdf", certificateController.GetCertificatePDF)
+	}
+
+	protected = router.Group("/hackathon")
+	protected.Use(middlewares.Auth(), middlewares.HackathonRoleGreater(db, 3))
+	{
+		protected.GET("/:hackathon_id/certificates/template", certificateController.GetTemplate)
+		protected.PUT("/:hackathon_id/certificates/template", certificateController.UpdateTemplate)
+		protected.GET("/:hackathon_id/certificates", certificateController.GetCertificates)
+		protected.POST("/:hackathon_id/certificates/issue", certificateController.IssueCertificates)
+	}
+}
@@ -10,9 +10,13 @@ import (
 func FileRouter(router *gin.Engine, db *gorm.DB) {
 	fileController := controllers.NewFileController(db)
 
+	// Подписанные ссылки локального хранилища открываются без авторизации
+	router.GET("/storage/*key", fileController.GetStorageObject)
+
 	protected := router.Group("/file")
 	protected.Use(middlewares.Auth())
 	{
 		protected.GET("/:file_id", fileController.GetFile)
+		protected.GET("/:file_id/url", fileController.GetFileURL)
 	}
 }
@@ -0,0 +1,37 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"server/controllers"
+	"server/middlewares"
+)
+
+func GalleryRouter(router *gin.Engine, db *gorm.DB) {
+	galleryController := controllers.NewGalleryController(db, controllers.NewFileController(db))
+
+	// Галерея открыта без авторизации
+	public := router.Group("/gallery")
+	{
+		public.GET("/:hackathon_id", galleryController.GetGallery)
+		public.GET("/:hackathon_id/teams/:team_id", galleryController.GetGalleryEntry)
+		public.GET("/:hackathon_id/teams/:team_id/download", galleryController.DownloadGalleryProject)
+		public.GET("/:hackathon_id/teams/:team_id/screenshots/:file_id", galleryController.GetGalleryScreenshot)
+	}
+
+	protected := router.Group("/hackathon")
+	protected.Use(middlewares.Auth(), middlewares.HackathonParticipant(db))
+	{
+		protected.GET("/:hackathon_id/gallery/team", galleryController.GetTeamGalleryEntry)
+		protected.PUT("/:hackathon_id/gallery/team", galleryController.UpdateTeamGalleryEntry)
+		protected.PUT("/:hackathon_id/gallery/consent", galleryController.SetGalleryConsent)
+	}
+
+	protected = router.Group("/hackathon")
+	protected.Use(middlewares.Auth(), middlewares.HackathonRoleGreater(db, 3))
+	{
+		protected.PUT("/:hackathon_id/gallery", galleryController.SetGallerySettings)
+		protected.GET("/:hackathon_id/gallery/entries", galleryController.GetGalleryEntries)
+		protected.PUT("/:hackathon_id/gallery/entries/:team_id/hide", galleryController.HideGalleryEntry)
+	}
+}
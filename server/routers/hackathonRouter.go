@@ -5,6 +5,7 @@ import (
 	"gorm.io/gorm"
 	"server/controllers"
 	"server/middlewares"
+	"server/models"
 )
 
 func HackathonRouter(router *gin.Engine, db *gorm.DB) {
@@ -25,10 +26,12 @@ func HackathonRouter(router *gin.Engine, db *gorm.DB) {
 		protected.GET("/:hackathon_id", hackathonController.GetByIDFull)
 		protected.GET("/team/accept/:invite_id", hackathonController.AcceptTeamInvite)
 		protected.GET("/team/reject/:invite_id", hackathonController.RejectTeamInvite)
-		protected.GET("/team/leave/:hackathon_id", hackathonController.LeaveTeam)
-		protected.GET("/team/kick/:hackathon_id/:user_id", hackathonController.KickTeam)
+		protected.GET("/team/leave/:hackathon_id", middlewares.HackathonPhase(db, models.HackathonPhaseRegistration, models.HackathonPhaseWork), hackathonController.LeaveTeam)
+		protected.GET("/team/kick/:hackathon_id/:user_id", middlewares.HackathonPhase(db, models.HackathonPhaseRegistration, models.HackathonPhaseWork), hackathonController.KickTeam)
 		protected.GET("/:hackathon_id/project", hackathonController.GetTeamProject)
 		protected.POST("/:hackathon_id/project", hackathonController.UploadTeamProject)
+		protected.GET("/:hackathon_id/submissions", hackathonController.GetSubmissions)
+		protected.POST("/:hackathon_id/submissions", hackathonController.CreateSubmission)
 	}
 
 	protected = router.Group("hackathon/mentor/invite")
@@ -43,12 +46,51 @@ func HackathonRouter(router *gin.Engine, db *gorm.DB) {
 	protected.Use(middlewares.Auth(), middlewares.HackathonRoleGreater(db, 3))
 	{
 		protected.GET("/:hackathon_id/edit", hackathonController.GetByIDEditFull)
+		protected.POST("/:hackathon_id/results/preview", hackathonController.PreviewResults)
+		protected.PUT("/:hackathon_id/phase", hackathonController.ExtendPhase)
+		protected.DELETE("/:hackathon_id/phase", hackathonController.ClosePhase)
+		protected.POST("/:hackathon_id/submit", hackathonController.SubmitForReview)
+		protected.POST("/:hackathon_id/cancel", hackathonController.CancelHackathon)
+		protected.GET("/:hackathon_id/judges/assignments", hackathonController.GetJudgeAssignments)
+		protected.PUT("/:hackathon_id/judges/assignments/:team_id", hackathonController.SetTeamJudges)
+		protected.POST("/:hackathon_id/judges/assignments/auto", hackathonController.AutoAssignJudges)
+		protected.POST("/:hackathon_id/judges/conflicts", hackathonController.CreateJudgeConflict)
+		protected.DELETE("/:hackathon_id/judges/conflicts/:conflict_id", hackathonController.DeleteJudgeConflict)
+		protected.POST("/:hackathon_id/blind/reveal", hackathonController.RevealBlindReview)
+		protected.GET("/:hackathon_id/results/tiebreakers", hackathonController.GetTieSettings)
+		protected.PUT("/:hackathon_id/results/tiebreakers", hackathonController.SetTieBreakers)
+		protected.PUT("/:hackathon_id/results/ties", hackathonController.SetTieDecisions)
+		protected.POST("/:hackathon_id/results/publish", middlewares.HackathonPhase(db, models.HackathonPhaseResults, models.HackathonPhaseArchived), hackathonController.PublishResults)
+		protected.DELETE("/:hackathon_id/results/publish", hackathonController.UnpublishResults)
+		protected.POST("/:hackathon_id/rounds/:step_id/close", hackathonController.CloseRound)
+		protected.DELETE("/:hackathon_id/rounds/:step_id/close", hackathonController.ReopenRound)
+		protected.GET("/:hackathon_id/export/:report", hackathonController.ExportReport)
+		protected.POST("/:hackathon_id/tracks", hackathonController.CreateAwardTrack)
+		protected.PUT("/:hackathon_id/tracks/:track_id", hackathonController.UpdateAwardTrack)
+		protected.DELETE("/:hackathon_id/tracks/:track_id", hackathonController.DeleteAwardTrack)
+		protected.PUT("/:hackathon_id/tracks/:track_id/picks", hackathonController.SetTrackPicks)
+	}
+
+	protected = router.Group("/hackathon")
+	protected.Use(middlewares.Auth(), middlewares.HackathonRoleGreater(db, 2))
+	{
+		protected.GET("/:hackathon_id/team/:team_id/submissions", hackathonController.GetTeamSubmissions)
+		protected.GET("/:hackathon_id/blind/:code/files/:file_id", hackathonController.DownloadBlindFile)
+	}
+
+	protected = router.Group("/hackathon/review")
+	protected.Use(middlewares.Auth(), middlewares.SystemRole(2))
+	{
+		protected.POST("/list", hackathonController.GetReviewQueue)
+		protected.POST("/:hackathon_id/approve", hackathonController.ApproveHackathon)
+		protected.POST("/:hackathon_id/reject", hackathonController.RejectHackathon)
 	}
 
 	protected = router.Group("/hackathon")
 	protected.Use(middlewares.Auth())
 	{
-		protected.GET("/join/:hackathon_id", hackathonController.AddUser)
+		protected.GET("/join/:hackathon_id", middlewares.HackathonPhase(db, models.HackathonPhaseRegistration), hackathonController.AddUser)
+		protected.DELETE("/join/:hackathon_id", hackathonController.LeaveHackathon)
 		protected.POST("/participants/:hackathon_id", hackathonController.GetParticipants)
 	}
 
@@ -56,18 +98,42 @@ func HackathonRouter(router *gin.Engine, db *gorm.DB) {
 	protected.Use(middlewares.Auth(), middlewares.HackathonParticipant(db))
 	{
 		protected.GET("/team/:hackathon_id", hackathonController.GetTeam)
-		protected.POST("/team/:hackathon_id", hackathonController.CreateTeam)
+		protected.POST("/team/:hackathon_id", middlewares.HackathonPhase(db, models.HackathonPhaseRegistration, models.HackathonPhaseWork), hackathonController.CreateTeam)
 		protected.GET("/:hackathon_id/role", hackathonController.GetHackathonRole)
 	}
 
 	protected = router.Group("/hackathon")
 	protected.Use(middlewares.Auth(), middlewares.HackathonParticipant(db))
 	{
-		protected.GET("/team/invite/:hackathon_id/:user_id", hackathonController.InviteUser)
-		protected.DELETE("/team/:hackathon_id", hackathonController.DeleteTeam)
+		protected.GET("/team/invite/:hackathon_id/:user_id", middlewares.HackathonPhase(db, models.HackathonPhaseRegistration, models.HackathonPhaseWork), hackathonController.InviteUser)
+		protected.DELETE("/team/:hackathon_id", middlewares.HackathonPhase(db, models.HackathonPhaseRegistration, models.HackathonPhaseWork), hackathonController.DeleteTeam)
 		protected.GET("/:hackathon_id/team/invite", hackathonController.GetTeamInvitesForMe)
 		protected.POST("/:hackathon_id/validate/projects", hackathonController.GetValidateProjects)
 		protected.POST("/:hackathon_id/team/:team_id/rating", hackathonController.SubmitProjectRating)
 		protected.GET("/:hackathon_id/results", hackathonController.GetResults)
+		protected.GET("/:hackathon_id/inspections/:file_id", hackathonController.GetArchiveInspection)
+	}
+
+	protected = router.Group("/hackathon")
+	protected.Use(middlewares.Auth(), middlewares.HackathonParticipant(db))
+	{
+		protected.GET("/:hackathon_id/teams", hackathonController.GetTeams)
+		protected.GET("/:hackathon_id/tracks", hackathonController.GetAwardTracks)
+		protected.GET("/:hackathon_id/votes", hackathonController.GetVotes)
+		protected.PUT("/:hackathon_id/votes", hackathonController.SetVotes)
+		protected.POST("/:hackathon_id/tracks/:track_id/entry", middlewares.HackathonPhase(db, models.HackathonPhaseRegistration, models.HackathonPhaseWork), hackathonController.EnterAwardTrack)
+		protected.DELETE("/:hackathon_id/tracks/:track_id/entry", middlewares.HackathonPhase(db, models.HackathonPhaseRegistration, models.HackathonPhaseWork), hackathonController.LeaveAwardTrack)
+		protected.POST("/:hackathon_id/recommendations", hackathonController.GetRecommendations)
+		protected.POST("/:hackathon_id/team/:team_id/request", middlewares.HackathonPhase(db, models.HackathonPhaseRegistration, models.HackathonPhaseWork), hackathonController.CreateJoinRequest)
+		protected.GET("/:hackathon_id/team/requests", hackathonController.GetTeamJoinRequests)
+		protected.GET("/:hackathon_id/team/requests/my", hackathonController.GetMyJoinRequests)
+		protected.POST("/:hackathon_id/team/requests/:request_id/accept", middlewares.HackathonPhase(db, models.HackathonPhaseRegistration, models.HackathonPhaseWork), hackathonController.AcceptJoinRequest)
+		protected.POST("/:hackathon_id/team/requests/:request_id/decline", hackathonController.DeclineJoinRequest)
+		protected.DELETE("/:hackathon_id/team/requests/:request_id", hackathonController.CancelJoinRequest)
+		protected.PUT("/team/:hackathon_id", middlewares.HackathonPhase(db, models.HackathonPhaseRegistration, models.HackathonPhaseWork), hackathonController.UpdateTeam)
+		protected.GET("/:hackathon_id/team/permissions", hackathonController.GetTeamPermissions)
+		protected.POST("/:hackathon_id/team/captain/:user_id", middlewares.HackathonPhase(db, models.HackathonPhaseRegistration, models.HackathonPhaseWork), hackathonController.TransferCaptaincy)
+		protected.POST("/:hackathon_id/team/cocaptain/:user_id", middlewares.HackathonPhase(db, models.HackathonPhaseRegistration, models.HackathonPhaseWork), hackathonController.PromoteCoCaptain)
+		protected.DELETE("/:hackathon_id/team/cocaptain/:user_id", middlewares.HackathonPhase(db, models.HackathonPhaseRegistration, models.HackathonPhaseWork), hackathonController.DemoteCoCaptain)
 	}
 }
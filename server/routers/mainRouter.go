@@ -15,8 +15,11 @@ func Router() *gin.Engine {
 	HackathonRouter(r, initializers.DB)
 	UserRouter(r, initializers.DB)
 	ChatRouter(r, initializers.DB)
-
-	r.Static("/uploads", "./data/uploads")
+	ApplicationRouter(r, initializers.DB)
+	CertificateRouter(r, initializers.DB)
+	GalleryRouter(r, initializers.DB)
+	SimilarityRouter(r, initializers.DB)
+	UploadRouter(r, initializers.DB)
 
 	r.MaxMultipartMemory = 1024 << 20
 	return r
@@ -0,0 +1,20 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"server/controllers"
+	"server/middlewares"
+)
+
+func SimilarityRouter(router *gin.Engine, db *gorm.DB) {
+	similarityController := controllers.NewSimilarityController(db, controllers.NewFileController(db))
+
+	protected := router.Group("/hackathon")
+	protected.Use(middlewares.Auth(), middlewares.HackathonRoleGreater(db, 3))
+	{
+		protected.POST("/:hackathon_id/similarity", similarityController.StartSimilarityCheck)
+		protected.GET("/:hackathon_id/similarity", similarityController.GetSimilarityReport)
+		protected.GET("/:hackathon_id/similarity/pairs/:pair_id", similarityController.GetSimilarityPair)
+	}
+}
@@ -0,0 +1,24 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"server/controllers"
+	"server/middlewares"
+)
+
+func UploadRouter(router *gin.Engine, db *gorm.DB) {
+	uploadController := controllers.NewUploadController(db, controllers.NewFileController(db))
+
+	// Возобновляемые загрузки больших файлов по протоколу tus
+	protected := router.Group("/upload")
+	protected.Use(uploadController.TusHeaders, middlewares.Auth())
+	{
+		protected.OPTIONS("", uploadController.GetUploadOptions)
+		protected.POST("", uploadController.CreateUpload)
+		protected.HEAD("/:upload_id", uploadController.HeadUpload)
+		protected.GET("/:upload_id", uploadController.GetUpload)
+		protected.PATCH("/:upload_id", uploadController.PatchUpload)
+		protected.DELETE("/:upload_id", uploadController.DeleteUpload)
+	}
+}
@@ -14,5 +14,7 @@ func UserRouter(router *gin.Engine, db *gorm.DB) {
 	protected.Use(middlewares.Auth())
 	{
 		protected.POST("/options", userController.GetOptions)
+		protected.GET("/technologies", userController.GetTechnologies)
+		protected.PUT("/technologies", userController.UpdateTechnologies)
 	}
 }
@@ -0,0 +1,192 @@
+package similarity
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// Фрагмент, найденный хотя бы в такой доле проектов, считается шаблонным кодом
+	boilerplateShare = 0.5
+	// Шаблонным кодом фрагмент может считаться, только если он есть хотя бы в стольких проектах:
+	// совпадение двух проектов - это не шаблон, а возможное копирование
+	boilerplateMinDocs = 3
+	// Пары с меньшим числом совпавших отпечатков или меньшей долей совпадения в отчёт не попадают
+	minMatches = 5
+	minScore   = 0.1
+	// Совпадения, разделённые не более чем таким числом строк, объединяются в один фрагмент
+	mergeGap = 3
+	// Сколько фрагментов сохраняется для одной пары
+	maxRegions = 100
+)
+
+// Region - совпадающие фрагменты файлов двух проектов, строки включительно
+type Region struct {
+	FileA  string `json:"file_a"`
+	StartA int    `json:"start_a"`
+	EndA   int    `json:"end_a"`
+	FileB  string `json:"file_b"`
+	StartB int    `json:"start_b"`
+	EndB   int    `json:"end_b"`
+	// Число совпавших отпечатков во фрагменте
+	Matches int `json:"matches"`
+}
+
+// Pair - сходство двух проектов. ScoreA - доля отпечатков проекта A, найденных в B, ScoreB - наоборот
+type Pair struct {
+	A, B    uint
+	ScoreA  float64
+	ScoreB  float64
+	Matches int
+	Regions []Region
+}
+
+// Score - наибольшая из долей совпадения: небольшой проект, целиком скопированный в большой, заметен
+func (p Pair) Score() float64 {
+	return math.Max(p.ScoreA, p.ScoreB)
+}
+
+// Result - итог сравнения проектов
+type Result struct {
+	Pairs []Pair
+	// Сколько различных фрагментов признано шаблонным кодом и не учитывалось
+	Boilerplate int
+	// С какого числа проектов фрагмент считается шаблонным; 0 - проектов слишком мало
+	BoilerplateDocs int
+}
+
+// BoilerplateDocs возвращает, в скольких из docs проектов должен встретиться фрагмент, чтобы считаться шаблонным.
+// Для двух проектов шаблонного кода нет
+func BoilerplateDocs(docs int) int {
+	if docs < boilerplateMinDocs {
+		return 0
+	}
+	return max(boilerplateMinDocs, int(math.Ceil(float64(docs)*boilerplateShare)))
+}
+
+// Compare попарно сравнивает проекты. Пары упорядочены по убыванию сходства
+func Compare(docs []*Document) Result {
+	// Вхождения каждого хэша по проектам
+	index := make(map[uint64]map[int][]print)
+	for d, doc := range docs {
+		for _, p := range doc.prints {
+			byDoc, ok := index[p.hash]
+			if !ok {
+				byDoc = make(map[int][]print)
+				index[p.hash] = byDoc
+			}
+			if len(byDoc[d]) < maxOccurrences {
+				byDoc[d] = append(byDoc[d], p)
+			}
+		}
+	}
+
+	result := Result{BoilerplateDocs: BoilerplateDocs(len(docs))}
+	distinct := make([]int, len(docs))
+	shared := make(map[[2]int][]uint64)
+	for hash, byDoc := range index {
+		if result.BoilerplateDocs > 0 && len(byDoc) >= result.BoilerplateDocs {
+			result.Boilerplate++
+			continue
+		}
+		ids := make([]int, 0, len(byDoc))
+		for d := range byDoc {
+			distinct[d]++
+			ids = append(ids, d)
+		}
+		sort.Ints(ids)
+		for i := range ids {
+			for j := i + 1; j < len(ids); j++ {
+				key := [2]int{ids[i], ids[j]}
+				shared[key] = append(shared[key], hash)
+			}
+		}
+	}
+
+	for key, hashes := range shared {
+		a, b := key[0], key[1]
+		if len(hashes) < minMatches {
+			continue
+		}
+		pair := Pair{
+			A:       docs[a].ID,
+			B:       docs[b].ID,
+			ScoreA:  float64(len(hashes)) / float64(distinct[a]),
+			ScoreB:  float64(len(hashes)) / float64(distinct[b]),
+			Matches: len(hashes),
+		}
+		if pair.Score() < minScore {
+			continue
+		}
+		pair.Regions = regions(docs[a], docs[b], hashes, index, a, b)
+		result.Pairs = append(result.Pairs, pair)
+	}
+
+	sort.Slice(result.Pairs, func(i, j int) bool {
+		if result.Pairs[i].Score() != result.Pairs[j].Score() {
+			return result.Pairs[i].Score() > result.Pairs[j].Score()
+		}
+		if result.Pairs[i].A != result.Pairs[j].A {
+			return result.Pairs[i].A < result.Pairs[j].A
+		}
+		return result.Pairs[i].B < result.Pairs[j].B
+	})
+	return result
+}
+
+// regions собирает совпавшие отпечатки в непрерывные фрагменты файлов
+func regions(docA, docB *Document, hashes []uint64, index map[uint64]map[int][]print, a, b int) []Region {
+	type link struct{ a, b print }
+	byFiles := make(map[[2]int][]link)
+	for _, hash := range hashes {
+		for _, pa := range index[hash][a] {
+			for _, pb := range index[hash][b] {
+				key := [2]int{pa.file, pb.file}
+				byFiles[key] = append(byFiles[key], link{pa, pb})
+			}
+		}
+	}
+
+	var result []Region
+	for files, links := range byFiles {
+		sort.Slice(links, func(i, j int) bool {
+			if links[i].a.start != links[j].a.start {
+				return links[i].a.start < links[j].a.start
+			}
+			return links[i].b.start < links[j].b.start
+		})
+
+		var current *Region
+		for _, l := range links {
+			if current != nil &&
+				l.a.start <= current.EndA+mergeGap &&
+				l.b.start <= current.EndB+mergeGap && l.b.end >= current.StartB-mergeGap {
+				current.EndA = max(current.EndA, l.a.end)
+				current.StartB = min(current.StartB, l.b.start)
+				current.EndB = max(current.EndB, l.b.end)
+				current.Matches++
+				continue
+			}
+			result = append(result, Region{
+				FileA: docA.Files[files[0]], StartA: l.a.start, EndA: l.a.end,
+				FileB: docB.Files[files[1]], StartB: l.b.start, EndB: l.b.end,
+				Matches: 1,
+			})
+			current = &result[len(result)-1]
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Matches != result[j].Matches {
+			return result[i].Matches > result[j].Matches
+		}
+		if result[i].FileA != result[j].FileA {
+			return result[i].FileA < result[j].FileA
+		}
+		return result[i].StartA < result[j].StartA
+	})
+	if len(result) > maxRegions {
+		result = result[:maxRegions]
+	}
+	return result
+}
@@ -0,0 +1,221 @@
+package similarity
+
+import (
+	"hash/fnv"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// Число токенов в хэшируемом фрагменте: более короткие совпадения считаются случайными
+	tokenK = 12
+	// Размер окна winnowing
+	windowSize = 8
+	// Совпадение такой длины в токенах обязательно попадает в отпечатки обоих проектов
+	guaranteeTokens = tokenK + windowSize - 1
+	// Сколько вхождений одного хэша в проекте учитывается при поиске совпадающих фрагментов
+	maxOccurrences = 8
+)
+
+// Document - отпечаток проекта команды
+type Document struct {
+	ID    uint
+	Files []string
+
+	prints []print
+}
+
+// print - выбранный хэш фрагмента и строки, которые фрагмент занимает в файле
+type print struct {
+	hash       uint64
+	file       int
+	start, end int
+}
+
+// token - нормализованный токен и строка, на которой он начинается
+type token struct {
+	hash uint64
+	line int
+}
+
+// Fingerprint строит отпечаток проекта id по его исходным файлам
+func Fingerprint(id uint, files []File) *Document {
+	doc := &Document{ID: id}
+	for _, file := range files {
+		tokens := tokenize(file.Content, commentStyleOf(file.Path))
+		if len(tokens) < tokenK {
+			continue
+		}
+		doc.Files = append(doc.Files, file.Path)
+		doc.prints = append(doc.prints, winnow(tokens, len(doc.Files)-1)...)
+	}
+	return doc
+}
+
+// winnow выбирает из каждого окна хэшей k-грамм наименьший (при равенстве - самый правый)
+// и сохраняет его, если он не был выбран в предыдущем окне
+func winnow(tokens []token, file int) []print {
+	grams := make([]print, len(tokens)-tokenK+1)
+	for i := range grams {
+		h := fnv.New64a()
+		var buf [8]byte
+		for _, t := range tokens[i : i+tokenK] {
+			for j := range buf {
+				buf[j] = byte(t.hash >> (8 * j))
+			}
+			h.Write(buf[:])
+		}
+		grams[i] = print{hash: h.Sum64(), file: file, start: tokens[i].line, end: tokens[i+tokenK-1].line}
+	}
+
+	if len(grams) <= windowSize {
+		// Файл короче окна: берётся один наименьший хэш
+		best := 0
+		for i := range grams {
+			if grams[i].hash <= grams[best].hash {
+				best = i
+			}
+		}
+		return []print{grams[best]}
+	}
+
+	var prints []print
+	last := -1
+	for start := 0; start+windowSize <= len(grams); start++ {
+		best := start
+		for i := start; i < start+windowSize; i++ {
+			if grams[i].hash <= grams[best].hash {
+				best = i
+			}
+		}
+		if best != last {
+			prints = append(prints, grams[best])
+			last = best
+		}
+	}
+	return prints
+}
+
+// commentStyle - какими символами в языке начинаются однострочные комментарии
+type commentStyle struct {
+	slash, hash, dash bool
+}
+
+func commentStyleOf(name string) commentStyle {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".py", ".rb", ".sh", ".r", ".pl", ".ex", ".exs":
+		return commentStyle{hash: true}
+	case ".sql", ".lua", ".hs":
+		return commentStyle{dash: true, slash: true}
+	case ".php":
+		return commentStyle{slash: true, hash: true}
+	}
+	return commentStyle{slash: true}
+}
+
+// keywords - служебные слова распространённых языков. Они задают структуру кода и сохраняются,
+// а остальные идентификаторы заменяются одним токеном, чтобы переименование не скрывало копирование
+var keywords = map[string]bool{
+	"if": true, "else": true, "elif": true, "for": true, "while": true, "do": true, "switch": true,
+	"case": true, "default": true, "break": true, "continue": true, "return": true, "goto": true,
+	"func": true, "function": true, "def": true, "fn": true, "class": true, "struct": true,
+	"interface": true, "enum": true, "type": true, "import": true, "from": true, "package": true,
+	"var": true, "let": true, "const": true, "new": true, "try": true, "catch": true, "except": true,
+	"finally": true, "throw": true, "raise": true, "with": true, "as": true, "in": true, "of": true,
+	"and": true, "or": true, "not": true, "is": true, "async": true, "await": true, "yield": true,
+	"lambda": true, "public": true, "private": true, "protected": true, "static": true, "void": true,
+	"extends": true, "implements": true, "export": true, "go": true, "defer": true, "range": true,
+	"select": true, "where": true, "insert": true, "update": true, "delete": true,
+}
+
+// tokenize разбивает код на нормализованные токены без комментариев: идентификаторы
+// заменяются на "a", числа на "0", строки на `""`, служебные слова и знаки сохраняются
+func tokenize(src []byte, style commentStyle) []token {
+	var tokens []token
+	line := 1
+	add := func(text string, at int) {
+		h := fnv.New64a()
+		h.Write([]byte(text))
+		tokens = append(tokens, token{hash: h.Sum64(), line: at})
+	}
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			i++
+		case style.slash && c == '/' && i+1 < len(src) && src[i+1] == '/',
+			style.hash && c == '#',
+			style.dash && c == '-' && i+1 < len(src) && src[i+1] == '-':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			i += 2
+			for i < len(src) && !(src[i] == '*' && i+1 < len(src) && src[i+1] == '/') {
+				if src[i] == '\n' {
+					line++
+				}
+				i++
+			}
+			i += 2
+		case c == '"' || c == '\'' || c == '`':
+			start := line
+			i++
+			for i < len(src) && src[i] != c {
+				if src[i] == '\\' && i+1 < len(src) && src[i+1] != '\n' {
+					i++
+				} else if src[i] == '\n' {
+					if c != '`' && c != '"' {
+						// Незакрытая одинарная кавычка - скорее апостроф, чем строка
+						break
+					}
+					line++
+				}
+				i++
+			}
+			if i < len(src) && src[i] == c {
+				i++
+			}
+			add(`""`, start)
+		case c >= '0' && c <= '9':
+			for i < len(src) && (isWordByte(src[i]) || src[i] == '.') {
+				i++
+			}
+			add("0", line)
+		default:
+			r, size := utf8.DecodeRune(src[i:])
+			if r == '_' || r == '$' || unicode.IsLetter(r) {
+				start := i
+				for i < len(src) {
+					r, size := utf8.DecodeRune(src[i:])
+					if r != '_' && r != '$' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+						break
+					}
+					i += size
+				}
+				word := string(src[start:i])
+				if keywords[strings.ToLower(word)] {
+					add(strings.ToLower(word), line)
+				} else {
+					add("a", line)
+				}
+				continue
+			}
+			i += size
+			if !unicode.IsSpace(r) {
+				add(string(r), line)
+			}
+		}
+	}
+	return tokens
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,94 @@
+// Package similarity ищет совпадающий код в проектах команд методом winnowing: исходники разбиваются
+// на токены, от каждых tokenK подряд идущих токенов берётся хэш, и из каждого окна хэшей в отпечаток
+// проекта попадает наименьший. Совпадение фрагментов длиннее guaranteeTokens токенов находится всегда
+package similarity
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"server/archive"
+	"strings"
+)
+
+// Ограничения на исходники одного проекта
+const (
+	maxSourceFileSize  = 256 << 10
+	maxSourceTotalSize = 32 << 20
+	maxSourceFiles     = 5000
+	// Распакованный объём архива, включая пропускаемые зависимости и двоичные файлы
+	maxUnpackedSize = 1 << 30
+)
+
+// File - исходный файл проекта
+type File struct {
+	Path    string
+	Content []byte
+}
+
+// sourceExtensions - расширения файлов с кодом и разметкой, которые сравниваются
+var sourceExtensions = map[string]bool{
+	".go": true, ".py": true, ".js": true, ".jsx": true, ".ts": true, ".tsx": true, ".mjs": true,
+	".java": true, ".kt": true, ".kts": true, ".scala": true, ".groovy": true,
+	".c": true, ".h": true, ".cc": true, ".cpp": true, ".cxx": true, ".hpp": true, ".cs": true,
+	".rs": true, ".swift": true, ".m": true, ".dart": true, ".rb": true, ".php": true,
+	".lua": true, ".r": true, ".pl": true, ".ex": true, ".exs": true, ".hs": true, ".sol": true,
+	".vue": true, ".svelte": true, ".html": true, ".css": true, ".scss": true, ".sql": true, ".sh": true,
+}
+
+// skippedDirs - каталоги зависимостей, сборки и окружения: их содержимое написано не командой
+var skippedDirs = map[string]bool{
+	"node_modules": true, "vendor": true, "bower_components": true, ".git": true, ".svn": true,
+	".idea": true, ".vscode": true, "dist": true, "build": true, "out": true, "target": true,
+	"bin": true, "obj": true, ".next": true, ".nuxt": true, "__pycache__": true, "venv": true,
+	".venv": true, "env": true, "site-packages": true, "Pods": true, ".gradle": true, "coverage": true,
+}
+
+// Sources извлекает исходные файлы из архива формата format. Файлы зависимостей, сборки,
+// минифицированные и двоичные файлы пропускаются. Если архив распаковывается больше чем
+// в maxUnpackedSize байт, сравниваются исходники, прочитанные до этого момента
+func Sources(r io.ReaderAt, size int64, format string) ([]File, error) {
+	var files []File
+	var total int64
+
+	err := archive.Walk(r, size, format, maxUnpackedSize, func(name string, size int64, body io.Reader) error {
+		if !isSource(name) || size > maxSourceFileSize {
+			return nil
+		}
+
+		content, err := io.ReadAll(io.LimitReader(body, maxSourceFileSize+1))
+		if err != nil {
+			return archive.ErrCorrupted
+		}
+		if len(content) > maxSourceFileSize || bytes.IndexByte(content, 0) >= 0 {
+			return nil
+		}
+
+		files = append(files, File{Path: name, Content: content})
+		total += int64(len(content))
+		if len(files) >= maxSourceFiles || total >= maxSourceTotalSize {
+			return fs.SkipAll
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, archive.ErrTooLarge) {
+		return nil, err
+	}
+	return files, nil
+}
+
+func isSource(name string) bool {
+	dirs := strings.Split(path.Dir(name), "/")
+	for _, dir := range dirs {
+		if skippedDirs[dir] {
+			return false
+		}
+	}
+	base := strings.ToLower(path.Base(name))
+	if strings.Contains(base, ".min.") {
+		return false
+	}
+	return sourceExtensions[path.Ext(base)]
+}
@@ -0,0 +1,187 @@
+// Package staging хранит данные незавершённых загрузок на диске сервера. Загрузка дописывается
+// частями с заданного смещения, поэтому её можно продолжить после обрыва соединения
+package staging
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrChecksum - контрольная сумма части не совпала, часть не принята
+var ErrChecksum = errors.New("контрольная сумма не совпадает")
+
+// ErrTooLarge - данных больше, чем осталось до конца загрузки
+var ErrTooLarge = errors.New("данные выходят за размер загрузки")
+
+// ErrLost - на диске меньше данных, чем было принято: загрузку нужно начать заново
+var ErrLost = errors.New("данные загрузки утеряны")
+
+// Algorithms - поддерживаемые алгоритмы контрольных сумм частей
+var Algorithms = []string{"sha256", "sha1", "md5"}
+
+// Dir - каталог с данными загрузок. Данные загрузки лежат в файле с её идентификатором
+type Dir struct {
+	root string
+}
+
+func New(root string) (*Dir, error) {
+	if root == "" {
+		return nil, errors.New("не задан каталог незавершённых загрузок")
+	}
+	if err := os.MkdirAll(root, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("ошибка создания каталога незавершённых загрузок: %w", err)
+	}
+	return &Dir{root: root}, nil
+}
+
+// path возвращает путь к данным загрузки. Идентификатор - UUID, других символов в имени файла не бывает
+func (d *Dir) path(id string) (string, error) {
+	if id == "" || strings.Trim(id, "0123456789abcdef-") != "" {
+		return "", fmt.Errorf("недопустимый идентификатор загрузки %q", id)
+	}
+	return filepath.Join(d.root, id), nil
+}
+
+// Digest - ожидаемая контрольная сумма части
+type Digest struct {
+	Algorithm string
+	Sum       []byte
+}
+
+// ParseChecksum разбирает заголовок Upload-Checksum протокола tus: "<алгоритм> <сумма в base64>"
+func ParseChecksum(header string) (*Digest, error) {
+	algorithm, encoded, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok {
+		return nil, errors.New("неверный формат контрольной суммы")
+	}
+	if newHash(algorithm) == nil {
+		return nil, fmt.Errorf("алгоритм %q не поддерживается", algorithm)
+	}
+	sum, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, errors.New("контрольная сумма должна быть в base64")
+	}
+	return &Digest{Algorithm: algorithm, Sum: sum}, nil
+}
+
+func newHash(algorithm string) hash.Hash {
+	switch algorithm {
+	case "sha256":
+		return sha256.New()
+	case "sha1":
+		return sha1.New()
+	case "md5":
+		return md5.New()
+	}
+	return nil
+}
+
+// Write записывает часть загрузки с позиции offset и возвращает число принятых байт.
+// Данные после offset, оставшиеся от оборванной записи, отбрасываются. Принимается не больше limit байт.
+// Без digest при обрыве соединения сохраняется всё, что успело прийти; с digest часть
+// принимается целиком или не принимается вовсе
+func (d *Dir) Write(id string, offset int64, r io.Reader, limit int64, digest *Digest) (int64, error) {
+	target, err := d.path(id)
+	if err != nil {
+		return 0, err
+	}
+	file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE, 0o600)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if info.Size() < offset {
+		return 0, ErrLost
+	}
+	if err := file.Truncate(offset); err != nil {
+		return 0, err
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	var w io.Writer = file
+	var sum hash.Hash
+	if digest != nil {
+		sum = newHash(digest.Algorithm)
+		w = io.MultiWriter(file, sum)
+	}
+
+	written, copyErr := io.Copy(w, io.LimitReader(r, limit))
+	if copyErr == nil && written == limit {
+		// Лишний байт означает, что клиент прислал больше объявленного размера
+		if n, _ := r.Read(make([]byte, 1)); n > 0 {
+			copyErr = ErrTooLarge
+		}
+	}
+	if copyErr == nil && sum != nil && !bytes.Equal(sum.Sum(nil), digest.Sum) {
+		copyErr = ErrChecksum
+	}
+	if copyErr != nil && (sum != nil || errors.Is(copyErr, ErrTooLarge)) {
+		written = 0
+	}
+	if err := file.Truncate(offset + written); err != nil {
+		return 0, err
+	}
+	if err := file.Sync(); err != nil {
+		return 0, err
+	}
+	return written, copyErr
+}
+
+// Open открывает данные загрузки на чтение
+func (d *Dir) Open(id string) (*os.File, error) {
+	target, err := d.path(id)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(target)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrLost
+	}
+	return file, err
+}
+
+// SHA256 вычисляет контрольную сумму всех данных загрузки
+func (d *Dir) SHA256(id string) (string, error) {
+	file, err := d.Open(id)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	sum := sha256.New()
+	if _, err := io.Copy(sum, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// Remove удаляет данные загрузки; отсутствие данных ошибкой не считается
+func (d *Dir) Remove(id string) error {
+	target, err := d.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Local хранит объекты в каталоге на диске. Подписанные ссылки ведут на сервер,
+// который проверяет их методом Verify и отдаёт файл
+type Local struct {
+	root    string
+	baseURL string
+	secret  []byte
+}
+
+func NewLocal(root, baseURL, secret string) (*Local, error) {
+	if root == "" {
+		return nil, errors.New("не задан каталог хранилища")
+	}
+	if err := os.MkdirAll(root, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("ошибка создания каталога хранилища: %w", err)
+	}
+	return &Local{root: root, baseURL: strings.TrimRight(baseURL, "/"), secret: []byte(secret)}, nil
+}
+
+// path возвращает путь объекта на диске. Ключ не может указывать за пределы каталога хранилища
+func (l *Local) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if clean == "/" || clean != "/"+key {
+		return "", fmt.Errorf("недопустимый ключ %q", key)
+	}
+	return filepath.Join(l.root, filepath.FromSlash(clean)), nil
+}
+
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	target, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+		return err
+	}
+
+	// Данные пишутся во временный файл и переименовываются: читатели не видят недописанный объект
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if size >= 0 && written != size {
+		return fmt.Errorf("записано %d байт из %d", written, size)
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	target, err := l.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := os.Open(target)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return file, l.object(key, info), nil
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	target, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (l *Local) Stat(ctx context.Context, key string) (*Object, error) {
+	target, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(target)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return l.object(key, info), nil
+}
+
+func (l *Local) object(key string, info fs.FileInfo) *Object {
+	return &Object{
+		Key:         key,
+		Size:        info.Size(),
+		ContentType: mime.TypeByExtension(path.Ext(key)),
+		ModTime:     info.ModTime(),
+	}
+}
+
+// Presign подписывает ссылку на сервер ключом хранилища
+func (l *Local) Presign(ctx context.Context, key string, expires time.Duration, name string) (string, error) {
+	if len(l.secret) == 0 {
+		return "", errors.New("не задан ключ подписи ссылок хранилища")
+	}
+	if _, err := l.path(key); err != nil {
+		return "", err
+	}
+	deadline := strconv.FormatInt(time.Now().Add(expires).Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", deadline)
+	if name != "" {
+		query.Set("name", name)
+	}
+	query.Set("signature", l.sign(key, deadline, name))
+	return l.baseURL + "/" + escapePath(key) + "?" + query.Encode(), nil
+}
+
+// Verify проверяет подпись и срок действия ссылки, выданной Presign
+func (l *Local) Verify(key string, query url.Values) error {
+	if len(l.secret) == 0 {
+		return ErrSignature
+	}
+	deadline := query.Get("expires")
+	expires, err := strconv.ParseInt(deadline, 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return ErrSignature
+	}
+	expected := l.sign(key, deadline, query.Get("name"))
+	if !hmac.Equal([]byte(expected), []byte(query.Get("signature"))) {
+		return ErrSignature
+	}
+	return nil
+}
+
+func (l *Local) sign(key, deadline, name string) string {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(key + "\n" + deadline + "\n" + name))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// S3Config - параметры S3-совместимого хранилища
+type S3Config struct {
+	// Адрес API, например https://s3.eu-central-1.amazonaws.com или http://minio:9000
+	Endpoint string
+	// Адрес, доступный браузерам, для подписанных ссылок; по умолчанию Endpoint
+	PublicEndpoint string
+	Region         string
+	Bucket         string
+	AccessKey      string
+	SecretKey      string
+	// Бакет указывается в пути (endpoint/bucket/key), а не в имени хоста. Нужно для MinIO
+	PathStyle bool
+}
+
+// S3 хранит объекты в бакете S3-совместимого хранилища. Запросы подписываются AWS Signature V4
+type S3 struct {
+	config   S3Config
+	endpoint *url.URL
+	public   *url.URL
+	client   *http.Client
+}
+
+func NewS3(config S3Config) (*S3, error) {
+	if config.Endpoint == "" || config.Bucket == "" || config.AccessKey == "" || config.SecretKey == "" {
+		return nil, errors.New("для хранилища S3 нужны S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY и S3_SECRET_KEY")
+	}
+	endpoint, err := url.Parse(strings.TrimRight(config.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("неверный адрес S3: %q", config.Endpoint)
+	}
+	public := endpoint
+	if config.PublicEndpoint != "" {
+		public, err = url.Parse(strings.TrimRight(config.PublicEndpoint, "/"))
+		if err != nil || public.Host == "" {
+			return nil, fmt.Errorf("неверный публичный адрес S3: %q", config.PublicEndpoint)
+		}
+	}
+	return &S3{config: config, endpoint: endpoint, public: public, client: &http.Client{}}, nil
+}
+
+// objectURL возвращает адрес объекта относительно base
+func (s *S3) objectURL(base *url.URL, key string) *url.URL {
+	u := *base
+	if s.config.PathStyle {
+		u.Path = base.Path + "/" + s.config.Bucket + "/" + key
+	} else {
+		u.Host = s.config.Bucket + "." + base.Host
+		u.Path = base.Path + "/" + key
+	}
+	// Путь кодируется так же, как при подписи запроса
+	u.RawPath = escapePath(u.Path)
+	return &u
+}
+
+func (s *S3) do(ctx context.Context, method, key string, body io.Reader, size int64, header http.Header) (*http.Response, error) {
+	target := s.objectURL(s.endpoint, key)
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	s.signer().signRequest(req, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, s3Error(method, key, resp)
+	}
+	return resp, nil
+}
+
+func (s *S3) signer() *signer {
+	return &signer{accessKey: s.config.AccessKey, secretKey: s.config.SecretKey, region: s.config.Region, service: "s3"}
+}
+
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if size < 0 {
+		return errors.New("для загрузки в S3 нужен размер объекта")
+	}
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	if size == 0 {
+		r = http.NoBody
+	}
+	resp, err := s.do(ctx, http.MethodPut, key, r, size, header)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, 0, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Body, responseObject(key, resp), nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, 0, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3) Stat(ctx context.Context, key string) (*Object, error) {
+	resp, err := s.do(ctx, http.MethodHead, key, nil, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return responseObject(key, resp), nil
+}
+
+// EnsureBucket создаёт бакет, если его ещё нет. Удобно для MinIO, развёрнутого рядом с сервером
+func (s *S3) EnsureBucket(ctx context.Context) error {
+	resp, err := s.do(ctx, http.MethodHead, "", nil, 0, nil)
+	if err == nil {
+		resp.Body.Close()
+		return nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	var body string
+	if s.config.Region != "us-east-1" {
+		body = "<CreateBucketConfiguration><LocationConstraint>" + s.config.Region + "</LocationConstraint></CreateBucketConfiguration>"
+	}
+	resp, err = s.do(ctx, http.MethodPut, "", strings.NewReader(body), int64(len(body)), nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Presign подписывает ссылку на скачивание объекта с публичного адреса хранилища
+func (s *S3) Presign(ctx context.Context, key string, expires time.Duration, name string) (string, error) {
+	target := s.objectURL(s.public, key)
+	query := url.Values{}
+	if name != "" {
+		query.Set("response-content-disposition", contentDisposition(name))
+	}
+	return s.signer().presign(http.MethodGet, target, query, expires, time.Now()), nil
+}
+
+func responseObject(key string, resp *http.Response) *Object {
+	object := &Object{Key: key, Size: resp.ContentLength, ContentType: resp.Header.Get("Content-Type")}
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		object.ModTime = modified
+	}
+	if object.Size < 0 {
+		object.Size, _ = strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	}
+	return object
+}
+
+// s3Error разбирает XML-ответ S3 с описанием ошибки
+func s3Error(method, key string, resp *http.Response) error {
+	var body struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if xml.Unmarshal(data, &body) != nil || body.Code == "" {
+		return fmt.Errorf("s3: %s %s: %s", method, key, resp.Status)
+	}
+	return fmt.Errorf("s3: %s %s: %s: %s", method, key, body.Code, body.Message)
+}
+
+// contentDisposition задаёт имя сохраняемого файла, в том числе не латиницей (RFC 6266)
+func contentDisposition(name string) string {
+	ascii := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, name)
+	return fmt.Sprintf("attachment; filename=\"%s\"; filename*=UTF-8''%s", ascii, url.PathEscape(name))
+}
@@ -0,0 +1,97 @@
+// Package storage хранит содержимое загруженных файлов: на локальном диске или в S3-совместимом
+// хранилище (AWS S3, MinIO). Сведения о файлах остаются в БД, здесь только данные по ключу
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Виды хранилищ
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+// ErrNotFound - объекта с таким ключом нет в хранилище
+var ErrNotFound = errors.New("объект не найден в хранилище")
+
+// ErrSignature - подписанная ссылка неверна или устарела
+var ErrSignature = errors.New("ссылка недействительна")
+
+// Object - сведения о хранимом объекте
+type Object struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Storage - хранилище содержимого файлов. Ключ - путь вида a/b/c без ведущего "/"
+type Storage interface {
+	// Put сохраняет size байт из r под ключом key, заменяя прежнее содержимое
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get открывает объект на чтение; читатель нужно закрыть
+	Get(ctx context.Context, key string) (io.ReadCloser, *Object, error)
+	Delete(ctx context.Context, key string) error
+	Stat(ctx context.Context, key string) (*Object, error)
+	// Presign возвращает ссылку, по которой объект можно скачать без авторизации до истечения expires.
+	// name - имя файла для сохранения в браузере
+	Presign(ctx context.Context, key string, expires time.Duration, name string) (string, error)
+}
+
+// Verifier проверяет подписанные ссылки хранилищ, которые отдают файлы через сервер
+type Verifier interface {
+	Verify(key string, query url.Values) error
+}
+
+// New создаёт хранилище вида backend по переменным окружения:
+// STORAGE_PATH, STORAGE_PUBLIC_URL и STORAGE_SIGNING_KEY для локального,
+// S3_ENDPOINT, S3_PUBLIC_ENDPOINT, S3_REGION, S3_BUCKET, S3_ACCESS_KEY, S3_SECRET_KEY и S3_PATH_STYLE для S3
+func New(backend string) (Storage, error) {
+	switch backend {
+	case "", BackendLocal:
+		secret := os.Getenv("STORAGE_SIGNING_KEY")
+		if secret == "" {
+			secret = os.Getenv("ACCESS_TOKEN_SECRET")
+		}
+		return NewLocal(env("STORAGE_PATH", "uploads"), env("STORAGE_PUBLIC_URL", "/api/storage"), secret)
+	case BackendS3:
+		pathStyle := true
+		if value := os.Getenv("S3_PATH_STYLE"); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("неверное значение S3_PATH_STYLE: %q", value)
+			}
+			pathStyle = parsed
+		}
+		return NewS3(S3Config{
+			Endpoint:       os.Getenv("S3_ENDPOINT"),
+			PublicEndpoint: os.Getenv("S3_PUBLIC_ENDPOINT"),
+			Region:         env("S3_REGION", "us-east-1"),
+			Bucket:         os.Getenv("S3_BUCKET"),
+			AccessKey:      os.Getenv("S3_ACCESS_KEY"),
+			SecretKey:      os.Getenv("S3_SECRET_KEY"),
+			PathStyle:      pathStyle,
+		})
+	}
+	return nil, fmt.Errorf("неизвестное хранилище %q", backend)
+}
+
+// FromEnv создаёт хранилище, выбранное переменной STORAGE_BACKEND (по умолчанию локальное)
+func FromEnv() (Storage, error) {
+	return New(os.Getenv("STORAGE_BACKEND"))
+}
+
+func env(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}